@@ -0,0 +1,62 @@
+// © 2025 Sharon Aicler (saichler@gmail.com)
+//
+// Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/saichler/l8bus/go/overlay/capture"
+	"github.com/saichler/l8bus/go/overlay/protocol"
+	"github.com/saichler/l8bus/go/overlay/vnic"
+)
+
+// captureFrame is a frame of a capture as printed with -json
+type captureFrame struct {
+	Offset      string `json:"offset"`
+	Direction   string `json:"direction"`
+	Connection  string `json:"connection"`
+	Source      string `json:"source"`
+	Destination string `json:"destination"`
+	ServiceName string `json:"serviceName"`
+	ServiceArea byte   `json:"serviceArea"`
+	Size        int    `json:"size"`
+}
+
+// dumpCapture prints the frames of a capture file, at the -speed of the capture, it
+// does not connect to a VNet so nic is nil.
+func dumpCapture(nic *vnic.VirtualNetworkInterface, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("expected a single capture file")
+	}
+	var first time.Time
+	return capture.Replay(args[0], *speed, func(frame *capture.Frame) error {
+		if first.IsZero() {
+			first = frame.Time
+		}
+		header, err := protocol.HeaderOf(frame.Data)
+		if err != nil {
+			return err
+		}
+		f := &captureFrame{Offset: frame.Time.Sub(first).String(), Direction: frame.Direction.String(),
+			Connection: frame.Connection, Source: header.Source, Destination: header.Destination,
+			ServiceName: header.ServiceName, ServiceArea: header.ServiceArea, Size: len(frame.Data)}
+		if *asJson {
+			return printJson(f)
+		}
+		fmt.Printf("%-12s %-3s %s  %s -> %s  %s/%d  %d bytes\n", f.Offset, f.Direction, f.Connection,
+			f.Source, f.Destination, f.ServiceName, f.ServiceArea, f.Size)
+		return nil
+	})
+}
@@ -0,0 +1,72 @@
+// © 2025 Sharon Aicler (saichler@gmail.com)
+//
+// Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/saichler/l8bus/go/overlay/vnet"
+	"github.com/saichler/l8bus/go/overlay/vnic"
+	"github.com/saichler/l8types/go/ifs"
+)
+
+var deadLetterActions = map[string]ifs.Action{
+	"list":    ifs.GET,
+	"redrive": ifs.POST,
+	"purge":   ifs.DELETE,
+}
+
+// deadLetters lists, redrives or purges the dead letters of the local VNet, all of them
+// or the ones with the given ids
+func deadLetters(nic *vnic.VirtualNetworkInterface, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("expected a dlq operation, list, redrive or purge")
+	}
+	action, ok := deadLetterActions[args[0]]
+	if !ok {
+		return fmt.Errorf("unknown dlq operation %s", args[0])
+	}
+	query := &vnet.DeadLetterQuery{}
+	for _, arg := range args[1:] {
+		id, err := strconv.ParseInt(arg, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid dead letter id %s", arg)
+		}
+		query.Ids = append(query.Ids, id)
+	}
+	result, err := vnet.DeadLetterRequest(nic, nic.Resources().SysConfig().RemoteUuid, action, query, *timeout)
+	if err != nil {
+		return err
+	}
+	if *asJson {
+		return printJson(result)
+	}
+	if action == ifs.POST {
+		fmt.Printf("%s %d dead letters, %d expired\n", args[0], result.Count, result.Expired)
+		return nil
+	}
+	if action != ifs.GET {
+		fmt.Printf("%s %d dead letters\n", args[0], result.Count)
+		return nil
+	}
+	fmt.Printf("Dead letters (%d, %d dropped)\n", result.Count, result.Dropped)
+	for _, letter := range result.Letters {
+		fmt.Printf("  %-6d %s %s/%d %s -> %s %d bytes: %s\n", letter.Id,
+			time.UnixMilli(letter.Time).Format("15:04:05.000"), letter.ServiceName, letter.ServiceArea,
+			letter.Source, letter.Destination, letter.Size, letter.Reason)
+	}
+	return nil
+}
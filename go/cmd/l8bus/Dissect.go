@@ -0,0 +1,89 @@
+// © 2025 Sharon Aicler (saichler@gmail.com)
+//
+// Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/saichler/l8bus/go/overlay/capture"
+	"github.com/saichler/l8bus/go/overlay/dissector"
+	"github.com/saichler/l8bus/go/overlay/vnic"
+	"github.com/saichler/l8types/go/ifs"
+)
+
+// dissect prints the fields of frames given in hex or base64, as an argument or one per
+// line of a text file, or of all the frames of a capture file. It does not connect to a
+// VNet so nic is nil, payloads are decoded with the types of the local registry.
+func dissect(nic *vnic.VirtualNetworkInterface, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("expected a frame in hex or base64, or a file")
+	}
+	resources := newResources(uint32(*port))
+	if _, err := os.Stat(args[0]); err != nil {
+		return dissectText(args[0], resources)
+	}
+	frames, err := capture.ReadAll(args[0])
+	if err == nil {
+		for i, frame := range frames {
+			if !*asJson {
+				fmt.Printf("#%d %s %s %s\n", i+1, frame.Time.Format("15:04:05.000000"), frame.Direction, frame.Connection)
+			}
+			printDissection(frame.Data, resources)
+		}
+		return nil
+	}
+	file, err := os.Open(args[0])
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 1024*1024), 64*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		err = dissectText(line, resources)
+		if err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+func dissectText(text string, resources ifs.IResources) error {
+	data, err := dissector.ParseFrame(text)
+	if err != nil {
+		return err
+	}
+	printDissection(data, resources)
+	return nil
+}
+
+func printDissection(data []byte, resources ifs.IResources) {
+	d, err := dissector.Dissect(data, resources)
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+	if *asJson {
+		printJson(d)
+		return
+	}
+	fmt.Print(d.Text())
+}
@@ -0,0 +1,149 @@
+// © 2025 Sharon Aicler (saichler@gmail.com)
+//
+// Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/saichler/l8bus/go/overlay/health"
+	"github.com/saichler/l8bus/go/overlay/vnet"
+	"github.com/saichler/l8bus/go/overlay/vnic"
+	"github.com/saichler/l8types/go/ifs"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func inspect(nic *vnic.VirtualNetworkInterface, args []string) error {
+	destination := nic.Resources().SysConfig().RemoteUuid
+	if len(args) > 0 {
+		var err error
+		destination, err = destinationOf(nic, args)
+		if err != nil {
+			return err
+		}
+	}
+	resp := nic.Request(destination, vnet.AdminServiceName, 0, ifs.GET, &wrapperspb.BytesValue{}, *timeout)
+	info, err := vnet.SwitchTableFrom(resp)
+	if err != nil {
+		return err
+	}
+	if *asJson {
+		return printJson(info)
+	}
+	printSwitchTable(info)
+	return nil
+}
+
+func printJson(any interface{}) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(any)
+}
+
+func printSwitchTable(info *vnet.SwitchTableInfo) {
+	aliases := make(map[string]string)
+	for _, hp := range info.Health {
+		aliases[hp.Uuid] = hp.Alias
+	}
+	alias := func(uuid string) string {
+		a, ok := aliases[uuid]
+		if !ok {
+			return uuid
+		}
+		return a
+	}
+
+	fmt.Printf("VNet %s (%s)\n", info.Alias, info.Uuid)
+	printConnections("Internal connections", info.Internal)
+	printConnections("External VNet connections", info.ExternalVnets)
+	printConnections("External VNic connections", info.ExternalVnics)
+
+	fmt.Printf("\nRoutes (%d)\n", len(info.Routes))
+	uuids := make([]string, 0, len(info.Routes))
+	for uuid := range info.Routes {
+		uuids = append(uuids, uuid)
+	}
+	sort.Strings(uuids)
+	for _, uuid := range uuids {
+		fmt.Printf("  %-40s via %s\n", alias(uuid), alias(info.Routes[uuid]))
+	}
+
+	fmt.Printf("\nServices (%d)\n", len(info.Services))
+	for _, service := range info.Services {
+		instances := make([]string, len(service.Uuids))
+		for i, uuid := range service.Uuids {
+			instances[i] = alias(uuid)
+		}
+		fmt.Printf("  %s/%d leader=%s instances=%s\n", service.Name, service.Area, alias(service.Leader), strings.Join(instances, ","))
+	}
+
+	fmt.Println("\nQueues")
+	names := make([]string, 0, len(info.Queues))
+	for name := range info.Queues {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Printf("  %-14s %d\n", name, info.Queues[name])
+	}
+
+	fmt.Printf("\nHealth (%d)\n", len(info.Health))
+	for _, hp := range info.Health {
+		kind := "vnic"
+		if hp.IsVnet {
+			kind = "vnet"
+		}
+		fmt.Printf("  %s %-30s %s\n", kind, hp.Alias, hp.Uuid)
+	}
+}
+
+func printConnections(title string, conns []*vnet.ConnectionInfo) {
+	fmt.Printf("\n%s (%d)\n", title, len(conns))
+	for _, conn := range conns {
+		state := "up"
+		if !conn.Running {
+			state = "down"
+		}
+		fmt.Printf("  %-30s %s %-21s %-4s tx=%d rx=%d v%d\n", conn.Alias, conn.Uuid, conn.Address, state, conn.TxQueue, conn.RxQueue, conn.Version)
+	}
+}
+
+func topology(nic *vnic.VirtualNetworkInterface, args []string) error {
+	waitForHealth(nic)
+	topology := vnet.CollectTopology(nic, *timeout)
+	if *asJson {
+		return printJson(topology)
+	}
+	fmt.Print(topology.Dot())
+	return nil
+}
+
+// waitForHealth waits for the health records of the overlay to arrive after connecting,
+// until their number is stable or the timeout expires
+func waitForHealth(nic *vnic.VirtualNetworkInterface) {
+	deadline := time.Now().Add(time.Duration(*timeout) * time.Second)
+	count := 0
+	for time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond * 500)
+		current := len(health.All(nic.Resources()))
+		if current > 1 && current == count {
+			return
+		}
+		count = current
+	}
+}
@@ -0,0 +1,50 @@
+// © 2025 Sharon Aicler (saichler@gmail.com)
+//
+// Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/saichler/l8services/go/services/manager"
+	"github.com/saichler/l8types/go/ifs"
+	"github.com/saichler/l8types/go/types/l8sysconfig"
+	"github.com/saichler/l8utils/go/utils/logger"
+	"github.com/saichler/l8utils/go/utils/registry"
+	"github.com/saichler/l8utils/go/utils/resources"
+	"github.com/saichler/reflect/go/reflect/introspecting"
+)
+
+// newResources creates the resources of the cli vnic, connecting to the VNet on the
+// given port with the security provider of the local installation.
+func newResources(port uint32) ifs.IResources {
+	res := resources.NewResources(logger.NewLoggerImpl(&logger.FmtLogMethod{}))
+	res.Logger().SetLogLevel(ifs.Error_Level)
+	res.Set(registry.NewRegistry())
+	sec, err := ifs.LoadSecurityProvider(res)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Failed to load the security provider: ", err.Error())
+		os.Exit(1)
+	}
+	res.Set(sec)
+	res.Set(&l8sysconfig.L8SysConfig{MaxDataSize: resources.DEFAULT_MAX_DATA_SIZE,
+		RxQueueSize: resources.DEFAULT_QUEUE_SIZE,
+		TxQueueSize: resources.DEFAULT_QUEUE_SIZE,
+		LocalAlias:  "l8bus",
+		VnetPort:    port,
+	})
+	res.Set(introspecting.NewIntrospect(res.Registry()))
+	res.Set(manager.NewServices(res))
+	return res
+}
@@ -0,0 +1,163 @@
+// © 2025 Sharon Aicler (saichler@gmail.com)
+//
+// Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command l8bus connects to a VNet as a VNic to diagnose and inspect the overlay.
+//
+//	l8bus [-port 50000] [-timeout 5] ping <uuid|alias>
+//	l8bus [-port 50000] [-timeout 5] traceroute <uuid|alias>
+//	l8bus [-port 50000] [-timeout 5] [-json] inspect [vnet uuid|alias]
+//	l8bus [-port 50000] [-timeout 5] [-json] topology
+//	l8bus [-speed 1] [-json] capture <file>
+//	l8bus [-json] dissect <hex|base64|file>
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/saichler/l8bus/go/overlay/health"
+	"github.com/saichler/l8bus/go/overlay/vnic"
+)
+
+// command is a subcommand of the cli, running over the connected vnic, an offline
+// command does not connect and runs with a nil vnic
+type command struct {
+	usage   string
+	offline bool
+	run     func(nic *vnic.VirtualNetworkInterface, args []string) error
+}
+
+var commands = map[string]*command{
+	"ping":       {usage: "ping <uuid|alias>", run: ping},
+	"traceroute": {usage: "traceroute <uuid|alias>", run: traceroute},
+	"inspect":    {usage: "inspect [vnet uuid|alias], the local VNet by default", run: inspect},
+	"topology":   {usage: "topology, in Graphviz DOT or in json with -json", run: topology},
+	"capture":    {usage: "capture <file>, prints the frames of a capture file", offline: true, run: dumpCapture},
+	"dissect":    {usage: "dissect <hex|base64|file>, decodes frames, a file is a capture or a frame per line", offline: true, run: dissect},
+	"dlq":        {usage: "dlq <list|redrive|purge> [id ...], the dead letters of the local VNet", run: deadLetters},
+}
+
+// commandNames is the order of the commands in the usage
+var commandNames = []string{"ping", "traceroute", "inspect", "topology", "capture", "dissect", "dlq"}
+
+var (
+	port    = flag.Uint("port", 50000, "The port of the local VNet")
+	timeout = flag.Int("timeout", 5, "Timeout in seconds of every request")
+	asJson  = flag.Bool("json", false, "Print the output as json")
+	speed   = flag.Float64("speed", 0, "Replay speed of a capture, 1 is the original speed and 0 is without delays")
+)
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+	if flag.NArg() == 0 {
+		usage()
+		os.Exit(2)
+	}
+	cmd, ok := commands[flag.Arg(0)]
+	if !ok {
+		usage()
+		os.Exit(2)
+	}
+
+	if cmd.offline {
+		err := cmd.run(nil, flag.Args()[1:])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			os.Exit(1)
+		}
+		return
+	}
+
+	nic := vnic.NewVirtualNetworkInterface(newResources(uint32(*port)), nil)
+	nic.Start()
+	nic.WaitForConnection()
+	defer nic.Shutdown()
+
+	err := cmd.run(nic, flag.Args()[1:])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		nic.Shutdown()
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage: l8bus [flags] <command> [args]")
+	fmt.Fprintln(os.Stderr, "Commands:")
+	for _, name := range commandNames {
+		fmt.Fprintln(os.Stderr, "  "+commands[name].usage)
+	}
+	fmt.Fprintln(os.Stderr, "Flags:")
+	flag.PrintDefaults()
+}
+
+func ping(nic *vnic.VirtualNetworkInterface, args []string) error {
+	destination, err := destinationOf(nic, args)
+	if err != nil {
+		return err
+	}
+	hop, err := nic.Ping(destination, *timeout)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("reply from %s (%s) time=%s\n", hop.Alias, hop.Uuid, formatRtt(hop.Rtt))
+	return nil
+}
+
+func traceroute(nic *vnic.VirtualNetworkInterface, args []string) error {
+	destination, err := destinationOf(nic, args)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("traceroute to %s, %d hops max\n", destination, vnic.DefaultMaxHops)
+	hops, err := nic.Traceroute(destination, *timeout)
+	for i, hop := range hops {
+		kind := "vnic"
+		if hop.IsVnet {
+			kind = "vnet"
+		}
+		fmt.Printf("%2d  %s %s (%s)  %s\n", i+1, kind, hop.Alias, hop.Uuid, formatRtt(hop.Rtt))
+	}
+	if err != nil {
+		fmt.Printf("%2d  * %s\n", len(hops)+1, err.Error())
+	}
+	return nil
+}
+
+// destinationOf returns the uuid of the destination argument, an alias is looked up
+// in the health records of the overlay
+func destinationOf(nic *vnic.VirtualNetworkInterface, args []string) (string, error) {
+	if len(args) != 1 {
+		return "", fmt.Errorf("expected a single destination uuid or alias")
+	}
+	if len(args[0]) == 36 {
+		return args[0], nil
+	}
+	deadline := time.Now().Add(time.Duration(*timeout) * time.Second)
+	for time.Now().Before(deadline) {
+		for uuid, hp := range health.All(nic.Resources()) {
+			if hp.Alias == args[0] {
+				return uuid, nil
+			}
+		}
+		time.Sleep(time.Millisecond * 100)
+	}
+	return "", fmt.Errorf("unknown destination %s", args[0])
+}
+
+func formatRtt(rtt time.Duration) string {
+	return fmt.Sprintf("%.3f ms", float64(rtt.Microseconds())/1000)
+}
@@ -0,0 +1,247 @@
+// © 2025 Sharon Aicler (saichler@gmail.com)
+//
+// Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package capture records the raw frames of a VNet or a VNic into a capture file and
+// replays them, to reproduce issues that depend on the ordering of messages.
+package capture
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/saichler/l8bus/go/overlay/protocol"
+	"github.com/saichler/l8utils/go/utils/resources"
+	"github.com/saichler/l8utils/go/utils/strings"
+)
+
+// Direction of a captured frame
+type Direction byte
+
+const (
+	// In is a frame received, by VNet.HandleData or by the RX of a vnic
+	In Direction = 1
+	// Out is a frame written to the socket by the TX of a vnic
+	Out Direction = 2
+)
+
+func (d Direction) String() string {
+	switch d {
+	case In:
+		return "in"
+	case Out:
+		return "out"
+	}
+	return "unknown"
+}
+
+// fileMagic starts every capture file, followed by the format version
+var fileMagic = []byte("L8CAP")
+
+const fileVersion = byte(1)
+
+// recordHeaderSize is the time (8), direction (1), connection length (2) & frame length (4)
+const recordHeaderSize = 15
+
+// MaxFrameSize is the largest frame a capture is read with, a longer frame is reported
+// as corrupt instead of being allocated.
+var MaxFrameSize = int(resources.DEFAULT_MAX_DATA_SIZE)
+
+// Frame is a captured frame, Connection is the uuid of the peer of the connection
+// the frame was received from or written to.
+type Frame struct {
+	Time       time.Time
+	Direction  Direction
+	Connection string
+	Data       []byte
+}
+
+// Filter selects the frames to capture by service name and by uuid, a frame matches
+// a uuid if it is its source, source VNet or destination. Empty lists match all frames.
+type Filter struct {
+	ServiceNames []string
+	Uuids        []string
+}
+
+// Match returns true if the frame passes the filter
+func (this *Filter) Match(data []byte) bool {
+	if this == nil || (len(this.ServiceNames) == 0 && len(this.Uuids) == 0) {
+		return true
+	}
+	header, err := protocol.HeaderOf(data)
+	if err != nil {
+		return false
+	}
+	if len(this.ServiceNames) > 0 && !contains(this.ServiceNames, header.ServiceName) {
+		return false
+	}
+	if len(this.Uuids) > 0 && !contains(this.Uuids, header.Source) && !contains(this.Uuids, header.SourceVnet) &&
+		!contains(this.Uuids, header.Destination) {
+		return false
+	}
+	return true
+}
+
+func contains(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Writer appends the frames that pass its filter to a capture file.
+type Writer struct {
+	file   *os.File
+	filter *Filter
+	mtx    sync.Mutex
+	frames int
+}
+
+// NewWriter creates the capture file, a nil filter captures all the frames.
+func NewWriter(filename string, filter *Filter) (*Writer, error) {
+	file, err := os.Create(filename)
+	if err != nil {
+		return nil, err
+	}
+	_, err = file.Write(append(append([]byte{}, fileMagic...), fileVersion))
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return &Writer{file: file, filter: filter}, nil
+}
+
+// Capture records the frame if it passes the filter, it is safe to call on a nil writer.
+func (this *Writer) Capture(direction Direction, connection string, data []byte) error {
+	if this == nil || !this.filter.Match(data) {
+		return nil
+	}
+	record := make([]byte, recordHeaderSize, recordHeaderSize+len(connection)+len(data))
+	binary.BigEndian.PutUint64(record[0:8], uint64(time.Now().UnixNano()))
+	record[8] = byte(direction)
+	binary.BigEndian.PutUint16(record[9:11], uint16(len(connection)))
+	binary.BigEndian.PutUint32(record[11:15], uint32(len(data)))
+	record = append(record, connection...)
+	record = append(record, data...)
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+	if this.file == nil {
+		return nil
+	}
+	this.frames++
+	_, err := this.file.Write(record)
+	return err
+}
+
+// Frames returns the number of frames captured
+func (this *Writer) Frames() int {
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+	return this.frames
+}
+
+// Close closes the capture file, frames captured after are dropped.
+func (this *Writer) Close() error {
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+	if this.file == nil {
+		return nil
+	}
+	err := this.file.Close()
+	this.file = nil
+	return err
+}
+
+// Reader reads the frames of a capture file in order.
+type Reader struct {
+	file *os.File
+}
+
+// Open opens a capture file and validates its header.
+func Open(filename string) (*Reader, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	header := make([]byte, len(fileMagic)+1)
+	_, err = io.ReadFull(file, header)
+	if err != nil || string(header[:len(fileMagic)]) != string(fileMagic) {
+		file.Close()
+		return nil, errors.New(strings.New(filename, " is not a capture file").String())
+	}
+	if header[len(fileMagic)] != fileVersion {
+		file.Close()
+		return nil, errors.New(strings.New("Unsupported capture version ", int(header[len(fileMagic)])).String())
+	}
+	return &Reader{file: file}, nil
+}
+
+// Next returns the next frame, or io.EOF at the end of the capture.
+func (this *Reader) Next() (*Frame, error) {
+	header := make([]byte, recordHeaderSize)
+	_, err := io.ReadFull(this.file, header)
+	if err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return nil, errors.New("Truncated capture frame")
+		}
+		return nil, err
+	}
+	frame := &Frame{}
+	frame.Time = time.Unix(0, int64(binary.BigEndian.Uint64(header[0:8])))
+	frame.Direction = Direction(header[8])
+	size := binary.BigEndian.Uint32(header[11:15])
+	if uint64(size) > uint64(MaxFrameSize) {
+		return nil, errors.New(strings.New("Corrupt capture frame of ", size, " bytes").String())
+	}
+	connection := make([]byte, binary.BigEndian.Uint16(header[9:11]))
+	frame.Data = make([]byte, size)
+	_, err = io.ReadFull(this.file, connection)
+	if err == nil {
+		_, err = io.ReadFull(this.file, frame.Data)
+	}
+	if err != nil {
+		return nil, errors.New("Truncated capture frame")
+	}
+	frame.Connection = string(connection)
+	return frame, nil
+}
+
+// Close closes the capture file
+func (this *Reader) Close() error {
+	return this.file.Close()
+}
+
+// ReadAll returns all the frames of a capture file.
+func ReadAll(filename string) ([]*Frame, error) {
+	reader, err := Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	frames := make([]*Frame, 0)
+	for {
+		frame, err := reader.Next()
+		if err == io.EOF {
+			return frames, nil
+		}
+		if err != nil {
+			return frames, err
+		}
+		frames = append(frames, frame)
+	}
+}
@@ -0,0 +1,116 @@
+// © 2025 Sharon Aicler (saichler@gmail.com)
+//
+// Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package capture
+
+import (
+	"io"
+	"time"
+
+	"github.com/saichler/l8bus/go/overlay/protocol"
+	"github.com/saichler/l8types/go/ifs"
+)
+
+// Speed of a replay, a speed of 2 replays twice as fast as the capture
+const (
+	// AsFastAsPossible replays the frames without delays
+	AsFastAsPossible = 0.0
+	// OriginalSpeed keeps the intervals between the frames as captured
+	OriginalSpeed = 1.0
+)
+
+// DataHandler is what a VNet implements to switch the raw frames it receives
+type DataHandler interface {
+	HandleData(data []byte, vnic ifs.IVNic)
+}
+
+// Replay calls fn with every frame of the capture, in order, keeping the intervals
+// between the frames divided by the speed. It stops on the first error of fn.
+func Replay(filename string, speed float64, fn func(frame *Frame) error) error {
+	reader, err := Open(filename)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	var first time.Time
+	start := time.Now()
+	for {
+		frame, err := reader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if first.IsZero() {
+			first = frame.Time
+		}
+		if speed > 0 {
+			due := time.Duration(float64(frame.Time.Sub(first)) / speed)
+			wait := due - time.Since(start)
+			if wait > 0 {
+				time.Sleep(wait)
+			}
+		}
+		err = fn(frame)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// ReplayToVNet feeds the received frames of the capture to a VNet, as if they were
+// received from the given vnic.
+func ReplayToVNet(filename string, vnet DataHandler, vnic ifs.IVNic, speed float64) error {
+	return Replay(filename, speed, func(frame *Frame) error {
+		if frame.Direction == In {
+			vnet.HandleData(frame.Data, vnic)
+		}
+		return nil
+	})
+}
+
+// ReplayToServices calls the service handlers of the vnic with the received requests
+// & notifications of the capture, replies are skipped as there is no request waiting
+// for them.
+func ReplayToServices(filename string, vnic ifs.IVNic, speed float64) error {
+	p := protocol.New(vnic)
+	return Replay(filename, speed, func(frame *Frame) error {
+		if frame.Direction != In {
+			return nil
+		}
+		msg, err := p.MessageOf(frame.Data)
+		if err != nil {
+			vnic.Resources().Logger().Error(err)
+			return nil
+		}
+		if msg.Reply() || msg.Action() == ifs.Reply {
+			return nil
+		}
+		pb, err := p.ElementsOf(msg)
+		if err != nil {
+			vnic.Resources().Logger().Error(err)
+			return nil
+		}
+		var resp ifs.IElements
+		if msg.Action() == ifs.Notify {
+			resp = vnic.Resources().Services().Notify(pb, vnic, msg, false)
+		} else {
+			resp = vnic.Resources().Services().Handle(pb, msg.Action(), msg, vnic)
+		}
+		if resp != nil && resp.Error() != nil {
+			vnic.Resources().Logger().Error(resp.Error())
+		}
+		return nil
+	})
+}
@@ -0,0 +1,218 @@
+// © 2025 Sharon Aicler (saichler@gmail.com)
+//
+// Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package dissector decodes raw overlay frames, the header, the message fields, the
+// extensions trailer and, if its type is registered, the payload, for offline debugging.
+package dissector
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	stdstrings "strings"
+	"time"
+
+	"github.com/saichler/l8bus/go/overlay/gateway"
+	"github.com/saichler/l8bus/go/overlay/protocol"
+	"github.com/saichler/l8bus/go/overlay/tracing"
+	"github.com/saichler/l8types/go/ifs"
+	"github.com/saichler/l8utils/go/utils/strings"
+)
+
+// Dissection is every field of a frame. Error is set when the message could not be
+// decoded past its header, PayloadError when the payload could not be decoded.
+type Dissection struct {
+	Size          int                    `json:"size"`
+	Source        string                 `json:"source"`
+	Vnet          string                 `json:"vnet"`
+	Destination   string                 `json:"destination"`
+	ServiceName   string                 `json:"serviceName"`
+	ServiceArea   byte                   `json:"serviceArea"`
+	Priority      int                    `json:"priority"`
+	MulticastMode string                 `json:"multicastMode"`
+	Action        string                 `json:"action,omitempty"`
+	Sequence      uint32                 `json:"sequence"`
+	Request       bool                   `json:"request"`
+	Reply         bool                   `json:"reply"`
+	FailMessage   string                 `json:"failMessage,omitempty"`
+	AAAId         string                 `json:"aaaId,omitempty"`
+	Transaction   *Transaction           `json:"transaction,omitempty"`
+	Extensions    []*Extension           `json:"extensions,omitempty"`
+	PayloadSize   int                    `json:"payloadSize"`
+	Payload       []*gateway.JsonElement `json:"payload,omitempty"`
+	PayloadError  string                 `json:"payloadError,omitempty"`
+	Error         string                 `json:"error,omitempty"`
+}
+
+// Transaction is the transaction state of a message
+type Transaction struct {
+	State     string `json:"state"`
+	Id        string `json:"id"`
+	ErrMsg    string `json:"errMsg,omitempty"`
+	Created   int64  `json:"created"`
+	Queued    int64  `json:"queued"`
+	Running   int64  `json:"running"`
+	End       int64  `json:"end"`
+	Timeout   int64  `json:"timeout"`
+	Replica   byte   `json:"replica"`
+	IsReplica bool   `json:"isReplica"`
+}
+
+// Extension is a field of the extensions trailer, Value is its hex or decoded value
+type Extension struct {
+	Type   byte   `json:"type"`
+	Name   string `json:"name"`
+	Length int    `json:"length"`
+	Value  string `json:"value"`
+}
+
+var actionNames = map[ifs.Action]string{
+	ifs.POST:   "POST",
+	ifs.PUT:    "PUT",
+	ifs.PATCH:  "PATCH",
+	ifs.DELETE: "DELETE",
+	ifs.GET:    "GET",
+	ifs.Notify: "Notify",
+	ifs.Reply:  "Reply",
+	ifs.Handle: "Handle",
+}
+
+var modeNames = map[ifs.MulticastMode]string{
+	ifs.M_All:        "all",
+	ifs.M_Leader:     "leader",
+	ifs.M_RoundRobin: "roundrobin",
+	ifs.M_Proximity:  "proximity",
+	ifs.M_Local:      "local",
+}
+
+var extensionNames = map[byte]string{
+	protocol.Ext_Trace:    "trace",
+	protocol.Ext_Probe:    "probe",
+	protocol.Ext_Checksum: "checksum",
+	protocol.Ext_Expiry:   "expiry",
+}
+
+// Dissect decodes a frame with the resources registry & security provider. It returns an
+// error only if the frame has no valid header.
+func Dissect(data []byte, resources ifs.IResources) (*Dissection, error) {
+	header, err := protocol.HeaderOf(data)
+	if err != nil {
+		return nil, err
+	}
+	d := &Dissection{Size: len(data)}
+	d.Source = header.Source
+	d.Vnet = header.SourceVnet
+	d.Destination = header.Destination
+	d.ServiceName = header.ServiceName
+	d.ServiceArea = header.ServiceArea
+	d.Priority = int(header.Priority)
+	d.MulticastMode = nameOf(modeNames, header.MulticastMode)
+	d.dissectMessage(data, resources)
+	return d, nil
+}
+
+// dissectMessage decodes the message fields, the extensions & the payload, a frame that
+// panics any of the decoders is set as the error of the dissection.
+func (this *Dissection) dissectMessage(data []byte, resources ifs.IResources) {
+	defer func() {
+		if r := recover(); r != nil {
+			this.Error = strings.New("Malformed frame: ", fmt.Sprint(r)).String()
+		}
+	}()
+	_, ext := protocol.SplitExtensions(data)
+	trace := tracing.Extract(data)
+	for t, value := range ext {
+		this.Extensions = append(this.Extensions, extensionOf(t, value, trace))
+	}
+	sort.Slice(this.Extensions, func(i, j int) bool {
+		return this.Extensions[i].Type < this.Extensions[j].Type
+	})
+	msg, err := protocol.MessageOf(data, resources)
+	if err != nil {
+		this.Error = err.Error()
+		return
+	}
+	this.Action = nameOf(actionNames, msg.Action())
+	this.Sequence = msg.Sequence()
+	this.Request = msg.Request()
+	this.Reply = msg.Reply()
+	this.FailMessage = msg.FailMessage()
+	this.AAAId = msg.AAAId()
+	if msg.Tr_State() != ifs.NotATransaction {
+		this.Transaction = &Transaction{State: strings.New(msg.Tr_State()).String(), Id: msg.Tr_Id(), ErrMsg: msg.Tr_ErrMsg(),
+			Created: msg.Tr_Created(), Queued: msg.Tr_Queued(), Running: msg.Tr_Running(), End: msg.Tr_End(),
+			Timeout: msg.Tr_Timeout(), Replica: msg.Tr_Replica(), IsReplica: msg.Tr_IsReplica()}
+	}
+	this.PayloadSize = len(msg.Data())
+	elems, err := protocol.ElementsOf(msg, resources)
+	if err != nil {
+		this.PayloadError = err.Error()
+		return
+	}
+	this.Payload, err = gateway.EncodeElements(elems)
+	if err != nil {
+		this.PayloadError = err.Error()
+	}
+}
+
+func extensionOf(t byte, value []byte, trace *tracing.TraceContext) *Extension {
+	e := &Extension{Type: t, Name: extensionNames[t], Length: len(value), Value: hex.EncodeToString(value)}
+	switch t {
+	case protocol.Ext_Trace:
+		if trace != nil {
+			e.Value = strings.New("trace=", trace.TraceIdString(), " span=", trace.SpanIdString(), " sampled=", fmt.Sprint(trace.Sampled)).String()
+		}
+	case protocol.Ext_Probe:
+		if len(value) > 0 {
+			e.Value = strings.New("hops=", int(value[0])).String()
+		}
+	case protocol.Ext_Expiry:
+		if len(value) == 8 {
+			e.Value = time.UnixMilli(int64(binary.BigEndian.Uint64(value))).Format(time.RFC3339Nano)
+		}
+	}
+	if e.Name == "" {
+		e.Name = "unknown"
+	}
+	return e
+}
+
+func nameOf[K comparable](names map[K]string, key K) string {
+	name, ok := names[key]
+	if ok {
+		return name
+	}
+	return fmt.Sprint(key)
+}
+
+// ParseFrame decodes a frame written in hex, with optional spaces, colons & 0x prefix,
+// or in standard or url base64.
+func ParseFrame(text string) ([]byte, error) {
+	text = stdstrings.TrimSpace(text)
+	clean := stdstrings.TrimPrefix(stdstrings.TrimPrefix(text, "0x"), "0X")
+	clean = stdstrings.NewReplacer(" ", "", ":", "", "\n", "", "\t", "").Replace(clean)
+	data, err := hex.DecodeString(clean)
+	if err == nil {
+		return data, nil
+	}
+	for _, encoding := range []*base64.Encoding{base64.StdEncoding, base64.URLEncoding, base64.RawStdEncoding, base64.RawURLEncoding} {
+		data, err = encoding.DecodeString(text)
+		if err == nil {
+			return data, nil
+		}
+	}
+	return nil, errors.New("Frame is neither hex nor base64")
+}
@@ -0,0 +1,74 @@
+// © 2025 Sharon Aicler (saichler@gmail.com)
+//
+// Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dissector
+
+import (
+	"fmt"
+	stdstrings "strings"
+)
+
+// Text renders the dissection as an indented field per line.
+func (this *Dissection) Text() string {
+	text := &stdstrings.Builder{}
+	fmt.Fprintf(text, "Frame: %d bytes\n", this.Size)
+	field(text, "Source", this.Source)
+	field(text, "Vnet", this.Vnet)
+	field(text, "Destination", this.Destination)
+	field(text, "Service", fmt.Sprintf("%s/%d", this.ServiceName, this.ServiceArea))
+	field(text, "Priority", fmt.Sprint(this.Priority))
+	field(text, "Multicast Mode", this.MulticastMode)
+	if this.Error != "" {
+		field(text, "Error", this.Error)
+	}
+	if this.Action != "" {
+		field(text, "Action", this.Action)
+		field(text, "Sequence", fmt.Sprint(this.Sequence))
+		field(text, "Request", fmt.Sprint(this.Request))
+		field(text, "Reply", fmt.Sprint(this.Reply))
+	}
+	if this.FailMessage != "" {
+		field(text, "Fail Message", this.FailMessage)
+	}
+	if this.AAAId != "" {
+		field(text, "AAA Id", this.AAAId)
+	}
+	if this.Transaction != nil {
+		tr := this.Transaction
+		text.WriteString("  Transaction:\n")
+		field(text, "  State", tr.State)
+		field(text, "  Id", tr.Id)
+		if tr.ErrMsg != "" {
+			field(text, "  Error", tr.ErrMsg)
+		}
+		field(text, "  Times", fmt.Sprintf("created=%d queued=%d running=%d end=%d timeout=%d", tr.Created, tr.Queued, tr.Running, tr.End, tr.Timeout))
+		field(text, "  Replica", fmt.Sprintf("%d isReplica=%v", tr.Replica, tr.IsReplica))
+	}
+	for _, ext := range this.Extensions {
+		field(text, "Extension", fmt.Sprintf("%s (%d) %d bytes: %s", ext.Name, ext.Type, ext.Length, ext.Value))
+	}
+	if this.Action != "" {
+		fmt.Fprintf(text, "  Payload: %d bytes\n", this.PayloadSize)
+		if this.PayloadError != "" {
+			field(text, "  Error", this.PayloadError)
+		}
+		for _, elem := range this.Payload {
+			field(text, "  "+elem.Type, string(elem.Body))
+		}
+	}
+	return text.String()
+}
+
+func field(text *stdstrings.Builder, name, value string) {
+	fmt.Fprintf(text, "  %-16s %s\n", name+":", value)
+}
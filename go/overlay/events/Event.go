@@ -0,0 +1,77 @@
+// © 2025 Sharon Aicler (saichler@gmail.com)
+//
+// Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package events is the typed stream of topology changes of a VNet. Local subscribers
+// register on the VNet EventBus, remote nodes subscribe to the VNetEvents service.
+package events
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/saichler/l8utils/go/utils/strings"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// EventType is the kind of topology change
+type EventType string
+
+const (
+	VNicConnected    EventType = "VNicConnected"
+	VNicDisconnected EventType = "VNicDisconnected"
+	RouteAdded       EventType = "RouteAdded"
+	RouteRemoved     EventType = "RouteRemoved"
+	ServiceAdded     EventType = "ServiceAdded"
+	ServiceRemoved   EventType = "ServiceRemoved"
+	LeaderChanged    EventType = "LeaderChanged"
+)
+
+// Event is a topology change reported by a VNet. Uuid & Alias are the vnic the event is
+// about, Link is the kind of connection of a connected vnic, RouteVnet is the VNet a
+// route leads to and PreviousLeader is the leader replaced on a LeaderChanged.
+type Event struct {
+	Type           EventType `json:"type"`
+	Vnet           string    `json:"vnet"`
+	VnetAlias      string    `json:"vnetAlias,omitempty"`
+	Time           int64     `json:"time"`
+	Uuid           string    `json:"uuid,omitempty"`
+	Alias          string    `json:"alias,omitempty"`
+	Link           string    `json:"link,omitempty"`
+	RouteVnet      string    `json:"routeVnet,omitempty"`
+	ServiceName    string    `json:"serviceName,omitempty"`
+	ServiceArea    byte      `json:"serviceArea"`
+	PreviousLeader string    `json:"previousLeader,omitempty"`
+}
+
+// ToBytes wraps the event json in a BytesValue to travel over the bus
+func (this *Event) ToBytes() (*wrapperspb.BytesValue, error) {
+	data, err := json.Marshal(this)
+	if err != nil {
+		return nil, err
+	}
+	return &wrapperspb.BytesValue{Value: data}, nil
+}
+
+// EventOf decodes an event sent over the bus
+func EventOf(any interface{}) (*Event, error) {
+	data, ok := any.(*wrapperspb.BytesValue)
+	if !ok {
+		return nil, errors.New(strings.New("Unexpected event type ", any).String())
+	}
+	event := &Event{}
+	err := json.Unmarshal(data.Value, event)
+	if err != nil {
+		return nil, err
+	}
+	return event, nil
+}
@@ -0,0 +1,131 @@
+// © 2025 Sharon Aicler (saichler@gmail.com)
+//
+// Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"runtime/debug"
+	"sync"
+
+	"github.com/saichler/l8types/go/ifs"
+)
+
+// DefaultBacklog is the number of events waiting to be delivered before new events
+// are dropped, so a slow subscriber never blocks the VNet
+const DefaultBacklog = 4096
+
+// EventBus delivers events to the local subscribers, in order, on its own goroutine. A
+// subscriber that panics is logged and does not stop the delivery to the others.
+type EventBus struct {
+	subscribers map[int]*subscriber
+	nextId      int
+	queue       chan *Event
+	mtx         sync.RWMutex
+	closed      bool
+	logger      ifs.ILogger
+	dropped     func(event *Event)
+}
+
+type subscriber struct {
+	fn    func(*Event)
+	types map[EventType]bool
+}
+
+// NewEventBus creates an event bus, dropped is called, if not nil, for every event that
+// did not fit in the backlog.
+func NewEventBus(logger ifs.ILogger, dropped func(event *Event)) *EventBus {
+	bus := &EventBus{subscribers: make(map[int]*subscriber), queue: make(chan *Event, DefaultBacklog),
+		logger: logger, dropped: dropped}
+	go bus.deliver()
+	return bus
+}
+
+// Subscribe registers fn for the given event types, or for all the events when no type is
+// given. It returns the subscription id to Unsubscribe.
+func (this *EventBus) Subscribe(fn func(*Event), types ...EventType) int {
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+	s := &subscriber{fn: fn}
+	if len(types) > 0 {
+		s.types = make(map[EventType]bool)
+		for _, t := range types {
+			s.types[t] = true
+		}
+	}
+	this.nextId++
+	this.subscribers[this.nextId] = s
+	return this.nextId
+}
+
+// Unsubscribe removes a subscription
+func (this *EventBus) Unsubscribe(id int) {
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+	delete(this.subscribers, id)
+}
+
+// Publish queues the event for delivery, it never blocks. An event published after the
+// bus was closed is ignored.
+func (this *EventBus) Publish(event *Event) {
+	this.mtx.RLock()
+	if this.closed {
+		this.mtx.RUnlock()
+		return
+	}
+	queued := true
+	select {
+	case this.queue <- event:
+	default:
+		queued = false
+	}
+	this.mtx.RUnlock()
+	if !queued && this.dropped != nil {
+		this.dropped(event)
+	}
+}
+
+// Close stops the delivery of events
+func (this *EventBus) Close() {
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+	if !this.closed {
+		this.closed = true
+		close(this.queue)
+	}
+}
+
+func (this *EventBus) deliver() {
+	for event := range this.queue {
+		this.mtx.RLock()
+		subscribers := make([]*subscriber, 0, len(this.subscribers))
+		for _, s := range this.subscribers {
+			if s.types == nil || s.types[event.Type] {
+				subscribers = append(subscribers, s)
+			}
+		}
+		this.mtx.RUnlock()
+		for _, s := range subscribers {
+			this.notify(s, event)
+		}
+	}
+}
+
+// notify calls the subscriber with the event, recovering a panic of the subscriber
+func (this *EventBus) notify(s *subscriber, event *Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			this.logger.Error("Event subscriber panicked on ", string(event.Type), " event: ", r, "\n", string(debug.Stack()))
+		}
+	}()
+	s.fn(event)
+}
@@ -0,0 +1,86 @@
+// © 2025 Sharon Aicler (saichler@gmail.com)
+//
+// Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"github.com/saichler/l8types/go/ifs"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// ServiceName is the service VNets multicast their events to, a node receives them by
+// running it.
+const (
+	ServiceName = "VNetEvents"
+	ServiceArea = byte(0)
+)
+
+// Subscribe activates the events service on the vnic, fn is called for every event
+// multicast by the VNets of the overlay.
+func Subscribe(vnic ifs.IVNic, fn func(*Event)) {
+	sla := ifs.NewServiceLevelAgreement(&EventsService{fn: fn}, ServiceName, ServiceArea, false, nil)
+	vnic.Resources().Services().Activate(sla, vnic)
+}
+
+// EventsService receives the events a VNet multicasts on Post.
+type EventsService struct {
+	fn func(*Event)
+}
+
+// Activate registers the event type with the registry when the service starts.
+func (this *EventsService) Activate(sla *ifs.ServiceLevelAgreement, vnic ifs.IVNic) error {
+	vnic.Resources().Registry().Register(&wrapperspb.BytesValue{})
+	return nil
+}
+
+// DeActivate is called when the service is stopped.
+func (this *EventsService) DeActivate() error {
+	return nil
+}
+
+// Post decodes the event and calls the subscriber.
+func (this *EventsService) Post(pb ifs.IElements, vnic ifs.IVNic) ifs.IElements {
+	event, err := EventOf(pb.Element())
+	if err != nil {
+		vnic.Resources().Logger().Error(err)
+		return nil
+	}
+	this.fn(event)
+	return nil
+}
+func (this *EventsService) Put(pb ifs.IElements, vnic ifs.IVNic) ifs.IElements {
+	return nil
+}
+func (this *EventsService) Patch(pb ifs.IElements, vnic ifs.IVNic) ifs.IElements {
+	return nil
+}
+func (this *EventsService) Delete(pb ifs.IElements, vnic ifs.IVNic) ifs.IElements {
+	return nil
+}
+func (this *EventsService) GetCopy(pb ifs.IElements, vnic ifs.IVNic) ifs.IElements {
+	return nil
+}
+func (this *EventsService) Get(pb ifs.IElements, vnic ifs.IVNic) ifs.IElements {
+	return nil
+}
+func (this *EventsService) Failed(pb ifs.IElements, vnic ifs.IVNic, msg *ifs.Message) ifs.IElements {
+	return nil
+}
+
+func (this *EventsService) TransactionConfig() ifs.ITransactionConfig {
+	return nil
+}
+
+func (this *EventsService) WebService() ifs.IWebService {
+	return nil
+}
@@ -0,0 +1,85 @@
+// © 2025 Sharon Aicler (saichler@gmail.com)
+//
+// Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package gateway lets clients that do not embed a VirtualNetworkInterface, such as
+// browsers and scripts, issue requests to services and receive their notifications
+// using JSON encoded messages.
+package gateway
+
+import (
+	"github.com/saichler/l8types/go/ifs"
+)
+
+// Bus is the access to the overlay the gateways need in order to serve JSON clients.
+type Bus interface {
+	// ServiceRequest sends a request to the service and waits for the reply. When the
+	// destination is empty, the provider is selected by the multicast mode.
+	ServiceRequest(destination, serviceName string, serviceArea byte, action ifs.Action,
+		mode ifs.MulticastMode, data interface{}, timeout int, token string) ifs.IElements
+	Resources() ifs.IResources
+}
+
+// Subscriber is implemented by buses that can deliver service notifications to gateway clients.
+type Subscriber interface {
+	// Subscribe registers the function to be invoked with the notifications of the service,
+	// the id identifies the subscribing client.
+	Subscribe(id, serviceName string, serviceArea byte, fn func(notification ifs.IElements))
+	// Unsubscribe removes the client subscription to the service.
+	Unsubscribe(id, serviceName string, serviceArea byte)
+	// UnsubscribeAll removes all the subscriptions of the client.
+	UnsubscribeAll(id string)
+}
+
+// VnicBus serves the gateways over a VNic, for running a gateway as a standalone process.
+type VnicBus struct {
+	vnic ifs.IVNic
+}
+
+// NewVnicBus creates a bus over the given VNic.
+func NewVnicBus(vnic ifs.IVNic) *VnicBus {
+	return &VnicBus{vnic: vnic}
+}
+
+// ServiceRequest sends the request via the vnic using the request flavor of the mode.
+func (this *VnicBus) ServiceRequest(destination, serviceName string, serviceArea byte, action ifs.Action,
+	mode ifs.MulticastMode, data interface{}, timeout int, token string) ifs.IElements {
+	if destination != "" {
+		return this.vnic.Request(destination, serviceName, serviceArea, action, data, timeout, token)
+	}
+	switch mode {
+	case ifs.M_Leader:
+		return this.vnic.LeaderRequest(serviceName, serviceArea, action, data, timeout, token)
+	case ifs.M_RoundRobin:
+		return this.vnic.RoundRobinRequest(serviceName, serviceArea, action, data, timeout, token)
+	case ifs.M_Proximity:
+		return this.vnic.ProximityRequest(serviceName, serviceArea, action, data, timeout, token)
+	case ifs.M_Local:
+		return this.vnic.LocalRequest(serviceName, serviceArea, action, data, timeout, token)
+	}
+	return this.vnic.Request(destination, serviceName, serviceArea, action, data, timeout, token)
+}
+
+// Resources returns the resources of the vnic.
+func (this *VnicBus) Resources() ifs.IResources {
+	return this.vnic.Resources()
+}
+
+// Authenticate validates the token with the security provider, a client without a token,
+// or with a token the security provider does not validate, is rejected.
+func Authenticate(resources ifs.IResources, token string) bool {
+	if token == "" {
+		return false
+	}
+	_, valid := resources.Security().ValidateToken(token)
+	return valid
+}
@@ -0,0 +1,183 @@
+// © 2025 Sharon Aicler (saichler@gmail.com)
+//
+// Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gateway
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	stdstrings "strings"
+
+	"github.com/saichler/l8bus/go/overlay/protocol"
+	"github.com/saichler/l8types/go/ifs"
+	"github.com/saichler/l8utils/go/utils/strings"
+)
+
+// HttpGateway serves the services on the bus over http. A request to METHOD /service/area
+// is translated to a service request with the method as the action. Query parameters:
+//   - type: the type name of the JSON body, one of the bodies the service registered for
+//     the action with AddEndpoint
+//   - query: a query string, e.g. for GET requests instead of a body
+//   - mode: leader, roundrobin, proximity, local or all (default)
+//   - destination: the uuid of a specific provider
+//   - timeout: the request timeout in seconds
+//
+// A bearer token in the Authorization header is forwarded with the request.
+type HttpGateway struct {
+	bus    Bus
+	server *http.Server
+}
+
+// NewHttpGateway creates an http gateway over the bus, a VNet or a VnicBus for a standalone gateway.
+// The gateway is an http.Handler, so it can also be mounted on an existing server.
+func NewHttpGateway(bus Bus) *HttpGateway {
+	return &HttpGateway{bus: bus}
+}
+
+// Start serves the gateway on the given port.
+func (this *HttpGateway) Start(port uint32) error {
+	listener, err := net.Listen("tcp", strings.New(":", int(port)).String())
+	if err != nil {
+		return err
+	}
+	this.server = &http.Server{Handler: this}
+	go this.server.Serve(listener)
+	return nil
+}
+
+// Shutdown stops serving the gateway.
+func (this *HttpGateway) Shutdown() {
+	if this.server != nil {
+		this.server.Close()
+	}
+}
+
+func (this *HttpGateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	token := bearerToken(r)
+	if !Authenticate(this.bus.Resources(), token) {
+		writeJson(w, http.StatusUnauthorized, &JsonMessage{Error: "Unauthorized"})
+		return
+	}
+	serviceName, serviceArea, err := servicePath(r.URL.Path)
+	if err != nil {
+		writeJson(w, http.StatusNotFound, &JsonMessage{Error: err.Error()})
+		return
+	}
+	action, err := ParseAction(r.Method)
+	if err != nil {
+		writeJson(w, http.StatusMethodNotAllowed, &JsonMessage{Error: err.Error()})
+		return
+	}
+	params := r.URL.Query()
+	mode, err := ParseMode(params.Get("mode"))
+	if err != nil {
+		writeJson(w, http.StatusBadRequest, &JsonMessage{Error: err.Error()})
+		return
+	}
+	timeout := DEFAULT_TIMEOUT
+	if params.Get("timeout") != "" {
+		timeout, err = strconv.Atoi(params.Get("timeout"))
+		if err != nil || timeout <= 0 {
+			writeJson(w, http.StatusBadRequest, &JsonMessage{Error: "Invalid timeout " + params.Get("timeout")})
+			return
+		}
+	}
+	// a body is bounded by the largest message the bus carries
+	maxSize := int64(this.bus.Resources().SysConfig().MaxDataSize)
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxSize))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeJson(w, http.StatusRequestEntityTooLarge, &JsonMessage{Error: err.Error()})
+			return
+		}
+		writeJson(w, http.StatusBadRequest, &JsonMessage{Error: err.Error()})
+		return
+	}
+	msg := &JsonMessage{Type: params.Get("type"), Query: params.Get("query"), Body: body}
+	if msg.Type == "" && msg.Query == "" && len(stdstrings.TrimSpace(string(body))) > 0 {
+		writeJson(w, http.StatusBadRequest, &JsonMessage{Error: "Missing the type of the request body"})
+		return
+	}
+	data, err := EndpointData(this.bus.Resources(), serviceName, serviceArea, action, msg)
+	if err != nil {
+		writeJson(w, http.StatusBadRequest, &JsonMessage{Error: err.Error()})
+		return
+	}
+	resp := this.bus.ServiceRequest(params.Get("destination"), serviceName, serviceArea, action, mode, data, timeout, token)
+	reply := Reply(0, resp)
+	reply.Op = ""
+	writeJson(w, StatusOf(resp), reply)
+}
+
+// StatusOf returns the http status code for the response of a service request, a failure
+// of the overlay maps to its own status and any other error is a failure of the service.
+func StatusOf(resp ifs.IElements) int {
+	if resp == nil {
+		return http.StatusGatewayTimeout
+	}
+	if resp.Error() == nil {
+		return http.StatusOK
+	}
+	switch protocol.FailureOf(resp.Error()) {
+	case protocol.ErrNoProvider:
+		return http.StatusServiceUnavailable
+	case protocol.ErrNoDestination, protocol.ErrSendFailed:
+		return http.StatusBadGateway
+	case protocol.ErrExpired:
+		return http.StatusGatewayTimeout
+	}
+	return http.StatusInternalServerError
+}
+
+// servicePath parses /service/area, the area defaults to 0.
+func servicePath(path string) (string, byte, error) {
+	parts := stdstrings.Split(stdstrings.Trim(path, "/"), "/")
+	if len(parts) == 0 || parts[0] == "" || len(parts) > 2 {
+		return "", 0, &pathError{path: path}
+	}
+	if len(parts) == 1 {
+		return parts[0], 0, nil
+	}
+	area, err := strconv.Atoi(parts[1])
+	if err != nil || area < 0 || area > 255 {
+		return "", 0, &pathError{path: path}
+	}
+	return parts[0], byte(area), nil
+}
+
+type pathError struct {
+	path string
+}
+
+func (this *pathError) Error() string {
+	return strings.New("Invalid service path ", this.path, ", expected /service/area").String()
+}
+
+func bearerToken(r *http.Request) string {
+	auth := r.Header.Get("Authorization")
+	if stdstrings.HasPrefix(auth, "Bearer ") {
+		return stdstrings.TrimPrefix(auth, "Bearer ")
+	}
+	return r.URL.Query().Get("token")
+}
+
+func writeJson(w http.ResponseWriter, status int, msg *JsonMessage) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(msg)
+}
@@ -0,0 +1,207 @@
+// © 2025 Sharon Aicler (saichler@gmail.com)
+//
+// Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gateway
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	stdstrings "strings"
+
+	"github.com/saichler/l8types/go/ifs"
+	"github.com/saichler/l8utils/go/utils/strings"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// JSON message operations
+const (
+	OpRequest      = "request"
+	OpReply        = "reply"
+	OpSubscribe    = "subscribe"
+	OpUnsubscribe  = "unsubscribe"
+	OpNotification = "notification"
+	OpError        = "error"
+)
+
+// JsonMessage is a single frame exchanged with JSON clients. Requests, replies and notifications
+// share the same shape, a client correlates a reply to its request by the Id.
+type JsonMessage struct {
+	Id          int64           `json:"id,omitempty"`
+	Op          string          `json:"op,omitempty"`
+	Destination string          `json:"destination,omitempty"`
+	ServiceName string          `json:"serviceName,omitempty"`
+	ServiceArea int32           `json:"serviceArea,omitempty"`
+	Action      string          `json:"action,omitempty"`
+	Mode        string          `json:"mode,omitempty"`
+	Type        string          `json:"type,omitempty"`
+	Query       string          `json:"query,omitempty"`
+	Body        json.RawMessage `json:"body,omitempty"`
+	Timeout     int             `json:"timeout,omitempty"`
+	Token       string          `json:"token,omitempty"`
+	Error       string          `json:"error,omitempty"`
+	Elements    []*JsonElement  `json:"elements,omitempty"`
+}
+
+// JsonElement is a single payload element with its registry type name.
+type JsonElement struct {
+	Type string          `json:"type"`
+	Body json.RawMessage `json:"body"`
+}
+
+var actions = map[string]ifs.Action{
+	"POST":   ifs.POST,
+	"PUT":    ifs.PUT,
+	"PATCH":  ifs.PATCH,
+	"DELETE": ifs.DELETE,
+	"GET":    ifs.GET,
+}
+
+var modes = map[string]ifs.MulticastMode{
+	"":           ifs.M_All,
+	"all":        ifs.M_All,
+	"leader":     ifs.M_Leader,
+	"roundrobin": ifs.M_RoundRobin,
+	"proximity":  ifs.M_Proximity,
+	"local":      ifs.M_Local,
+}
+
+// ParseAction returns the action for an http method style name such as GET or POST.
+func ParseAction(name string) (ifs.Action, error) {
+	action, ok := actions[stdstrings.ToUpper(name)]
+	if !ok {
+		return 0, errors.New(strings.New("Unknown action ", name).String())
+	}
+	return action, nil
+}
+
+// actionName returns the http method style name of the action
+func actionName(action ifs.Action) string {
+	for name, a := range actions {
+		if a == action {
+			return name
+		}
+	}
+	return strings.New(int(action)).String()
+}
+
+// ParseMode returns the multicast mode for a name such as leader or roundrobin, empty is all.
+func ParseMode(name string) (ifs.MulticastMode, error) {
+	mode, ok := modes[stdstrings.ToLower(name)]
+	if !ok {
+		return ifs.M_All, errors.New(strings.New("Unknown mode ", name).String())
+	}
+	return mode, nil
+}
+
+// DecodeBody creates a new instance of the registered type and fills it from the JSON body.
+func DecodeBody(resources ifs.IResources, typeName string, body []byte) (proto.Message, error) {
+	info, err := resources.Registry().Info(typeName)
+	if err != nil {
+		return nil, err
+	}
+	instance, err := info.NewInstance()
+	if err != nil {
+		return nil, err
+	}
+	pb, ok := instance.(proto.Message)
+	if !ok {
+		return nil, errors.New(strings.New("Type ", typeName, " is not a protobuf message").String())
+	}
+	if len(body) > 0 {
+		err = protojson.Unmarshal(body, pb)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return pb, nil
+}
+
+// RequestData returns the data to send for a JSON request, a query string for queries,
+// a registered type instance for bodies and nil when there is neither.
+func RequestData(resources ifs.IResources, msg *JsonMessage) (interface{}, error) {
+	if msg.Query != "" {
+		return msg.Query, nil
+	}
+	if msg.Type == "" {
+		return nil, nil
+	}
+	return DecodeBody(resources, msg.Type, msg.Body)
+}
+
+// EndpointData returns the data to send for a JSON request to the service, per the endpoints
+// the service registered with web.New & AddEndpoint. The body type must be one of the bodies
+// of the action. A service not hosted by this node has no endpoints here, its body is
+// decoded as the registered type.
+func EndpointData(resources ifs.IResources, serviceName string, serviceArea byte, action ifs.Action,
+	msg *JsonMessage) (interface{}, error) {
+	if msg.Query != "" || msg.Type == "" {
+		return RequestData(resources, msg)
+	}
+	handler, ok := resources.Services().ServiceHandler(serviceName, serviceArea)
+	if !ok || handler.WebService() == nil {
+		return RequestData(resources, msg)
+	}
+	body, _, err := handler.WebService().Protos(msg.Type, action)
+	if err != nil || body == nil {
+		return nil, errors.New(strings.New("Service ", serviceName, " area ", int(serviceArea),
+			" has no ", msg.Type, " endpoint for ", actionName(action)).String())
+	}
+	pb := body.ProtoReflect().New().Interface()
+	if len(msg.Body) > 0 {
+		err = protojson.Unmarshal(msg.Body, pb)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return pb, nil
+}
+
+// EncodeElements encodes the protobuf elements as JSON elements.
+func EncodeElements(elems ifs.IElements) ([]*JsonElement, error) {
+	result := make([]*JsonElement, 0)
+	if elems == nil {
+		return result, nil
+	}
+	for _, elem := range elems.Elements() {
+		pb, ok := elem.(proto.Message)
+		if !ok || pb == nil || reflect.ValueOf(pb).IsNil() {
+			continue
+		}
+		body, err := protojson.Marshal(pb)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, &JsonElement{Type: reflect.TypeOf(pb).Elem().Name(), Body: body})
+	}
+	return result, nil
+}
+
+// Reply creates the JSON reply for the given request id and response elements.
+func Reply(id int64, resp ifs.IElements) *JsonMessage {
+	reply := &JsonMessage{Id: id, Op: OpReply}
+	if resp == nil {
+		return reply
+	}
+	if resp.Error() != nil {
+		reply.Error = resp.Error().Error()
+	}
+	elements, err := EncodeElements(resp)
+	if err != nil {
+		reply.Error = err.Error()
+		return reply
+	}
+	reply.Elements = elements
+	return reply
+}
@@ -0,0 +1,137 @@
+// © 2025 Sharon Aicler (saichler@gmail.com)
+//
+// Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gateway
+
+import (
+	"sync"
+
+	"github.com/gorilla/websocket"
+	"github.com/saichler/l8types/go/ifs"
+)
+
+// DEFAULT_TIMEOUT is the request timeout in seconds used when the client does not specify one.
+const DEFAULT_TIMEOUT = 15
+
+// JsonSession serves a single JSON websocket client. Requests are handled concurrently
+// and replied with the request id, notifications of subscribed services are pushed as they arrive.
+type JsonSession struct {
+	id    string
+	ws    *websocket.Conn
+	bus   Bus
+	token string
+	wMtx  *sync.Mutex
+}
+
+// NewJsonSession creates a session for the websocket client, the token is used for
+// requests that do not carry their own token. A request token is validated like the
+// session token before it is used.
+func NewJsonSession(ws *websocket.Conn, bus Bus, token string) *JsonSession {
+	return &JsonSession{id: ifs.NewUuid(), ws: ws, bus: bus, token: token, wMtx: &sync.Mutex{}}
+}
+
+// Serve reads the client messages until the websocket is closed.
+func (this *JsonSession) Serve() {
+	defer this.close()
+	for {
+		msg := &JsonMessage{}
+		err := this.ws.ReadJSON(msg)
+		if err != nil {
+			return
+		}
+		switch msg.Op {
+		case OpRequest, "":
+			go this.request(msg)
+		case OpSubscribe:
+			this.subscribe(msg)
+		case OpUnsubscribe:
+			this.unsubscribe(msg)
+		default:
+			this.write(&JsonMessage{Id: msg.Id, Op: OpError, Error: "Unknown op " + msg.Op})
+		}
+	}
+}
+
+func (this *JsonSession) request(msg *JsonMessage) {
+	action, err := ParseAction(msg.Action)
+	if err != nil {
+		this.write(&JsonMessage{Id: msg.Id, Op: OpReply, Error: err.Error()})
+		return
+	}
+	mode, err := ParseMode(msg.Mode)
+	if err != nil {
+		this.write(&JsonMessage{Id: msg.Id, Op: OpReply, Error: err.Error()})
+		return
+	}
+	data, err := EndpointData(this.bus.Resources(), msg.ServiceName, byte(msg.ServiceArea), action, msg)
+	if err != nil {
+		this.write(&JsonMessage{Id: msg.Id, Op: OpReply, Error: err.Error()})
+		return
+	}
+	timeout := msg.Timeout
+	if timeout <= 0 {
+		timeout = DEFAULT_TIMEOUT
+	}
+	token := msg.Token
+	if token == "" {
+		token = this.token
+	} else if !Authenticate(this.bus.Resources(), token) {
+		this.write(&JsonMessage{Id: msg.Id, Op: OpReply, Error: "Invalid token"})
+		return
+	}
+	resp := this.bus.ServiceRequest(msg.Destination, msg.ServiceName, byte(msg.ServiceArea), action, mode, data, timeout, token)
+	this.write(Reply(msg.Id, resp))
+}
+
+func (this *JsonSession) subscribe(msg *JsonMessage) {
+	subscriber, ok := this.bus.(Subscriber)
+	if !ok {
+		this.write(&JsonMessage{Id: msg.Id, Op: OpError, Error: "Subscriptions are not supported"})
+		return
+	}
+	serviceName := msg.ServiceName
+	serviceArea := msg.ServiceArea
+	subscriber.Subscribe(this.id, serviceName, byte(serviceArea), func(notification ifs.IElements) {
+		elements, err := EncodeElements(notification)
+		if err != nil {
+			return
+		}
+		this.write(&JsonMessage{Op: OpNotification, ServiceName: serviceName, ServiceArea: serviceArea, Elements: elements})
+	})
+	this.write(&JsonMessage{Id: msg.Id, Op: OpReply})
+}
+
+func (this *JsonSession) unsubscribe(msg *JsonMessage) {
+	subscriber, ok := this.bus.(Subscriber)
+	if ok {
+		subscriber.Unsubscribe(this.id, msg.ServiceName, byte(msg.ServiceArea))
+	}
+	this.write(&JsonMessage{Id: msg.Id, Op: OpReply})
+}
+
+func (this *JsonSession) write(msg *JsonMessage) {
+	this.wMtx.Lock()
+	defer this.wMtx.Unlock()
+	err := this.ws.WriteJSON(msg)
+	if err != nil {
+		this.bus.Resources().Logger().Debug("Failed to write to json client: ", err.Error())
+	}
+}
+
+func (this *JsonSession) close() {
+	subscriber, ok := this.bus.(Subscriber)
+	if ok {
+		subscriber.UnsubscribeAll(this.id)
+	}
+	this.ws.Close()
+}
@@ -16,11 +16,13 @@ package metrics
 import (
 	"context"
 	"errors"
+	stdstrings "strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/saichler/l8types/go/ifs"
+	"github.com/saichler/l8utils/go/utils/strings"
 )
 
 // CircuitBreakerState represents the state of a circuit breaker
@@ -163,6 +165,28 @@ func (cb *CircuitBreaker) Execute(fn func() (interface{}, error)) (interface{},
 	}
 }
 
+// Allow checks if the circuit breaker allows a call whose outcome is reported by the caller
+// via RecordSuccess or RecordFailure, for calls that manage their own timeout
+func (cb *CircuitBreaker) Allow() error {
+	if err := cb.canExecute(); err != nil {
+		return err
+	}
+	requestCounter := cb.registry.Counter("layer8_circuit_breaker_requests_total",
+		map[string]string{"name": cb.name, "state": cb.GetState().String()})
+	requestCounter.Inc()
+	return nil
+}
+
+// RecordSuccess reports a successful call allowed by Allow
+func (cb *CircuitBreaker) RecordSuccess() {
+	cb.recordSuccess()
+}
+
+// RecordFailure reports a failed call allowed by Allow
+func (cb *CircuitBreaker) RecordFailure() {
+	cb.recordFailure()
+}
+
 // canExecute checks if the circuit breaker allows execution
 func (cb *CircuitBreaker) canExecute() error {
 	state := cb.GetState()
@@ -262,6 +286,16 @@ func (cb *CircuitBreaker) transitionToHalfOpen() {
 	cb.updateMetrics()
 }
 
+// IsOpen returns true if the circuit breaker is open and its reset timeout has not passed,
+// e.g. a call would fail fast. Unlike Allow it does not transition the circuit breaker.
+func (cb *CircuitBreaker) IsOpen() bool {
+	if cb.GetState() != OpenState {
+		return false
+	}
+	lastFail := atomic.LoadInt64(&cb.lastFailTime)
+	return time.Now().Unix()-lastFail < int64(cb.config.ResetTimeout.Seconds())
+}
+
 // GetState returns the current state of the circuit breaker
 func (cb *CircuitBreaker) GetState() CircuitBreakerState {
 	return CircuitBreakerState(atomic.LoadInt32(&cb.state))
@@ -342,6 +376,23 @@ func (m *CircuitBreakerManager) GetOrCreate(name string, config *CircuitBreakerC
 	return breaker
 }
 
+// ServiceBreakerName returns the name of the circuit breaker guarding the requests
+// to the service instance on the destination
+func ServiceBreakerName(serviceName string, serviceArea byte, destination string) string {
+	return strings.New("service_", serviceName, "_", int(serviceArea), "_", destination).String()
+}
+
+// ForService gets or creates the circuit breaker of the service instance on the destination
+func (m *CircuitBreakerManager) ForService(serviceName string, serviceArea byte, destination string) *CircuitBreaker {
+	return m.GetOrCreate(ServiceBreakerName(serviceName, serviceArea, destination), DefaultCircuitBreakerConfig())
+}
+
+// IsOpen returns true if the circuit breaker of the service instance on the destination is open
+func (m *CircuitBreakerManager) IsOpen(serviceName string, serviceArea byte, destination string) bool {
+	breaker, exists := m.Get(ServiceBreakerName(serviceName, serviceArea, destination))
+	return exists && breaker.IsOpen()
+}
+
 // Get returns an existing circuit breaker
 func (m *CircuitBreakerManager) Get(name string) (*CircuitBreaker, bool) {
 	m.mutex.RLock()
@@ -363,14 +414,37 @@ func (m *CircuitBreakerManager) GetAll() map[string]*CircuitBreaker {
 	return breakers
 }
 
-// Remove removes a circuit breaker from the manager
-// Note: This does not clean up metrics in the registry - that remains a known limitation
+// Remove removes a circuit breaker from the manager, with its metrics in the registry
 func (m *CircuitBreakerManager) Remove(name string) {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 
-	if _, exists := m.breakers[name]; exists {
-		delete(m.breakers, name)
-		m.logger.Debug("Removed circuit breaker:", name)
+	m.remove(name)
+}
+
+// RemoveDestination removes the circuit breakers of all the service instances on the destination,
+// e.g. when it disconnects
+func (m *CircuitBreakerManager) RemoveDestination(destination string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	suffix := "_" + destination
+	for name := range m.breakers {
+		if stdstrings.HasPrefix(name, "service_") && stdstrings.HasSuffix(name, suffix) {
+			m.remove(name)
+		}
+	}
+}
+
+func (m *CircuitBreakerManager) remove(name string) {
+	if _, exists := m.breakers[name]; !exists {
+		return
+	}
+	delete(m.breakers, name)
+	for _, state := range []string{"closed", "open", "half_open"} {
+		m.registry.Remove("layer8_circuit_breaker_requests_total", map[string]string{"name": name, "state": state})
 	}
+	m.registry.Remove("layer8_circuit_breaker_failures", map[string]string{"name": name})
+	m.registry.Remove("layer8_circuit_breaker_state", map[string]string{"name": name})
+	m.logger.Debug("Removed circuit breaker:", name)
 }
\ No newline at end of file
@@ -65,6 +65,9 @@ type ConnectionMetrics struct {
 	ErrorCount       int64
 	TimeoutCount     int64
 	
+	// Recent latency, used for scoring so a degraded tail is noticed quickly
+	latencyWindow    *WindowHistogram
+
 	// Health scoring
 	HealthScore      int64 // 0-100, where 100 is perfect health
 	State            int32 // ConnectionHealthState
@@ -75,12 +78,13 @@ type ConnectionMetrics struct {
 // NewConnectionMetrics creates a new connection metrics tracker
 func NewConnectionMetrics(connectionID, remoteAddr string) *ConnectionMetrics {
 	return &ConnectionMetrics{
-		ConnectionID: connectionID,
-		RemoteAddr:   remoteAddr,
-		ConnectedAt:  time.Now(),
-		LastActivity: time.Now().Unix(),
-		HealthScore:  100, // Start with perfect health
-		State:        int32(HealthyState),
+		ConnectionID:  connectionID,
+		RemoteAddr:    remoteAddr,
+		ConnectedAt:   time.Now(),
+		LastActivity:  time.Now().Unix(),
+		HealthScore:   100, // Start with perfect health
+		State:         int32(HealthyState),
+		latencyWindow: NewWindowHistogram(DefaultWindow, DefaultWindowSlots),
 	}
 }
 
@@ -104,6 +108,7 @@ func (c *ConnectionMetrics) RecordMessageReceived(bytes int64) {
 func (c *ConnectionMetrics) RecordLatency(latencyMs int64) {
 	atomic.AddInt64(&c.LatencySum, latencyMs)
 	atomic.AddInt64(&c.LatencyCount, 1)
+	c.latencyWindow.Observe(latencyMs)
 	c.updateHealthScore()
 }
 
@@ -129,6 +134,11 @@ func (c *ConnectionMetrics) GetAverageLatency() float64 {
 	return float64(sum) / float64(count)
 }
 
+// GetLatencyPercentiles returns the latency percentiles over the last window
+func (c *ConnectionMetrics) GetLatencyPercentiles() LatencyPercentiles {
+	return c.latencyWindow.Percentiles()
+}
+
 // GetHealthScore returns the current health score (0-100)
 func (c *ConnectionMetrics) GetHealthScore() int64 {
 	return atomic.LoadInt64(&c.HealthScore)
@@ -159,14 +169,15 @@ func (c *ConnectionMetrics) updateHealthScore() {
 		score -= int64(timeoutRate * 30) // Timeouts can reduce score by up to 30 points
 	}
 
-	// Factor 3: Latency (penalize high latency)
-	avgLatency := c.GetAverageLatency()
-	if avgLatency > 100 { // More than 100ms is considered degraded
-		latencyPenalty := (avgLatency - 100) / 10 // 1 point per 10ms over 100ms
-		if latencyPenalty > 20 {
-			latencyPenalty = 20 // Cap at 20 points
+	// Factor 3: Latency (penalize high tail latency over the recent window, so a sudden
+	// degradation is not hidden by the lifetime average)
+	p99Latency := c.latencyWindow.Percentile(99)
+	if p99Latency > 100 { // More than 100ms is considered degraded
+		latencyPenalty := (p99Latency - 100) / 10 // 1 point per 10ms over 100ms
+		if latencyPenalty > 50 {
+			latencyPenalty = 50 // Cap at 50 points, enough to score the connection unhealthy
 		}
-		score -= int64(latencyPenalty)
+		score -= latencyPenalty
 	}
 
 	// Factor 4: Inactivity (penalize connections with no recent activity)
@@ -217,6 +228,7 @@ func (c *ConnectionMetrics) GetSnapshot() ConnectionMetricsSnapshot {
 		BytesSent:        atomic.LoadInt64(&c.BytesSent),
 		BytesReceived:    atomic.LoadInt64(&c.BytesReceived),
 		AverageLatency:   c.GetAverageLatency(),
+		Latency:          c.latencyWindow.Percentiles(),
 		ErrorCount:       atomic.LoadInt64(&c.ErrorCount),
 		TimeoutCount:     atomic.LoadInt64(&c.TimeoutCount),
 		HealthScore:      atomic.LoadInt64(&c.HealthScore),
@@ -235,6 +247,7 @@ type ConnectionMetricsSnapshot struct {
 	BytesSent        int64
 	BytesReceived    int64
 	AverageLatency   float64
+	Latency          LatencyPercentiles
 	ErrorCount       int64
 	TimeoutCount     int64
 	HealthScore      int64
@@ -0,0 +1,287 @@
+// © 2025 Sharon Aicler (saichler@gmail.com)
+//
+// Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	stdstrings "strings"
+	"sync/atomic"
+
+	"github.com/saichler/l8utils/go/utils/strings"
+)
+
+// Content types of the exposition formats
+const (
+	TextContentType        = "text/plain; version=0.0.4; charset=utf-8"
+	OpenMetricsContentType = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+)
+
+// defaultHelp describes the metrics recorded by the overlay itself
+var defaultHelp = map[string]string{
+	"layer8_messages_sent_total":            "Total number of messages sent",
+	"layer8_messages_received_total":        "Total number of messages received",
+	"layer8_bytes_sent_total":               "Total number of bytes sent",
+	"layer8_bytes_received_total":           "Total number of bytes received",
+	"layer8_connection_errors_total":        "Total number of connection errors",
+	"layer8_message_latency_ms":             "Message round trip latency in milliseconds",
+	"layer8_message_latency_window_ms":      "Message round trip latency quantiles over the last minute in milliseconds",
+	"layer8_request_timeouts_total":         "Total number of requests that timed out",
+	"layer8_connections_total":              "Number of monitored connections",
+	"layer8_circuit_breaker_requests_total": "Total number of requests through a circuit breaker by state",
+	"layer8_circuit_breaker_failures":       "Current number of failures of a circuit breaker",
+	"layer8_circuit_breaker_state":          "Current state of a circuit breaker, 0 closed, 1 open, 2 half open",
+}
+
+// family is all the samples of a single metric name
+type family struct {
+	name       string
+	metricType MetricType
+	metrics    []*Metric
+	histograms []*HistogramMetric
+	windows    []*windowSample
+}
+
+type windowSample struct {
+	labels map[string]string
+	window *WindowHistogram
+}
+
+// windowQuantiles are the quantiles rendered for sliding window histograms
+var windowQuantiles = []float64{0.5, 0.9, 0.99, 0.999}
+
+// WriteText renders the registry in the Prometheus text format, or in the OpenMetrics
+// format when openMetrics is true. Histogram buckets are cumulative.
+func (r *MetricsRegistry) WriteText(w io.Writer, openMetrics bool) error {
+	writer := bufio.NewWriter(w)
+	for _, f := range r.families() {
+		name := sanitizeName(f.name, true)
+		familyName := name
+		if openMetrics && f.metricType == CounterType {
+			familyName = stdstrings.TrimSuffix(name, "_total")
+		}
+		help := r.GetHelp(f.name)
+		if help == "" {
+			help = defaultHelp[f.name]
+		}
+		if help != "" {
+			writer.WriteString(strings.New("# HELP ", familyName, " ", escapeHelp(help), "\n").String())
+		}
+		writer.WriteString(strings.New("# TYPE ", familyName, " ", typeName(f.metricType), "\n").String())
+		switch f.metricType {
+		case HistogramType:
+			for _, h := range f.histograms {
+				writeHistogram(writer, name, h)
+			}
+		case WindowType:
+			for _, w := range f.windows {
+				writeWindow(writer, name, w)
+			}
+		case CounterType:
+			sampleName := name
+			if openMetrics {
+				sampleName = familyName + "_total"
+			}
+			for _, m := range f.metrics {
+				writeSample(writer, sampleName, m.Labels, "", "", strconv.FormatInt(m.Value, 10))
+			}
+		default:
+			for _, m := range f.metrics {
+				writeSample(writer, name, m.Labels, "", "", strconv.FormatInt(m.Value, 10))
+			}
+		}
+	}
+	if openMetrics {
+		writer.WriteString("# EOF\n")
+	}
+	return writer.Flush()
+}
+
+// families groups the registry metrics by name, sorted by name and then by labels
+func (r *MetricsRegistry) families() []*family {
+	byName := make(map[string]*family)
+	all := r.GetAllMetrics()
+	keys := make([]string, 0, len(all))
+	for key := range all {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	histograms := r.GetAllHistograms()
+	windows := r.GetAllWindows()
+	for _, key := range keys {
+		m := all[key]
+		f, ok := byName[m.Name]
+		if !ok {
+			f = &family{name: m.Name, metricType: m.Type}
+			byName[m.Name] = f
+		}
+		if m.Type == HistogramType {
+			h, ok := histograms[key]
+			if ok {
+				f.histograms = append(f.histograms, h)
+			}
+			continue
+		}
+		if m.Type == WindowType {
+			w, ok := windows[key]
+			if ok {
+				f.windows = append(f.windows, &windowSample{labels: m.Labels, window: w})
+			}
+			continue
+		}
+		f.metrics = append(f.metrics, m)
+	}
+	names := make([]string, 0, len(byName))
+	for name := range byName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	result := make([]*family, 0, len(names))
+	for _, name := range names {
+		result = append(result, byName[name])
+	}
+	return result
+}
+
+func writeHistogram(writer *bufio.Writer, name string, h *HistogramMetric) {
+	buckets := h.GetBuckets()
+	count := h.GetCount()
+	cumulative := int64(0)
+	for _, bound := range HistogramBuckets {
+		cumulative += buckets[bound]
+		writeSample(writer, name+"_bucket", h.metric.Labels, "le", strconv.FormatInt(bound, 10), strconv.FormatInt(cumulative, 10))
+	}
+	if count < cumulative {
+		count = cumulative
+	}
+	writeSample(writer, name+"_bucket", h.metric.Labels, "le", "+Inf", strconv.FormatInt(count, 10))
+	writeSample(writer, name+"_sum", h.metric.Labels, "", "", strconv.FormatInt(atomic.LoadInt64(&h.sum), 10))
+	writeSample(writer, name+"_count", h.metric.Labels, "", "", strconv.FormatInt(count, 10))
+}
+
+// writeWindow writes a sliding window histogram as a summary of its quantiles
+func writeWindow(writer *bufio.Writer, name string, w *windowSample) {
+	for _, quantile := range windowQuantiles {
+		value := w.window.Percentile(quantile * 100)
+		writeSample(writer, name, w.labels, "quantile", strconv.FormatFloat(quantile, 'f', -1, 64), strconv.FormatInt(value, 10))
+	}
+	writeSample(writer, name+"_count", w.labels, "", "", strconv.FormatInt(w.window.Count(), 10))
+}
+
+// writeSample writes a single sample line, extraName & extraValue are an additional label such as le
+func writeSample(writer *bufio.Writer, name string, labels map[string]string, extraName, extraValue, value string) {
+	writer.WriteString(name)
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	if len(keys) > 0 || extraName != "" {
+		writer.WriteString("{")
+		first := true
+		for _, key := range keys {
+			if !first {
+				writer.WriteString(",")
+			}
+			first = false
+			writer.WriteString(strings.New(sanitizeName(key, false), "=\"", escapeLabel(labels[key]), "\"").String())
+		}
+		if extraName != "" {
+			if !first {
+				writer.WriteString(",")
+			}
+			writer.WriteString(strings.New(extraName, "=\"", extraValue, "\"").String())
+		}
+		writer.WriteString("}")
+	}
+	writer.WriteString(" ")
+	writer.WriteString(value)
+	writer.WriteString("\n")
+}
+
+func typeName(metricType MetricType) string {
+	switch metricType {
+	case CounterType:
+		return "counter"
+	case GaugeType:
+		return "gauge"
+	case HistogramType:
+		return "histogram"
+	case WindowType:
+		return "summary"
+	}
+	return "unknown"
+}
+
+// sanitizeName replaces characters that are not allowed in metric names, or in label
+// names when metric is false, with an underscore
+func sanitizeName(name string, metric bool) string {
+	result := []byte(name)
+	for i, c := range result {
+		valid := c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') ||
+			(i > 0 && c >= '0' && c <= '9') || (metric && c == ':')
+		if !valid {
+			result[i] = '_'
+		}
+	}
+	if len(result) == 0 {
+		return "_"
+	}
+	return string(result)
+}
+
+var labelEscaper = stdstrings.NewReplacer("\\", "\\\\", "\"", "\\\"", "\n", "\\n")
+var helpEscaper = stdstrings.NewReplacer("\\", "\\\\", "\n", "\\n")
+
+func escapeLabel(value string) string {
+	return labelEscaper.Replace(value)
+}
+
+func escapeHelp(value string) string {
+	return helpEscaper.Replace(value)
+}
+
+// Handler returns an http handler rendering the registry for scraping. The OpenMetrics
+// format is used when the scraper accepts it, otherwise the Prometheus text format.
+func (r *MetricsRegistry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		openMetrics := stdstrings.Contains(req.Header.Get("Accept"), "application/openmetrics-text")
+		if openMetrics {
+			w.Header().Set("Content-Type", OpenMetricsContentType)
+		} else {
+			w.Header().Set("Content-Type", TextContentType)
+		}
+		err := r.WriteText(w, openMetrics)
+		if err != nil && r.logger != nil {
+			r.logger.Error("Failed to write metrics: ", err.Error())
+		}
+	})
+}
+
+// Serve exposes the registry for scraping on the given port under /metrics
+func (r *MetricsRegistry) Serve(port uint32) (*http.Server, error) {
+	listener, err := net.Listen("tcp", strings.New(":", int(port)).String())
+	if err != nil {
+		return nil, err
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", r.Handler())
+	server := &http.Server{Handler: mux}
+	go server.Serve(listener)
+	return server, nil
+}
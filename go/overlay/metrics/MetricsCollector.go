@@ -14,6 +14,7 @@
 package metrics
 
 import (
+	"sort"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -29,6 +30,7 @@ const (
 	CounterType MetricType = iota
 	GaugeType
 	HistogramType
+	WindowType
 )
 
 // Metric represents a single metric with its value and metadata
@@ -40,21 +42,44 @@ type Metric struct {
 	LastUpdated time.Time
 }
 
+// HistogramBuckets are the upper bounds of the histogram buckets
+var HistogramBuckets = []int64{1, 5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000}
+
 // MetricsRegistry manages all metrics for the system
 type MetricsRegistry struct {
-	metrics map[string]*Metric
-	mutex   sync.RWMutex
-	logger  ifs.ILogger
+	metrics    map[string]*Metric
+	histograms map[string]*HistogramMetric
+	windows    map[string]*WindowHistogram
+	help       map[string]string
+	mutex      sync.RWMutex
+	logger     ifs.ILogger
 }
 
 // NewMetricsRegistry creates a new metrics registry
 func NewMetricsRegistry(logger ifs.ILogger) *MetricsRegistry {
 	return &MetricsRegistry{
-		metrics: make(map[string]*Metric),
-		logger:  logger,
+		metrics:    make(map[string]*Metric),
+		histograms: make(map[string]*HistogramMetric),
+		windows:    make(map[string]*WindowHistogram),
+		help:       make(map[string]string),
+		logger:     logger,
 	}
 }
 
+// SetHelp sets the description of a metric, used by the exposition format
+func (r *MetricsRegistry) SetHelp(name, help string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.help[name] = help
+}
+
+// GetHelp returns the description of a metric
+func (r *MetricsRegistry) GetHelp(name string) string {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.help[name]
+}
+
 // Counter creates or updates a counter metric
 func (r *MetricsRegistry) Counter(name string, labels map[string]string) *CounterMetric {
 	r.mutex.Lock()
@@ -97,12 +122,27 @@ func (r *MetricsRegistry) Gauge(name string, labels map[string]string) *GaugeMet
 	return &GaugeMetric{metric: metric}
 }
 
+// Remove removes the metric with the given name & labels, with its histogram or window if any
+func (r *MetricsRegistry) Remove(name string, labels map[string]string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	key := r.buildKey(name, labels)
+	delete(r.metrics, key)
+	delete(r.histograms, key)
+	delete(r.windows, key)
+}
+
 // Histogram creates or updates a histogram metric
 func (r *MetricsRegistry) Histogram(name string, labels map[string]string) *HistogramMetric {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 
 	key := r.buildKey(name, labels)
+	histogram, exists := r.histograms[key]
+	if exists {
+		return histogram
+	}
 	metric, exists := r.metrics[key]
 	if !exists {
 		metric = &Metric{
@@ -115,13 +155,61 @@ func (r *MetricsRegistry) Histogram(name string, labels map[string]string) *Hist
 		r.metrics[key] = metric
 	}
 
-	return &HistogramMetric{
+	histogram = &HistogramMetric{
 		metric:    metric,
 		buckets:   make(map[int64]int64),
 		sum:       0,
 		count:     0,
 		bucketsMu: sync.RWMutex{},
 	}
+	r.histograms[key] = histogram
+	return histogram
+}
+
+// Window creates or returns a sliding window histogram over the default window
+func (r *MetricsRegistry) Window(name string, labels map[string]string) *WindowHistogram {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	key := r.buildKey(name, labels)
+	window, exists := r.windows[key]
+	if exists {
+		return window
+	}
+	r.metrics[key] = &Metric{
+		Name:        name,
+		Type:        WindowType,
+		Value:       0,
+		Labels:      labels,
+		LastUpdated: time.Now(),
+	}
+	window = NewWindowHistogram(DefaultWindow, DefaultWindowSlots)
+	r.windows[key] = window
+	return window
+}
+
+// GetAllWindows returns the sliding window histograms of the registry by their metric key
+func (r *MetricsRegistry) GetAllWindows() map[string]*WindowHistogram {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	windows := make(map[string]*WindowHistogram)
+	for key, window := range r.windows {
+		windows[key] = window
+	}
+	return windows
+}
+
+// GetAllHistograms returns the histograms of the registry by their metric key
+func (r *MetricsRegistry) GetAllHistograms() map[string]*HistogramMetric {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	histograms := make(map[string]*HistogramMetric)
+	for key, histogram := range r.histograms {
+		histograms[key] = histogram
+	}
+	return histograms
 }
 
 // GetAllMetrics returns a snapshot of all current metrics
@@ -143,11 +231,17 @@ func (r *MetricsRegistry) GetAllMetrics() map[string]*Metric {
 	return snapshot
 }
 
-// buildKey creates a unique key for a metric based on name and labels
+// buildKey creates a unique key for a metric based on name and labels,
+// labels are sorted so the same labels always produce the same key
 func (r *MetricsRegistry) buildKey(name string, labels map[string]string) string {
+	names := make([]string, 0, len(labels))
+	for k := range labels {
+		names = append(names, k)
+	}
+	sort.Strings(names)
 	key := name
-	for k, v := range labels {
-		key = strings.New(key, "_", k, "_", v).String()
+	for _, k := range names {
+		key = strings.New(key, "_", k, "_", labels[k]).String()
 	}
 	return key
 }
@@ -223,8 +317,7 @@ func (h *HistogramMetric) Observe(value int64) {
 	atomic.AddInt64(&h.count, 1)
 
 	// Update buckets (simplified bucket logic)
-	bucketBounds := []int64{1, 5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000}
-	for _, bound := range bucketBounds {
+	for _, bound := range HistogramBuckets {
 		if value <= bound {
 			h.buckets[bound]++
 			break
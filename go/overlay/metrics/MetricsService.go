@@ -0,0 +1,176 @@
+// © 2025 Sharon Aicler (saichler@gmail.com)
+//
+// Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/saichler/l8bus/go/overlay/health"
+	"github.com/saichler/l8srlz/go/serialize/object"
+	"github.com/saichler/l8types/go/ifs"
+	"github.com/saichler/l8types/go/types/l8services"
+	"github.com/saichler/l8utils/go/utils/strings"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// ServiceName is the identifier used to register and lookup the metrics service.
+const (
+	ServiceName     = "Metrics"
+	ServiceTypeName = "MetricsService"
+	ServiceArea     = byte(0)
+)
+
+// breakerSource is implemented by the vnics & vnets that keep circuit breakers
+type breakerSource interface {
+	CircuitBreakers() *CircuitBreakerManager
+}
+
+// Activate registers the metrics service with the given VNic, so other nodes can
+// query the snapshot of its registry and circuit breakers.
+func Activate(vnic ifs.IVNic) {
+	sla := ifs.NewServiceLevelAgreement(&MetricsService{}, ServiceName, ServiceArea, false, nil)
+	vnic.Resources().Services().Activate(sla, vnic)
+}
+
+// MetricsService returns a json snapshot of the node metrics, wrapped in a BytesValue,
+// on Get.
+type MetricsService struct {
+}
+
+// Activate registers the snapshot type with the registry when the service starts.
+func (this *MetricsService) Activate(sla *ifs.ServiceLevelAgreement, vnic ifs.IVNic) error {
+	vnic.Resources().Registry().Register(&wrapperspb.BytesValue{})
+	return nil
+}
+
+// DeActivate is called when the service is stopped.
+func (this *MetricsService) DeActivate() error {
+	return nil
+}
+
+func (this *MetricsService) Post(pb ifs.IElements, vnic ifs.IVNic) ifs.IElements {
+	return nil
+}
+func (this *MetricsService) Put(pb ifs.IElements, vnic ifs.IVNic) ifs.IElements {
+	return nil
+}
+func (this *MetricsService) Patch(pb ifs.IElements, vnic ifs.IVNic) ifs.IElements {
+	return nil
+}
+func (this *MetricsService) Delete(pb ifs.IElements, vnic ifs.IVNic) ifs.IElements {
+	return nil
+}
+func (this *MetricsService) GetCopy(pb ifs.IElements, vnic ifs.IVNic) ifs.IElements {
+	return nil
+}
+
+// Get returns the snapshot of the local registry and circuit breakers.
+func (this *MetricsService) Get(pb ifs.IElements, vnic ifs.IVNic) ifs.IElements {
+	data, err := json.Marshal(SnapshotOf(vnic))
+	if err != nil {
+		return object.NewError(err.Error())
+	}
+	return object.New(nil, &wrapperspb.BytesValue{Value: data})
+}
+func (this *MetricsService) Failed(pb ifs.IElements, vnic ifs.IVNic, msg *ifs.Message) ifs.IElements {
+	return nil
+}
+
+func (this *MetricsService) TransactionConfig() ifs.ITransactionConfig {
+	return nil
+}
+
+func (this *MetricsService) WebService() ifs.IWebService {
+	return nil
+}
+
+// SnapshotOf returns the snapshot of the global registry and of the vnic circuit breakers.
+func SnapshotOf(vnic ifs.IVNic) *MetricsSnapshot {
+	snapshot := GetGlobalRegistry(vnic.Resources().Logger()).Snapshot()
+	snapshot.Source = vnic.Resources().SysConfig().LocalUuid
+	snapshot.Alias = vnic.Resources().SysConfig().LocalAlias
+	source, ok := vnic.(breakerSource)
+	if ok {
+		snapshot.AddCircuitBreakers(source.CircuitBreakers())
+	}
+	return snapshot
+}
+
+// SnapshotFrom decodes the response of the Metrics service.
+func SnapshotFrom(resp ifs.IElements) (*MetricsSnapshot, error) {
+	if resp == nil {
+		return nil, errors.New(strings.New("No response from ", ServiceName, " service").String())
+	}
+	if resp.Error() != nil {
+		return nil, resp.Error()
+	}
+	data, ok := resp.Element().(*wrapperspb.BytesValue)
+	if !ok {
+		return nil, errors.New(strings.New("Unexpected ", ServiceName, " response type").String())
+	}
+	snapshot := &MetricsSnapshot{}
+	err := json.Unmarshal(data.Value, snapshot)
+	if err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// multicastRequester is implemented by the vnics that gather the responses of a multicast request
+type multicastRequester interface {
+	MulticastRequest(serviceName string, serviceArea byte, action ifs.Action, any interface{}, expected, timeoutInSeconds int) []ifs.IElements
+}
+
+// Collect sends a single multicast request to the Metrics service of the overlay and merges
+// the snapshots of the nodes answering it with the local one. It waits for as many answers
+// as the nodes the health service knows to run the service, those failing to answer within
+// the timeout are left out.
+func Collect(vnic ifs.IVNic, timeout int) *MetricsSnapshot {
+	local := vnic.Resources().SysConfig().LocalUuid
+	snapshots := []*MetricsSnapshot{SnapshotOf(vnic)}
+	requester, ok := vnic.(multicastRequester)
+	if !ok {
+		vnic.Resources().Logger().Warning("Collecting metrics from ", local, " only, its vnic cannot multicast requests")
+		return MergeSnapshots(snapshots...)
+	}
+	expected := 0
+	for uuid, hp := range health.All(vnic.Resources()) {
+		if uuid != local && runsMetrics(hp.Services) {
+			expected++
+		}
+	}
+	responses := requester.MulticastRequest(ServiceName, ServiceArea, ifs.GET, &wrapperspb.BytesValue{}, expected, timeout)
+	for _, resp := range responses {
+		snapshot, err := SnapshotFrom(resp)
+		if err != nil {
+			vnic.Resources().Logger().Warning("Failed to collect metrics: ", err.Error())
+			continue
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	if len(responses) < expected {
+		vnic.Resources().Logger().Warning("Collected metrics from ", len(responses), " of ", expected, " nodes")
+	}
+	return MergeSnapshots(snapshots...)
+}
+
+// runsMetrics returns true if the node services include the metrics service
+func runsMetrics(services *l8services.L8Services) bool {
+	if services == nil || services.ServiceToAreas == nil {
+		return false
+	}
+	areas, ok := services.ServiceToAreas[ServiceName]
+	return ok && areas != nil && areas.Areas[int32(ServiceArea)]
+}
@@ -0,0 +1,203 @@
+// © 2025 Sharon Aicler (saichler@gmail.com)
+//
+// Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"sort"
+	"time"
+)
+
+// Labels added to every sample when snapshots of several nodes are merged
+const (
+	NodeLabel  = "node"
+	AliasLabel = "alias"
+)
+
+// MetricsSnapshot is a point in time copy of a registry and of the circuit breakers of
+// a node, it is what the Metrics service returns so it can travel over the bus.
+type MetricsSnapshot struct {
+	Source          string
+	Alias           string
+	Time            int64
+	Metrics         []*Metric
+	Histograms      []*HistogramSnapshot
+	Windows         []*WindowSnapshot
+	CircuitBreakers []*BreakerSnapshot
+}
+
+// HistogramSnapshot is a copy of a histogram, buckets are not cumulative
+type HistogramSnapshot struct {
+	Name    string
+	Labels  map[string]string
+	Buckets map[int64]int64
+	Sum     int64
+	Count   int64
+}
+
+// WindowSnapshot is the percentiles of a sliding window histogram
+type WindowSnapshot struct {
+	Name        string
+	Labels      map[string]string
+	Percentiles LatencyPercentiles
+}
+
+// BreakerSnapshot is the statistics of a circuit breaker, Node is set when merged
+type BreakerSnapshot struct {
+	Node string
+	CircuitBreakerStats
+}
+
+// Snapshot copies the registry, counters & gauges go to Metrics while histograms and
+// windows go to their own lists. Entries are sorted by their metric key.
+func (r *MetricsRegistry) Snapshot() *MetricsSnapshot {
+	s := &MetricsSnapshot{Time: time.Now().Unix()}
+	all := r.GetAllMetrics()
+	keys := make([]string, 0, len(all))
+	for key := range all {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	histograms := r.GetAllHistograms()
+	windows := r.GetAllWindows()
+	for _, key := range keys {
+		m := all[key]
+		switch m.Type {
+		case HistogramType:
+			h, ok := histograms[key]
+			if ok {
+				s.Histograms = append(s.Histograms, &HistogramSnapshot{
+					Name:    m.Name,
+					Labels:  copyLabels(m.Labels),
+					Buckets: h.GetBuckets(),
+					Sum:     h.GetSum(),
+					Count:   h.GetCount(),
+				})
+			}
+		case WindowType:
+			w, ok := windows[key]
+			if ok {
+				s.Windows = append(s.Windows, &WindowSnapshot{
+					Name:        m.Name,
+					Labels:      copyLabels(m.Labels),
+					Percentiles: w.Percentiles(),
+				})
+			}
+		default:
+			m.Labels = copyLabels(m.Labels)
+			s.Metrics = append(s.Metrics, m)
+		}
+	}
+	return s
+}
+
+// AddCircuitBreakers adds the statistics of the manager's circuit breakers, sorted by name
+func (s *MetricsSnapshot) AddCircuitBreakers(manager *CircuitBreakerManager) {
+	if manager == nil {
+		return
+	}
+	all := manager.GetAll()
+	names := make([]string, 0, len(all))
+	for name := range all {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		s.CircuitBreakers = append(s.CircuitBreakers, &BreakerSnapshot{CircuitBreakerStats: all[name].GetStats()})
+	}
+}
+
+// MergeSnapshots merges the snapshots of several nodes into one. Samples of different
+// nodes are kept apart by the node label, set to the uuid of the node, as percentiles
+// cannot be added up. The alias label is set as well when the node has an alias.
+func MergeSnapshots(snapshots ...*MetricsSnapshot) *MetricsSnapshot {
+	merged := &MetricsSnapshot{Time: time.Now().Unix()}
+	for _, s := range snapshots {
+		if s == nil {
+			continue
+		}
+		node := s.Source
+		for _, m := range s.Metrics {
+			copied := *m
+			copied.Labels = withNode(m.Labels, node, s.Alias)
+			merged.Metrics = append(merged.Metrics, &copied)
+		}
+		for _, h := range s.Histograms {
+			copied := *h
+			copied.Labels = withNode(h.Labels, node, s.Alias)
+			merged.Histograms = append(merged.Histograms, &copied)
+		}
+		for _, w := range s.Windows {
+			copied := *w
+			copied.Labels = withNode(w.Labels, node, s.Alias)
+			merged.Windows = append(merged.Windows, &copied)
+		}
+		for _, b := range s.CircuitBreakers {
+			copied := *b
+			copied.Node = node
+			merged.CircuitBreakers = append(merged.CircuitBreakers, &copied)
+		}
+	}
+	return merged
+}
+
+// Find returns the value of the counter or gauge with the given name & labels
+func (s *MetricsSnapshot) Find(name string, labels map[string]string) (int64, bool) {
+	for _, m := range s.Metrics {
+		if m.Name == name && labelsMatch(m.Labels, labels) {
+			return m.Value, true
+		}
+	}
+	return 0, false
+}
+
+// Sum returns the sum of the counters or gauges with the given name across all labels,
+// e.g. the total messages sent by the whole overlay in a merged snapshot
+func (s *MetricsSnapshot) Sum(name string) int64 {
+	sum := int64(0)
+	for _, m := range s.Metrics {
+		if m.Name == name {
+			sum += m.Value
+		}
+	}
+	return sum
+}
+
+func labelsMatch(labels, expected map[string]string) bool {
+	if len(labels) != len(expected) {
+		return false
+	}
+	for k, v := range expected {
+		if labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+func copyLabels(labels map[string]string) map[string]string {
+	result := make(map[string]string, len(labels))
+	for k, v := range labels {
+		result[k] = v
+	}
+	return result
+}
+
+func withNode(labels map[string]string, node, alias string) map[string]string {
+	result := copyLabels(labels)
+	result[NodeLabel] = node
+	if alias != "" {
+		result[AliasLabel] = alias
+	}
+	return result
+}
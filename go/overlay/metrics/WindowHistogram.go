@@ -0,0 +1,203 @@
+// © 2025 Sharon Aicler (saichler@gmail.com)
+//
+// Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Default sliding window of latency histograms
+const (
+	DefaultWindow      = 60 * time.Second
+	DefaultWindowSlots = 12
+)
+
+// windowBucketGrowth is the ratio between bucket bounds, values are reported with
+// up to 5% relative error, similar to an HDR histogram with 2 significant digits
+const windowBucketGrowth = 1.05
+
+var windowBucketLog = math.Log(windowBucketGrowth)
+
+// LatencyPercentiles are the percentiles of a sliding window
+type LatencyPercentiles struct {
+	P50   int64
+	P90   int64
+	P99   int64
+	P999  int64
+	Count int64
+}
+
+// WindowHistogram is a histogram of the observations made over the last window.
+// The window is divided into slots, the oldest slot is dropped as time moves, so
+// the percentiles reflect only the recent observations.
+type WindowHistogram struct {
+	mutex    sync.Mutex
+	slotSize int64
+	slots    []*windowSlot
+	version  int64
+	cache    *windowSnapshot
+}
+
+type windowSlot struct {
+	number int64
+	counts map[int]int64
+	count  int64
+}
+
+// windowSnapshot is the merged buckets of the window, cached until the next
+// observation or until the window slides
+type windowSnapshot struct {
+	slot    int64
+	version int64
+	buckets []int
+	counts  []int64
+	count   int64
+}
+
+// NewWindowHistogram creates a histogram over the given window divided into slots
+func NewWindowHistogram(window time.Duration, slots int) *WindowHistogram {
+	if slots <= 0 {
+		slots = DefaultWindowSlots
+	}
+	if window < time.Duration(slots) {
+		window = DefaultWindow
+	}
+	w := &WindowHistogram{slotSize: int64(window) / int64(slots)}
+	w.slots = make([]*windowSlot, slots)
+	for i := range w.slots {
+		w.slots[i] = &windowSlot{number: -1, counts: make(map[int]int64)}
+	}
+	return w
+}
+
+// Observe records a new observation in the current slot
+func (w *WindowHistogram) Observe(value int64) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	slot := w.slotFor(time.Now().UnixNano())
+	slot.counts[bucketOf(value)]++
+	slot.count++
+	w.version++
+}
+
+// Percentile returns the value below which the given percent of the observations
+// in the window fall, e.g. 99 for the p99, or 0 if there are no observations
+func (w *WindowHistogram) Percentile(percent float64) int64 {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return w.snapshot().percentile(percent)
+}
+
+// Percentiles returns the p50, p90, p99 & p999 of the window
+func (w *WindowHistogram) Percentiles() LatencyPercentiles {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	s := w.snapshot()
+	return LatencyPercentiles{
+		P50:   s.percentile(50),
+		P90:   s.percentile(90),
+		P99:   s.percentile(99),
+		P999:  s.percentile(99.9),
+		Count: s.count,
+	}
+}
+
+// Count returns the number of observations in the window
+func (w *WindowHistogram) Count() int64 {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return w.snapshot().count
+}
+
+// slotFor returns the slot of the given time, resetting it if it holds an older slot
+func (w *WindowHistogram) slotFor(now int64) *windowSlot {
+	number := now / w.slotSize
+	slot := w.slots[number%int64(len(w.slots))]
+	if slot.number != number {
+		slot.number = number
+		slot.counts = make(map[int]int64)
+		slot.count = 0
+	}
+	return slot
+}
+
+// snapshot merges the slots of the window, the caller holds the mutex
+func (w *WindowHistogram) snapshot() *windowSnapshot {
+	current := time.Now().UnixNano() / w.slotSize
+	if w.cache != nil && w.cache.slot == current && w.cache.version == w.version {
+		return w.cache
+	}
+	oldest := current - int64(len(w.slots)) + 1
+	merged := make(map[int]int64)
+	s := &windowSnapshot{slot: current, version: w.version}
+	for _, slot := range w.slots {
+		if slot.number < oldest || slot.number > current {
+			continue
+		}
+		for bucket, count := range slot.counts {
+			merged[bucket] += count
+		}
+		s.count += slot.count
+	}
+	s.buckets = make([]int, 0, len(merged))
+	for bucket := range merged {
+		s.buckets = append(s.buckets, bucket)
+	}
+	sort.Ints(s.buckets)
+	s.counts = make([]int64, len(s.buckets))
+	for i, bucket := range s.buckets {
+		s.counts[i] = merged[bucket]
+	}
+	w.cache = s
+	return s
+}
+
+func (s *windowSnapshot) percentile(percent float64) int64 {
+	if s.count == 0 {
+		return 0
+	}
+	rank := int64(math.Ceil(float64(s.count) * percent / 100))
+	if rank < 1 {
+		rank = 1
+	}
+	cumulative := int64(0)
+	for i, bucket := range s.buckets {
+		cumulative += s.counts[i]
+		if cumulative >= rank {
+			return boundOf(bucket)
+		}
+	}
+	return boundOf(s.buckets[len(s.buckets)-1])
+}
+
+// bucketOf returns the bucket of a value, bucket 0 holds the values up to 1
+func bucketOf(value int64) int {
+	if value <= 1 {
+		return 0
+	}
+	return int(math.Ceil(math.Log(float64(value)) / windowBucketLog))
+}
+
+// boundOf returns the upper bound of a bucket
+func boundOf(bucket int) int64 {
+	if bucket == 0 {
+		return 1
+	}
+	return int64(math.Ceil(math.Pow(windowBucketGrowth, float64(bucket))))
+}
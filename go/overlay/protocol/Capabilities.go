@@ -0,0 +1,144 @@
+// © 2025 Sharon Aicler (saichler@gmail.com)
+//
+// Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package protocol
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/saichler/l8utils/go/utils/strings"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// Version is the protocol version of this build, peers that never sent a hello are
+// treated as version 0 with no capabilities.
+const Version = 1
+
+// Capability is an optional protocol feature, it is used on a connection only when both
+// sides announced it in their hello.
+type Capability string
+
+// CapExtensions is the extensions trailer itself, a peer that did not agree on it gets
+// no trailer at all. CapTracing, CapChecksum & CapExpiry are the trace context, the frame
+// checksum and the expiry in the trailer, the expiry is a wall clock time so it is sent
+// only to peers that agreed to honor it.
+const (
+	CapExtensions Capability = "extensions"
+	CapTracing    Capability = "tracing"
+	CapChecksum   Capability = "checksum"
+	CapExpiry     Capability = "expiry"
+)
+
+// DefaultCapabilities returns the capabilities a node announces unless configured
+// otherwise, the checksum is left out as it copies every frame.
+func DefaultCapabilities() []Capability {
+	return []Capability{CapExtensions, CapTracing, CapExpiry}
+}
+
+// WithCapability returns a copy of the capabilities with the capability added if enabled
+// or removed otherwise.
+func WithCapability(capabilities []Capability, capability Capability, enabled bool) []Capability {
+	result := make([]Capability, 0, len(capabilities)+1)
+	for _, c := range capabilities {
+		if c != capability {
+			result = append(result, c)
+		}
+	}
+	if enabled {
+		result = append(result, capability)
+	}
+	return result
+}
+
+// HelloServiceName is the service the hello is exchanged with after the connection is
+// validated, a peer that does not run it logs the unknown service and keeps working.
+const (
+	HelloServiceName = "L8Hello"
+	HelloServiceArea = byte(0)
+)
+
+// Hello announces the protocol version and capabilities of a node, Reply is set on the
+// answer so it is not answered again.
+type Hello struct {
+	Uuid         string       `json:"uuid"`
+	Version      int          `json:"version"`
+	Capabilities []Capability `json:"capabilities,omitempty"`
+	Reply        bool         `json:"reply,omitempty"`
+}
+
+// NewHello returns the hello of this build for the node uuid and its capabilities
+func NewHello(uuid string, reply bool, capabilities []Capability) *Hello {
+	return &Hello{Uuid: uuid, Version: Version, Capabilities: capabilities, Reply: reply}
+}
+
+// ToBytes wraps the hello json in a BytesValue to travel over the bus
+func (this *Hello) ToBytes() (*wrapperspb.BytesValue, error) {
+	data, err := json.Marshal(this)
+	if err != nil {
+		return nil, err
+	}
+	return &wrapperspb.BytesValue{Value: data}, nil
+}
+
+// HelloOf decodes a hello sent over the bus
+func HelloOf(any interface{}) (*Hello, error) {
+	data, ok := any.(*wrapperspb.BytesValue)
+	if !ok {
+		return nil, errors.New(strings.New("Unexpected hello type ", any).String())
+	}
+	hello := &Hello{}
+	err := json.Unmarshal(data.Value, hello)
+	if err != nil {
+		return nil, err
+	}
+	return hello, nil
+}
+
+// Peer is what was negotiated with the other side of a connection, Version is the lower
+// of both versions and Agreed the capabilities both sides support.
+type Peer struct {
+	Version      int
+	Capabilities []Capability
+	Agreed       []Capability
+}
+
+// Negotiate returns the peer of a received hello against the local capabilities
+func Negotiate(hello *Hello, local []Capability) *Peer {
+	peer := &Peer{Version: hello.Version, Capabilities: hello.Capabilities, Agreed: make([]Capability, 0)}
+	if peer.Version > Version {
+		peer.Version = Version
+	}
+	for _, capability := range local {
+		for _, remote := range hello.Capabilities {
+			if capability == remote {
+				peer.Agreed = append(peer.Agreed, capability)
+				break
+			}
+		}
+	}
+	return peer
+}
+
+// Supports returns true if both sides agreed on the capability, a nil peer supports nothing
+func (this *Peer) Supports(capability Capability) bool {
+	if this == nil {
+		return false
+	}
+	for _, agreed := range this.Agreed {
+		if agreed == capability {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,57 @@
+// © 2025 Sharon Aicler (saichler@gmail.com)
+//
+// Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package protocol
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+)
+
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// ErrChecksumMismatch is returned for a frame that does not match its checksum
+var ErrChecksumMismatch = errors.New("Frame checksum mismatch")
+
+// AppendChecksum returns a copy of the frame with the CRC32C of the message and its other
+// extensions in the Ext_Checksum extension.
+func AppendChecksum(data []byte) []byte {
+	msg, ext := SplitExtensions(data)
+	if ext == nil {
+		ext = make(Extensions)
+	}
+	delete(ext, Ext_Checksum)
+	sum := crc32.Checksum(AppendExtensions(msg, ext), castagnoli)
+	ext[Ext_Checksum] = binary.BigEndian.AppendUint32(nil, sum)
+	return AppendExtensions(msg, ext)
+}
+
+// VerifyChecksum checks the frame against its checksum and returns it without the
+// checksum, present is false if the frame carries no checksum.
+func VerifyChecksum(data []byte) (frame []byte, present bool, err error) {
+	msg, ext := SplitExtensions(data)
+	value, ok := ext[Ext_Checksum]
+	if !ok {
+		return data, false, nil
+	}
+	if len(value) != 4 {
+		return nil, true, ErrChecksumMismatch
+	}
+	delete(ext, Ext_Checksum)
+	frame = AppendExtensions(msg, ext)
+	if crc32.Checksum(frame, castagnoli) != binary.BigEndian.Uint32(value) {
+		return nil, true, ErrChecksumMismatch
+	}
+	return frame, true, nil
+}
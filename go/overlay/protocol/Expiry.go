@@ -0,0 +1,37 @@
+// © 2025 Sharon Aicler (saichler@gmail.com)
+//
+// Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package protocol
+
+import (
+	"encoding/binary"
+	"time"
+)
+
+// ExpiredMessage is the failure of a request that expired before it was delivered
+const ExpiredMessage = "Message expired before it was delivered"
+
+// ExpiryOf returns the expiry of the message bytes, false if it has none
+func ExpiryOf(data []byte) (time.Time, bool) {
+	value, ok := ExtensionOf(data, Ext_Expiry)
+	if !ok || len(value) != 8 {
+		return time.Time{}, false
+	}
+	return time.UnixMilli(int64(binary.BigEndian.Uint64(value))), true
+}
+
+// Expired returns true if the message bytes have an expiry that has passed
+func Expired(data []byte) bool {
+	expiry, ok := ExpiryOf(data)
+	return ok && time.Now().After(expiry)
+}
@@ -0,0 +1,142 @@
+// © 2025 Sharon Aicler (saichler@gmail.com)
+//
+// Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package protocol
+
+import (
+	"encoding/binary"
+)
+
+// Extensions are optional fields carried in a trailer after the marshaled message, so
+// they travel with the message bytes through the VNets without changing the message
+// format. The trailer is the extensions as type, length & value, followed by the size
+// of the extensions and a magic number.
+//
+//	| type (1) | length (2) | value | ... | size (4) | magic (4) |
+const ExtensionsMagic uint32 = 0x4C384558
+
+// Extension types, Ext_Probe is the remaining hop limit of a diagnostics probe,
+// Ext_Checksum is the CRC32C of the frame and Ext_Expiry the time, in unix milliseconds,
+// after which the message is dropped
+const (
+	Ext_Trace    byte = 1
+	Ext_Probe    byte = 2
+	Ext_Checksum byte = 3
+	Ext_Expiry   byte = 4
+)
+
+const extensionsFooterSize = 8
+const extensionHeaderSize = 3
+
+// Extensions are the extension values by their type
+type Extensions map[byte][]byte
+
+// SplitExtensions separates the message bytes from the extensions trailer, if there is
+// no valid trailer the data is returned as is with no extensions.
+func SplitExtensions(data []byte) ([]byte, Extensions) {
+	size, ok := trailerSize(data)
+	if !ok {
+		return data, nil
+	}
+	end := len(data) - extensionsFooterSize
+	start := end - size
+	ext := make(Extensions)
+	for i := start; i < end; {
+		t := data[i]
+		l := int(binary.BigEndian.Uint16(data[i+1:]))
+		i += extensionHeaderSize
+		ext[t] = data[i : i+l]
+		i += l
+	}
+	return data[:start], ext
+}
+
+// ExtensionOf returns the value of an extension of the message bytes
+func ExtensionOf(data []byte, extType byte) ([]byte, bool) {
+	_, ext := SplitExtensions(data)
+	value, ok := ext[extType]
+	return value, ok
+}
+
+// WithExtension returns a copy of the message bytes with the extension set to the value,
+// keeping the other extensions of the trailer.
+func WithExtension(data []byte, extType byte, value []byte) []byte {
+	msg, ext := SplitExtensions(data)
+	if ext == nil {
+		ext = make(Extensions)
+	}
+	ext[extType] = value
+	return AppendExtensions(msg, ext)
+}
+
+// AppendExtensions returns a copy of the message bytes followed by the extensions trailer,
+// extensions are written by ascending type.
+func AppendExtensions(msg []byte, ext Extensions) []byte {
+	size := 0
+	for _, value := range ext {
+		size += extensionHeaderSize + len(value)
+	}
+	if size == 0 {
+		return msg
+	}
+	result := make([]byte, len(msg), len(msg)+size+extensionsFooterSize)
+	copy(result, msg)
+	for t := 0; t < 256; t++ {
+		value, ok := ext[byte(t)]
+		if !ok {
+			continue
+		}
+		result = append(result, byte(t))
+		result = binary.BigEndian.AppendUint16(result, uint16(len(value)))
+		result = append(result, value...)
+	}
+	result = binary.BigEndian.AppendUint32(result, uint32(size))
+	result = binary.BigEndian.AppendUint32(result, ExtensionsMagic)
+	return result
+}
+
+// trailerSize validates the trailer of the data and returns the size of its extensions
+func trailerSize(data []byte) (int, bool) {
+	if len(data) < extensionsFooterSize {
+		return 0, false
+	}
+	end := len(data) - extensionsFooterSize
+	if binary.BigEndian.Uint32(data[end+4:]) != ExtensionsMagic {
+		return 0, false
+	}
+	size := int(binary.BigEndian.Uint32(data[end:]))
+	if size <= 0 || size > end {
+		return 0, false
+	}
+	for i := end - size; i < end; {
+		if i+extensionHeaderSize > end {
+			return 0, false
+		}
+		i += extensionHeaderSize + int(binary.BigEndian.Uint16(data[i+1:]))
+		if i > end {
+			return 0, false
+		}
+	}
+	return size, true
+}
+
+// WithoutExtension returns the message bytes without the extension, the data is returned
+// as is if it does not carry it.
+func WithoutExtension(data []byte, extType byte) []byte {
+	msg, ext := SplitExtensions(data)
+	if _, ok := ext[extType]; !ok {
+		return data
+	}
+	delete(ext, extType)
+	return AppendExtensions(msg, ext)
+}
@@ -0,0 +1,67 @@
+// © 2025 Sharon Aicler (saichler@gmail.com)
+//
+// Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package protocol
+
+import (
+	"errors"
+	stdstrings "strings"
+
+	"github.com/saichler/l8utils/go/utils/strings"
+)
+
+// Failures of the overlay itself, as opposed to the errors of service handlers. A failure
+// reaches the sender as the text of a failed message, sentinel text first.
+var (
+	ErrNoProvider    = errors.New("No provider for service")
+	ErrNoDestination = errors.New("Cannot find destination port")
+	ErrSendFailed    = errors.New("Error sending data")
+	ErrExpired       = errors.New(ExpiredMessage)
+)
+
+var failures = []error{ErrNoProvider, ErrNoDestination, ErrSendFailed, ErrExpired}
+
+// failure is a sentinel failure with its detail
+type failure struct {
+	sentinel error
+	text     string
+}
+
+func (this *failure) Error() string {
+	return this.text
+}
+
+func (this *failure) Unwrap() error {
+	return this.sentinel
+}
+
+// Failure returns the sentinel failure with the given detail, errors.Is finds the sentinel.
+func Failure(sentinel error, detail ...interface{}) error {
+	text := strings.New(append([]interface{}{sentinel.Error(), ": "}, detail...)...).String()
+	return &failure{sentinel: sentinel, text: text}
+}
+
+// FailureOf returns the sentinel of an overlay failure, either wrapped by the error or
+// received as the text of a failed message, and nil for any other error.
+func FailureOf(err error) error {
+	if err == nil {
+		return nil
+	}
+	for _, sentinel := range failures {
+		if errors.Is(err, sentinel) || err.Error() == sentinel.Error() ||
+			stdstrings.HasPrefix(err.Error(), sentinel.Error()+": ") {
+			return sentinel
+		}
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+// © 2025 Sharon Aicler (saichler@gmail.com)
+//
+// Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package protocol
+
+import (
+	"encoding/binary"
+	"time"
+
+	"github.com/saichler/l8types/go/ifs"
+)
+
+// MessageOptions are the header, transaction & extension fields of a message to create.
+// NewMessage sets the defaults, the With methods return the options so they chain:
+//
+//	opts := protocol.NewMessage("MyService", 0, ifs.POST).To(uuid).WithMode(ifs.M_Leader)
+type MessageOptions struct {
+	Destination   string
+	ServiceName   string
+	ServiceArea   byte
+	Action        ifs.Action
+	Priority      ifs.Priority
+	MulticastMode ifs.MulticastMode
+	// Source & Vnet are set by the sender when empty
+	Source   string
+	Vnet     string
+	Request  bool
+	Reply    bool
+	Sequence uint32
+	// Token is the AAA id of the message
+	Token       string
+	Transaction *Transaction
+	// Timeout of a request in seconds, carried as the transaction timeout
+	Timeout    int64
+	Extensions Extensions
+}
+
+// Transaction is the transaction state of a message, times are -1 when not set.
+type Transaction struct {
+	State     ifs.TransactionState
+	Id        string
+	ErrMsg    string
+	Created   int64
+	Queued    int64
+	Running   int64
+	End       int64
+	Timeout   int64
+	Replica   byte
+	IsReplica bool
+}
+
+// NewMessage returns the options of a message to the service, multicast to all its
+// instances with the default priority and no transaction.
+func NewMessage(serviceName string, serviceArea byte, action ifs.Action) *MessageOptions {
+	return &MessageOptions{ServiceName: serviceName, ServiceArea: serviceArea, Action: action,
+		Priority: ifs.P8, MulticastMode: ifs.M_All, Timeout: -1}
+}
+
+// To sets the destination uuid
+func (this *MessageOptions) To(destination string) *MessageOptions {
+	this.Destination = destination
+	return this
+}
+
+// From sets the source uuid and the uuid of its VNet
+func (this *MessageOptions) From(source, vnet string) *MessageOptions {
+	this.Source = source
+	this.Vnet = vnet
+	return this
+}
+
+// WithPriority sets the priority
+func (this *MessageOptions) WithPriority(priority ifs.Priority) *MessageOptions {
+	this.Priority = priority
+	return this
+}
+
+// WithMode sets the multicast mode choosing the service instances
+func (this *MessageOptions) WithMode(mode ifs.MulticastMode) *MessageOptions {
+	this.MulticastMode = mode
+	return this
+}
+
+// WithSequence sets the message number
+func (this *MessageOptions) WithSequence(sequence uint32) *MessageOptions {
+	this.Sequence = sequence
+	return this
+}
+
+// AsRequest marks the message as a request waiting for a reply, with its timeout in seconds
+func (this *MessageOptions) AsRequest(timeout int64) *MessageOptions {
+	this.Request = true
+	this.Timeout = timeout
+	return this
+}
+
+// AsReply marks the message as a reply
+func (this *MessageOptions) AsReply() *MessageOptions {
+	this.Reply = true
+	return this
+}
+
+// WithToken sets the AAA id of the message
+func (this *MessageOptions) WithToken(token string) *MessageOptions {
+	this.Token = token
+	return this
+}
+
+// WithTransaction sets the transaction state
+func (this *MessageOptions) WithTransaction(transaction *Transaction) *MessageOptions {
+	this.Transaction = transaction
+	return this
+}
+
+// WithExtension sets an extension of the message trailer
+func (this *MessageOptions) WithExtension(extType byte, value []byte) *MessageOptions {
+	if this.Extensions == nil {
+		this.Extensions = make(Extensions)
+	}
+	this.Extensions[extType] = value
+	return this
+}
+
+// WithExpiry sets the time after which the message is dropped by the queues it waits in
+func (this *MessageOptions) WithExpiry(expiry time.Time) *MessageOptions {
+	return this.WithExtension(Ext_Expiry, binary.BigEndian.AppendUint64(nil, uint64(expiry.UnixMilli())))
+}
+
+// WithTTL sets the expiry of the message to the time to live from now
+func (this *MessageOptions) WithTTL(ttl time.Duration) *MessageOptions {
+	return this.WithExpiry(time.Now().Add(ttl))
+}
+
+// TransactionOf returns the transaction state of a message, nil if it is not a transaction
+func TransactionOf(msg *ifs.Message) *Transaction {
+	if msg.Tr_State() == ifs.NotATransaction {
+		return nil
+	}
+	return &Transaction{State: msg.Tr_State(), Id: msg.Tr_Id(), ErrMsg: msg.Tr_ErrMsg(),
+		Created: msg.Tr_Created(), Queued: msg.Tr_Queued(), Running: msg.Tr_Running(), End: msg.Tr_End(),
+		Timeout: msg.Tr_Timeout(), Replica: msg.Tr_Replica(), IsReplica: msg.Tr_IsReplica()}
+}
+
+// transactionFields returns the transaction fields of the message header, a message that
+// is not a transaction carries the request timeout as its transaction timeout.
+func (this *MessageOptions) transactionFields() *Transaction {
+	if this.Transaction != nil {
+		return this.Transaction
+	}
+	return &Transaction{State: ifs.NotATransaction, Created: -1, Queued: -1, Running: -1, End: -1, Timeout: this.Timeout}
+}
@@ -0,0 +1,136 @@
+// © 2025 Sharon Aicler (saichler@gmail.com)
+//
+// Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package protocol
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/saichler/l8srlz/go/serialize/object"
+	"github.com/saichler/l8types/go/ifs"
+	"github.com/saichler/l8types/go/types/l8api"
+	"github.com/saichler/l8utils/go/utils/strings"
+	"google.golang.org/protobuf/proto"
+)
+
+// Header is the routing header of a frame
+type Header struct {
+	Source        string
+	SourceVnet    string
+	Destination   string
+	ServiceName   string
+	ServiceArea   byte
+	Priority      ifs.Priority
+	MulticastMode ifs.MulticastMode
+}
+
+// HeaderOf returns the routing header of a frame. The header layout belongs to
+// ifs.HeaderOf, which reads past the end of a short frame, so a frame too short or
+// malformed to hold a header is recovered here as an error.
+func HeaderOf(data []byte) (header *Header, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			header, err = nil, malformed("header", r)
+		}
+	}()
+	header = &Header{}
+	header.Source, header.SourceVnet, header.Destination, header.ServiceName, header.ServiceArea,
+		header.Priority, header.MulticastMode = ifs.HeaderOf(data)
+	return header, nil
+}
+
+// MessageOf is a standalone function to unmarshal the message of a frame using the
+// provided resources, a frame too short or malformed to hold a message is an error.
+func MessageOf(data []byte, resources ifs.IResources) (msg *ifs.Message, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			msg, err = nil, malformed("message", r)
+		}
+	}()
+	data, _ = SplitExtensions(data)
+	if len(data) == 0 {
+		return nil, malformed("message", "empty frame")
+	}
+	msg = &ifs.Message{}
+	_, err = msg.Unmarshal(data, resources)
+	if err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// ElementsFor creates the payload elements of a message from a query, elements, a
+// protobuf or a slice of either, anything else is an error.
+func ElementsFor(any interface{}, resources ifs.IResources) (ifs.IElements, error) {
+	if any == nil {
+		return object.New(nil, nil), nil
+	}
+	pq, ok := any.(*l8api.L8Query)
+	if ok {
+		return object.NewQuery(pq.Text, resources)
+	}
+
+	gsql, ok := any.(string)
+	if ok {
+		return object.NewQuery(gsql, resources)
+	}
+
+	elems, ok := any.(ifs.IElements)
+	if ok {
+		return elems, nil
+	}
+
+	pb, ok := any.(proto.Message)
+	if ok {
+		return object.New(nil, pb), nil
+	}
+
+	v := reflect.ValueOf(any)
+
+	if v.Kind() == reflect.Slice {
+		pbs := make([]proto.Message, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			elm := v.Index(i)
+			elements, ok := elm.Interface().(ifs.IElements)
+			if ok {
+				for _, epb := range elements.Elements() {
+					pb, ok = epb.(proto.Message)
+					if !ok {
+						return nil, unknownInput(epb)
+					}
+					pbs[i] = pb
+				}
+			} else {
+				pb, ok = elm.Interface().(proto.Message)
+				if !ok {
+					return nil, unknownInput(elm.Interface())
+				}
+				pbs[i] = pb
+			}
+		}
+		return object.New(nil, pbs), nil
+	}
+	return nil, unknownInput(any)
+}
+
+func unknownInput(any interface{}) error {
+	return errors.New(strings.New("Uknown input type ", reflect.ValueOf(any).String()).String())
+}
+
+// malformed is the error of a panic recovered while parsing the bytes of a frame, the
+// parsing of ifs & l8srlz does not check the lengths it reads
+func malformed(what string, r interface{}) error {
+	return errors.New(strings.New("Malformed ", what, ": ", fmt.Sprint(r)).String())
+}
@@ -28,20 +28,26 @@ var Discovery_Enabled = true
 type Protocol struct {
 	sequence atomic.Uint32
 	vnic     ifs.IVNic
+	stats    *MessageStatistics
 }
 
 // New creates a new Protocol instance with the given resources.
 func New(vnic ifs.IVNic) *Protocol {
 	p := &Protocol{}
 	p.vnic = vnic
+	p.stats = NewMessageStatistics(MsgLog)
 	return p
 }
 
-// MessageOf deserializes raw bytes into a Message struct.
+// Statistics returns the statistics of the messages created & handled with this protocol.
+func (this *Protocol) Statistics() *MessageStatistics {
+	return this.stats
+}
+
+// MessageOf deserializes raw bytes into a Message struct, the extensions trailer
+// is not part of the message.
 func (this *Protocol) MessageOf(data []byte) (*ifs.Message, error) {
-	msg := &ifs.Message{}
-	_, err := msg.Unmarshal(data, this.vnic.Resources())
-	return msg, err
+	return MessageOf(data, this.vnic.Resources())
 }
 
 // ElementsOf extracts the payload elements from a message.
@@ -50,10 +56,15 @@ func (this *Protocol) ElementsOf(msg *ifs.Message) (ifs.IElements, error) {
 }
 
 // ElementsOf is a standalone function to extract payload elements from a message
-// using the provided resources for deserialization.
-func ElementsOf(msg *ifs.Message, resourcs ifs.IResources) (ifs.IElements, error) {
+// using the provided resources for deserialization, a malformed payload is an error.
+func ElementsOf(msg *ifs.Message, resourcs ifs.IResources) (elems ifs.IElements, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			elems, err = nil, malformed("payload", r)
+		}
+	}()
 	result := &object.Elements{}
-	err := result.Deserialize(msg.Data(), resourcs.Registry())
+	err = result.Deserialize(msg.Data(), resourcs.Registry())
 	if err != nil {
 		return nil, err
 	}
@@ -75,56 +86,80 @@ func DataFor(elems ifs.IElements, security ifs.ISecurityProvider) ([]byte, error
 	return data, err
 }
 
-// CreateMessageFor creates a complete message with all routing and metadata.
-// It supports unicast/multicast modes, request/reply patterns, transactions,
-// and priority-based scheduling.
-func (this *Protocol) CreateMessageFor(destination, serviceName string, serviceArea byte,
-	priority ifs.Priority, multicastMode ifs.MulticastMode, action ifs.Action, source, vnet string, o ifs.IElements,
-	isRequest, isReply bool, msgNum uint32,
-	tr_state ifs.TransactionState, tr_id, tr_errMsg string,
-	tr_created, tr_queued, tr_running, tr_complete, tr_timeout int64, tr_replica byte, tr_isReplica bool,
-	aaaid string) ([]byte, error) {
-
+// Create creates a complete message per the options, with the elements as its payload
+// and the extensions, if any, in its trailer.
+func (this *Protocol) Create(opts *MessageOptions, o ifs.IElements) ([]byte, error) {
 	//Disable priority for now until i figure out what is causing starvation
-	priority = ifs.P8
+	priority := ifs.P8
 
 	var data []byte
 	var err error
 
+	if o == nil {
+		o = object.New(nil, nil)
+	}
 	data, err = o.Serialize()
 	if err != nil {
 		return nil, err
 	}
 
-	msg, err := this.vnic.Resources().Security().Message(aaaid, this.vnic)
-	defer MsgLog.AddLog(serviceName, serviceArea, action)
+	msg, err := this.vnic.Resources().Security().Message(opts.Token, this.vnic)
 	if err != nil {
 		return nil, err
 	}
-	msg.Init(destination,
-		serviceName,
-		serviceArea,
+	tr := opts.transactionFields()
+	msg.Init(opts.Destination,
+		opts.ServiceName,
+		opts.ServiceArea,
 		priority,
-		multicastMode,
-		action,
-		source,
-		vnet,
+		opts.MulticastMode,
+		opts.Action,
+		opts.Source,
+		opts.Vnet,
 		data,
-		isRequest,
-		isReply,
-		msgNum,
-		tr_state,
-		tr_id,
-		tr_errMsg,
-		tr_created,
-		tr_queued,
-		tr_running,
-		tr_complete,
-		tr_timeout,
-		tr_replica,
-		tr_isReplica)
+		opts.Request,
+		opts.Reply,
+		opts.Sequence,
+		tr.State,
+		tr.Id,
+		tr.ErrMsg,
+		tr.Created,
+		tr.Queued,
+		tr.Running,
+		tr.End,
+		tr.Timeout,
+		tr.Replica,
+		tr.IsReplica)
+
+	data, err = msg.Marshal(nil, this.vnic.Resources())
+	if err != nil {
+		return nil, err
+	}
+	if len(opts.Extensions) > 0 {
+		data = AppendExtensions(data, opts.Extensions)
+	}
+	this.stats.Record(opts.Source, opts.Destination, opts.ServiceName, opts.ServiceArea, opts.Action, len(data))
+	return data, nil
+}
 
-	return msg.Marshal(nil, this.vnic.Resources())
+// CreateMessageFor creates a complete message with all routing and metadata.
+//
+// Deprecated: use Create with MessageOptions.
+func (this *Protocol) CreateMessageFor(destination, serviceName string, serviceArea byte,
+	priority ifs.Priority, multicastMode ifs.MulticastMode, action ifs.Action, source, vnet string, o ifs.IElements,
+	isRequest, isReply bool, msgNum uint32,
+	tr_state ifs.TransactionState, tr_id, tr_errMsg string,
+	tr_created, tr_queued, tr_running, tr_complete, tr_timeout int64, tr_replica byte, tr_isReplica bool,
+	aaaid string) ([]byte, error) {
+	opts := &MessageOptions{Destination: destination, ServiceName: serviceName, ServiceArea: serviceArea,
+		Action: action, Priority: priority, MulticastMode: multicastMode, Source: source, Vnet: vnet,
+		Request: isRequest, Reply: isReply, Sequence: msgNum, Token: aaaid, Timeout: tr_timeout}
+	if tr_state != ifs.NotATransaction {
+		opts.Transaction = &Transaction{State: tr_state, Id: tr_id, ErrMsg: tr_errMsg, Created: tr_created,
+			Queued: tr_queued, Running: tr_running, End: tr_complete, Timeout: tr_timeout,
+			Replica: tr_replica, IsReplica: tr_isReplica}
+	}
+	return this.Create(opts, o)
 }
 
 // CreateMessageForm creates a message from an existing Message template with new payload elements.
@@ -0,0 +1,137 @@
+// © 2025 Sharon Aicler (saichler@gmail.com)
+//
+// Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package protocol
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/saichler/l8types/go/ifs"
+	"github.com/saichler/l8utils/go/utils/strings"
+)
+
+// MessageLog enables or disables the message statistics for debugging and monitoring.
+var MessageLog bool = false
+
+// MsgLog is the process wide message statistics, every Protocol records into it as well.
+var MsgLog = NewMessageStatistics(nil)
+
+// MessageStatistics counts messages & bytes by service name, area and action, and by
+// source and destination.
+type MessageStatistics struct {
+	mtx     sync.Mutex
+	types   map[TypeKey]*Counter
+	flows   map[FlowKey]*Counter
+	total   Counter
+	parent  *MessageStatistics
+	enabled atomic.Bool
+}
+
+// TypeKey is the service name, area & action of a message
+type TypeKey struct {
+	ServiceName string
+	ServiceArea byte
+	Action      ifs.Action
+}
+
+// FlowKey is the source & destination of a message, an empty destination is a multicast
+type FlowKey struct {
+	Source      string
+	Destination string
+}
+
+// Counter is a message count and their total size in bytes
+type Counter struct {
+	Count int64 `json:"count"`
+	Bytes int64 `json:"bytes"`
+}
+
+// NewMessageStatistics creates message statistics, records are added to the parent as well
+// if it is not nil.
+func NewMessageStatistics(parent *MessageStatistics) *MessageStatistics {
+	return &MessageStatistics{types: make(map[TypeKey]*Counter), flows: make(map[FlowKey]*Counter), parent: parent}
+}
+
+// Enable turns the recording of these statistics on or off, regardless of MessageLog.
+func (this *MessageStatistics) Enable(enabled bool) {
+	this.enabled.Store(enabled)
+}
+
+// Record adds a message of size bytes to the statistics.
+// If neither these statistics nor MessageLog are enabled, this function returns immediately
+// without counting.
+func (this *MessageStatistics) Record(source, destination, serviceName string, serviceArea byte, action ifs.Action, bytes int) {
+	if !MessageLog && !this.enabled.Load() {
+		return
+	}
+	this.mtx.Lock()
+	add(this.types, TypeKey{ServiceName: serviceName, ServiceArea: serviceArea, Action: action}, bytes)
+	add(this.flows, FlowKey{Source: source, Destination: destination}, bytes)
+	this.total.Count++
+	this.total.Bytes += int64(bytes)
+	this.mtx.Unlock()
+	if this.parent != nil {
+		this.parent.Record(source, destination, serviceName, serviceArea, action, bytes)
+	}
+}
+
+func add[K comparable](m map[K]*Counter, key K, bytes int) {
+	counter, ok := m[key]
+	if !ok {
+		counter = &Counter{}
+		m[key] = counter
+	}
+	counter.Count++
+	counter.Bytes += int64(bytes)
+}
+
+// Snapshot returns a copy of the counters.
+func (this *MessageStatistics) Snapshot() *StatisticsSnapshot {
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+	snapshot := &StatisticsSnapshot{Time: time.Now().UnixMilli(), Total: &Rate{Counter: this.total}}
+	for k, v := range this.types {
+		snapshot.Types = append(snapshot.Types, &TypeStats{ServiceName: k.ServiceName, ServiceArea: k.ServiceArea,
+			Action: strings.New(k.Action).String(), Rate: Rate{Counter: *v}})
+	}
+	for k, v := range this.flows {
+		snapshot.Flows = append(snapshot.Flows, &FlowStats{Source: k.Source, Destination: k.Destination, Rate: Rate{Counter: *v}})
+	}
+	sort.Slice(snapshot.Types, func(i, j int) bool {
+		return snapshot.Types[i].key() < snapshot.Types[j].key()
+	})
+	sort.Slice(snapshot.Flows, func(i, j int) bool {
+		return snapshot.Flows[i].key() < snapshot.Flows[j].key()
+	})
+	return snapshot
+}
+
+// Print outputs all message type counts and the total to stdout.
+func (this *MessageStatistics) Print() {
+	snapshot := this.Snapshot()
+	for _, t := range snapshot.Types {
+		fmt.Println(t.key(), " - ", t.Count, " messages ", t.Bytes, " bytes")
+	}
+	fmt.Println("Total - ", snapshot.Total.Count, " messages ", snapshot.Total.Bytes, " bytes")
+}
+
+// Total returns the total number of messages counted.
+func (this *MessageStatistics) Total() int64 {
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+	return this.total.Count
+}
@@ -0,0 +1,289 @@
+// © 2025 Sharon Aicler (saichler@gmail.com)
+//
+// Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package protocol
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	stdstrings "strings"
+	"sync"
+	"time"
+
+	"github.com/saichler/l8types/go/ifs"
+	"github.com/saichler/l8utils/go/utils/strings"
+)
+
+// Statistics output formats
+const (
+	FormatCSV   = "csv"
+	FormatJSONL = "jsonl"
+)
+
+// StatisticsConfig is where & how a StatisticsSink writes the statistics. Every Interval a
+// snapshot is appended to Dir/Name.Format, when the file grows over MaxFileSize it is rotated
+// to Name.1.Format, keeping up to MaxFiles rotated files.
+type StatisticsConfig struct {
+	Dir         string
+	Name        string
+	Format      string
+	Interval    time.Duration
+	MaxFileSize int64
+	MaxFiles    int
+}
+
+// DefaultStatisticsConfig returns a csv config writing every 10 seconds to the temp
+// directory, rotating at 10MB and keeping 5 files.
+func DefaultStatisticsConfig(name string) *StatisticsConfig {
+	return &StatisticsConfig{Dir: os.TempDir(), Name: name, Format: FormatCSV, Interval: time.Second * 10,
+		MaxFileSize: 10 * 1024 * 1024, MaxFiles: 5}
+}
+
+// Rate is a counter and its per second rates over the last interval
+type Rate struct {
+	Counter
+	Rate     float64 `json:"rate"`
+	ByteRate float64 `json:"byteRate"`
+}
+
+// TypeStats is the statistics of a service name, area & action
+type TypeStats struct {
+	ServiceName string `json:"serviceName"`
+	ServiceArea byte   `json:"serviceArea"`
+	Action      string `json:"action"`
+	Rate
+}
+
+// FlowStats is the statistics of a source & destination
+type FlowStats struct {
+	Source      string `json:"source"`
+	Destination string `json:"destination"`
+	Rate
+}
+
+// StatisticsSnapshot is the statistics at a point in time, the rates are over the
+// Interval in milliseconds since the previous snapshot.
+type StatisticsSnapshot struct {
+	Time     int64        `json:"time"`
+	Interval int64        `json:"interval"`
+	Total    *Rate        `json:"total"`
+	Types    []*TypeStats `json:"types"`
+	Flows    []*FlowStats `json:"flows"`
+}
+
+func (this *TypeStats) key() string {
+	return strings.New(this.ServiceName, "/", int(this.ServiceArea), "/", this.Action).String()
+}
+
+func (this *FlowStats) key() string {
+	return strings.New(this.Source, "->", this.Destination).String()
+}
+
+// rates sets the rates of the snapshot by the counters of the previous one
+func (this *StatisticsSnapshot) rates(previous *StatisticsSnapshot) {
+	if previous == nil {
+		return
+	}
+	this.Interval = this.Time - previous.Time
+	if this.Interval <= 0 {
+		return
+	}
+	seconds := float64(this.Interval) / 1000
+	this.Total.setRate(previous.Total.Counter, seconds)
+	types := make(map[string]Counter)
+	for _, t := range previous.Types {
+		types[t.key()] = t.Counter
+	}
+	for _, t := range this.Types {
+		t.setRate(types[t.key()], seconds)
+	}
+	flows := make(map[string]Counter)
+	for _, f := range previous.Flows {
+		flows[f.key()] = f.Counter
+	}
+	for _, f := range this.Flows {
+		f.setRate(flows[f.key()], seconds)
+	}
+}
+
+func (this *Rate) setRate(previous Counter, seconds float64) {
+	this.Rate = float64(this.Count-previous.Count) / seconds
+	this.ByteRate = float64(this.Bytes-previous.Bytes) / seconds
+}
+
+// StatisticsSink periodically writes the snapshots of message statistics to a rotating file.
+type StatisticsSink struct {
+	stats    *MessageStatistics
+	config   *StatisticsConfig
+	logger   ifs.ILogger
+	previous *StatisticsSnapshot
+	mtx      sync.Mutex
+	stop     chan bool
+	done     chan bool
+	stopOnce sync.Once
+}
+
+// NewStatisticsSink validates the config and creates the output directory.
+func NewStatisticsSink(stats *MessageStatistics, config *StatisticsConfig, logger ifs.ILogger) (*StatisticsSink, error) {
+	if config.Format != FormatCSV && config.Format != FormatJSONL {
+		return nil, errors.New(strings.New("Unknown statistics format ", config.Format).String())
+	}
+	if config.Name == "" {
+		return nil, errors.New("Statistics file name is empty")
+	}
+	if config.Interval <= 0 {
+		return nil, errors.New("Statistics interval must be positive")
+	}
+	err := os.MkdirAll(config.Dir, 0755)
+	if err != nil {
+		return nil, err
+	}
+	return &StatisticsSink{stats: stats, config: config, logger: logger, stop: make(chan bool), done: make(chan bool)}, nil
+}
+
+// Start writes a snapshot every interval until Stop is called.
+func (this *StatisticsSink) Start() {
+	go this.run()
+}
+
+// Stop writes a last snapshot and stops the sink, it is safe to call more than once.
+func (this *StatisticsSink) Stop() {
+	this.stopOnce.Do(func() {
+		close(this.stop)
+		<-this.done
+	})
+}
+
+// Filename returns the path of the current output file.
+func (this *StatisticsSink) Filename() string {
+	return this.filename(0)
+}
+
+func (this *StatisticsSink) filename(index int) string {
+	name := this.config.Name
+	if index > 0 {
+		name = strings.New(name, ".", index).String()
+	}
+	return filepath.Join(this.config.Dir, strings.New(name, ".", this.config.Format).String())
+}
+
+func (this *StatisticsSink) run() {
+	defer close(this.done)
+	ticker := time.NewTicker(this.config.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			this.write()
+		case <-this.stop:
+			this.write()
+			return
+		}
+	}
+}
+
+func (this *StatisticsSink) write() {
+	err := this.Write()
+	if err != nil {
+		this.logger.Error("Failed to write statistics: ", err.Error())
+	}
+}
+
+// Write appends a snapshot with the rates since the previous write to the output file.
+func (this *StatisticsSink) Write() error {
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+	snapshot := this.stats.Snapshot()
+	snapshot.rates(this.previous)
+	this.previous = snapshot
+	err := this.rotate()
+	if err != nil {
+		return err
+	}
+	file, err := os.OpenFile(this.Filename(), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	var data []byte
+	if this.config.Format == FormatJSONL {
+		data, err = json.Marshal(snapshot)
+		if err != nil {
+			return err
+		}
+		data = append(data, '\n')
+	} else {
+		data = snapshot.csv(info.Size() == 0)
+	}
+	_, err = file.Write(data)
+	return err
+}
+
+// rotate shifts the output files when the current one is over the max size, dropping the oldest.
+func (this *StatisticsSink) rotate() error {
+	if this.config.MaxFileSize <= 0 {
+		return nil
+	}
+	info, err := os.Stat(this.Filename())
+	if err != nil || info.Size() < this.config.MaxFileSize {
+		return nil
+	}
+	if this.config.MaxFiles <= 0 {
+		return os.Remove(this.Filename())
+	}
+	os.Remove(this.filename(this.config.MaxFiles))
+	for i := this.config.MaxFiles - 1; i >= 0; i-- {
+		_, err = os.Stat(this.filename(i))
+		if err == nil {
+			err = os.Rename(this.filename(i), this.filename(i+1))
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// csv returns a row per type and flow plus a total row, with a header for a new file.
+func (this *StatisticsSnapshot) csv(header bool) []byte {
+	str := &stdstrings.Builder{}
+	if header {
+		str.WriteString("\"Time\",\"Kind\",\"Service\",\"Area\",\"Action\",\"Source\",\"Destination\",\"Count\",\"Bytes\",\"Rate\",\"ByteRate\"\n")
+	}
+	time := strconv.FormatInt(this.Time, 10)
+	for _, t := range this.Types {
+		csvRow(str, []string{time, "type", t.ServiceName, strconv.Itoa(int(t.ServiceArea)), t.Action, "", ""}, &t.Rate)
+	}
+	for _, f := range this.Flows {
+		csvRow(str, []string{time, "flow", "", "", "", f.Source, f.Destination}, &f.Rate)
+	}
+	csvRow(str, []string{time, "total", "", "", "", "", ""}, this.Total)
+	return []byte(str.String())
+}
+
+func csvRow(str *stdstrings.Builder, fields []string, rate *Rate) {
+	for _, field := range fields {
+		str.WriteString("\"" + field + "\",")
+	}
+	str.WriteString(strconv.FormatInt(rate.Count, 10) + ",")
+	str.WriteString(strconv.FormatInt(rate.Bytes, 10) + ",")
+	str.WriteString(strconv.FormatFloat(rate.Rate, 'f', 2, 64) + ",")
+	str.WriteString(strconv.FormatFloat(rate.ByteRate, 'f', 2, 64) + "\n")
+}
@@ -0,0 +1,186 @@
+// © 2025 Sharon Aicler (saichler@gmail.com)
+//
+// Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracing
+
+import (
+	"encoding/json"
+	"os"
+	"strconv"
+	"sync"
+)
+
+// Exporter receives the finished spans
+type Exporter interface {
+	Export(span *Span)
+	Shutdown() error
+}
+
+// MemoryExporter keeps the finished spans in memory, e.g. for tests
+type MemoryExporter struct {
+	spans []*Span
+	mtx   sync.Mutex
+}
+
+// NewMemoryExporter creates an empty in memory exporter
+func NewMemoryExporter() *MemoryExporter {
+	return &MemoryExporter{spans: make([]*Span, 0)}
+}
+
+func (this *MemoryExporter) Export(span *Span) {
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+	this.spans = append(this.spans, span)
+}
+
+func (this *MemoryExporter) Shutdown() error {
+	return nil
+}
+
+// Spans returns the spans exported so far
+func (this *MemoryExporter) Spans() []*Span {
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+	result := make([]*Span, len(this.spans))
+	copy(result, this.spans)
+	return result
+}
+
+// Trace returns the exported spans of the given trace id
+func (this *MemoryExporter) Trace(traceId string) []*Span {
+	result := make([]*Span, 0)
+	for _, span := range this.Spans() {
+		if span.TraceIdString() == traceId {
+			result = append(result, span)
+		}
+	}
+	return result
+}
+
+// Reset drops the spans exported so far
+func (this *MemoryExporter) Reset() {
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+	this.spans = make([]*Span, 0)
+}
+
+// FileExporter appends the spans to a file in the OTLP json format, one export request
+// per line, as read by the OpenTelemetry collector otlpjsonfile receiver.
+type FileExporter struct {
+	file *os.File
+	mtx  sync.Mutex
+}
+
+// NewFileExporter creates an exporter appending to the given file
+func NewFileExporter(filename string) (*FileExporter, error) {
+	file, err := os.OpenFile(filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &FileExporter{file: file}, nil
+}
+
+func (this *FileExporter) Export(span *Span) {
+	data, err := json.Marshal(otlpOf(span))
+	if err != nil {
+		return
+	}
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+	this.file.Write(append(data, '\n'))
+}
+
+func (this *FileExporter) Shutdown() error {
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+	return this.file.Close()
+}
+
+// The OTLP json encoding of a span, ids are hex and times are nanoseconds as strings
+type otlpTraces struct {
+	ResourceSpans []*otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   *otlpResource     `json:"resource"`
+	ScopeSpans []*otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []*otlpAttribute `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope *otlpScope  `json:"scope"`
+	Spans []*otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpSpan struct {
+	TraceId           string           `json:"traceId"`
+	SpanId            string           `json:"spanId"`
+	ParentSpanId      string           `json:"parentSpanId,omitempty"`
+	Name              string           `json:"name"`
+	Kind              int              `json:"kind"`
+	StartTimeUnixNano string           `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string           `json:"endTimeUnixNano"`
+	Attributes        []*otlpAttribute `json:"attributes,omitempty"`
+	Status            *otlpStatus      `json:"status,omitempty"`
+}
+
+type otlpAttribute struct {
+	Key   string     `json:"key"`
+	Value *otlpValue `json:"value"`
+}
+
+type otlpValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+// otlpStatusError is the OTLP status code of a failed span
+const otlpStatusError = 2
+
+func otlpOf(span *Span) *otlpTraces {
+	s := &otlpSpan{
+		TraceId:           span.TraceIdString(),
+		SpanId:            span.SpanIdString(),
+		ParentSpanId:      span.ParentSpanIdString(),
+		Name:              span.Name,
+		Kind:              int(span.Kind),
+		StartTimeUnixNano: strconv.FormatInt(span.Start.UnixNano(), 10),
+		EndTimeUnixNano:   strconv.FormatInt(span.End.UnixNano(), 10),
+	}
+	span.mtx.Lock()
+	for key, value := range span.Attributes {
+		s.Attributes = append(s.Attributes, &otlpAttribute{Key: key, Value: &otlpValue{StringValue: value}})
+	}
+	if span.Error != "" {
+		s.Status = &otlpStatus{Code: otlpStatusError, Message: span.Error}
+	}
+	span.mtx.Unlock()
+	resource := &otlpResource{Attributes: []*otlpAttribute{
+		{Key: "service.name", Value: &otlpValue{StringValue: span.Service}},
+	}}
+	return &otlpTraces{ResourceSpans: []*otlpResourceSpans{{
+		Resource:   resource,
+		ScopeSpans: []*otlpScopeSpans{{Scope: &otlpScope{Name: "l8bus"}, Spans: []*otlpSpan{s}}},
+	}}}
+}
@@ -0,0 +1,157 @@
+// © 2025 Sharon Aicler (saichler@gmail.com)
+//
+// Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracing
+
+import (
+	"encoding/hex"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// SpanKind is the role of the span in the request, values follow OTLP
+type SpanKind int
+
+const (
+	SpanKindInternal SpanKind = 1
+	SpanKindServer   SpanKind = 2
+	SpanKindClient   SpanKind = 3
+)
+
+// SampleRate is the ratio of new traces that are sampled, spans of a trace that is not
+// sampled still propagate the trace context but are not exported.
+var SampleRate = 1.0
+
+var exporter atomic.Pointer[exporterHolder]
+
+type exporterHolder struct {
+	exporter Exporter
+}
+
+// SetExporter sets the exporter of the finished spans, tracing is disabled, and no trace
+// context is added to the messages, while there is no exporter.
+func SetExporter(e Exporter) {
+	if e == nil {
+		exporter.Store(nil)
+		return
+	}
+	exporter.Store(&exporterHolder{exporter: e})
+}
+
+// Enabled returns true if there is an exporter
+func Enabled() bool {
+	return exporter.Load() != nil
+}
+
+// Span is a timed operation of a trace
+type Span struct {
+	Name         string
+	Kind         SpanKind
+	TraceId      [16]byte
+	SpanId       [8]byte
+	ParentSpanId [8]byte
+	Start        time.Time
+	End          time.Time
+	Service      string
+	Attributes   map[string]string
+	Error        string
+	sampled      bool
+	mtx          sync.Mutex
+}
+
+// StartSpan starts a span of the service, the child of the parent trace context or the
+// root of a new trace when there is no parent. It returns nil when tracing is disabled,
+// all the Span methods accept a nil span.
+func StartSpan(service, name string, kind SpanKind, parent *TraceContext) *Span {
+	if !Enabled() {
+		return nil
+	}
+	span := &Span{Name: name, Kind: kind, Service: service, Start: time.Now(), SpanId: newSpanId()}
+	if parent != nil {
+		span.TraceId = parent.TraceId
+		span.ParentSpanId = parent.SpanId
+		span.sampled = parent.Sampled
+	} else {
+		span.TraceId = newTraceId()
+		span.sampled = SampleRate >= 1 || rand.Float64() < SampleRate
+	}
+	return span
+}
+
+// Context returns the trace context to propagate to the messages sent by the span
+func (this *Span) Context() *TraceContext {
+	if this == nil {
+		return nil
+	}
+	return &TraceContext{TraceId: this.TraceId, SpanId: this.SpanId, Sampled: this.sampled}
+}
+
+// SetAttribute sets an attribute of the span
+func (this *Span) SetAttribute(key, value string) {
+	if this == nil {
+		return
+	}
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+	if this.Attributes == nil {
+		this.Attributes = make(map[string]string)
+	}
+	this.Attributes[key] = value
+}
+
+// SetError marks the span as failed
+func (this *Span) SetError(err string) {
+	if this == nil {
+		return
+	}
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+	this.Error = err
+}
+
+// Finish ends the span and exports it if it is sampled
+func (this *Span) Finish() {
+	if this == nil || !this.sampled {
+		return
+	}
+	this.End = time.Now()
+	holder := exporter.Load()
+	if holder != nil {
+		holder.exporter.Export(this)
+	}
+}
+
+// Sampled returns true if the span is exported when finished
+func (this *Span) Sampled() bool {
+	return this != nil && this.sampled
+}
+
+// TraceIdString returns the trace id in hex
+func (this *Span) TraceIdString() string {
+	return hex.EncodeToString(this.TraceId[:])
+}
+
+// SpanIdString returns the span id in hex
+func (this *Span) SpanIdString() string {
+	return hex.EncodeToString(this.SpanId[:])
+}
+
+// ParentSpanIdString returns the parent span id in hex, or empty for a root span
+func (this *Span) ParentSpanIdString() string {
+	if this.ParentSpanId == [8]byte{} {
+		return ""
+	}
+	return hex.EncodeToString(this.ParentSpanId[:])
+}
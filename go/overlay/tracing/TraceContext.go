@@ -0,0 +1,112 @@
+// © 2025 Sharon Aicler (saichler@gmail.com)
+//
+// Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package tracing follows requests through the overlay. The trace context is carried
+// in the extensions trailer of every message, spans are created as a message is sent,
+// routed by the VNets and handled, and are exported by a pluggable Exporter.
+package tracing
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/saichler/l8bus/go/overlay/protocol"
+)
+
+const traceContextSize = 25
+
+// TraceContext is the trace of a message and the span that sent it
+type TraceContext struct {
+	TraceId [16]byte
+	SpanId  [8]byte
+	Sampled bool
+}
+
+// TraceIdString returns the trace id in hex
+func (this *TraceContext) TraceIdString() string {
+	return hex.EncodeToString(this.TraceId[:])
+}
+
+// SpanIdString returns the span id in hex
+func (this *TraceContext) SpanIdString() string {
+	return hex.EncodeToString(this.SpanId[:])
+}
+
+// Bytes encodes the trace context as the trace id, span id & the sampling flag
+func (this *TraceContext) Bytes() []byte {
+	data := make([]byte, traceContextSize)
+	copy(data, this.TraceId[:])
+	copy(data[16:], this.SpanId[:])
+	if this.Sampled {
+		data[24] = 1
+	}
+	return data
+}
+
+// contextOf decodes a trace context, returning nil if the data is not a trace context
+func contextOf(data []byte) *TraceContext {
+	if len(data) != traceContextSize {
+		return nil
+	}
+	ctx := &TraceContext{Sampled: data[24] == 1}
+	copy(ctx.TraceId[:], data)
+	copy(ctx.SpanId[:], data[16:])
+	return ctx
+}
+
+// Inject returns the message bytes with the trace context in their extensions, or the
+// data as is when there is no trace context.
+func Inject(data []byte, ctx *TraceContext) []byte {
+	if ctx == nil {
+		return data
+	}
+	return protocol.WithExtension(data, protocol.Ext_Trace, ctx.Bytes())
+}
+
+// WithTrace sets the trace context as an extension of the message options, if it is not nil.
+func WithTrace(opts *protocol.MessageOptions, ctx *TraceContext) *protocol.MessageOptions {
+	if ctx != nil {
+		opts.WithExtension(protocol.Ext_Trace, ctx.Bytes())
+	}
+	return opts
+}
+
+// TraceOf returns the trace context set on the message options, or nil if there is none.
+func TraceOf(opts *protocol.MessageOptions) *TraceContext {
+	value, ok := opts.Extensions[protocol.Ext_Trace]
+	if !ok {
+		return nil
+	}
+	return contextOf(value)
+}
+
+// Extract returns the trace context of the message bytes, or nil if there is none.
+func Extract(data []byte) *TraceContext {
+	value, ok := protocol.ExtensionOf(data, protocol.Ext_Trace)
+	if !ok {
+		return nil
+	}
+	return contextOf(value)
+}
+
+func newTraceId() [16]byte {
+	id := [16]byte{}
+	rand.Read(id[:])
+	return id
+}
+
+func newSpanId() [8]byte {
+	id := [8]byte{}
+	rand.Read(id[:])
+	return id
+}
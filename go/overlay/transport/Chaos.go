@@ -0,0 +1,295 @@
+// © 2025 Sharon Aicler (saichler@gmail.com)
+//
+// Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transport
+
+import (
+	"errors"
+	"math/rand"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/saichler/l8types/go/ifs"
+	"github.com/saichler/l8utils/go/utils/strings"
+)
+
+// Faults describes the faults injected on a link between two named endpoints.
+type Faults struct {
+	// Latency added before every write and every read
+	Latency time.Duration
+	// Jitter is a random extra delay, up to this value, added to the latency
+	Jitter time.Duration
+	// ReadDelay is added before every read to simulate a slow consumer
+	ReadDelay time.Duration
+	// DropRate is the probability of a write being silently dropped
+	DropRate float64
+	// PartialRate is the probability of a write being cut in half and the connection reset
+	PartialRate float64
+	// ResetRate is the probability of the connection being reset on a write
+	ResetRate float64
+	// CorruptRate is the probability of a byte in the middle of a write being flipped
+	CorruptRate float64
+}
+
+// Chaos wraps another transport and injects faults on the connections it creates.
+// Each VNet or VNic gets a named view of the Chaos transport via Named, so faults and
+// partitions can be set between named endpoints. Faults are applied by the dialing side
+// of a link, in both directions, which is enough as closing it resets both ends.
+type Chaos struct {
+	inner      Transport
+	mtx        *sync.Mutex
+	rnd        *rand.Rand
+	ports      map[uint32]string
+	faults     map[string]*Faults
+	partitions map[string]time.Time
+	conns      map[*chaosConn]bool
+	defaults   *Faults
+}
+
+// NewChaos creates a fault injecting transport over the inner transport.
+// The seed makes the random faults reproducible between runs.
+func NewChaos(inner Transport, seed int64) *Chaos {
+	c := &Chaos{}
+	c.inner = inner
+	c.mtx = &sync.Mutex{}
+	c.rnd = rand.New(rand.NewSource(seed))
+	c.ports = make(map[uint32]string)
+	c.faults = make(map[string]*Faults)
+	c.partitions = make(map[string]time.Time)
+	c.conns = make(map[*chaosConn]bool)
+	c.defaults = &Faults{}
+	return c
+}
+
+// Named returns a view of this transport for the endpoint with the given name.
+// A VNet listening through the view is known by that name to endpoints dialing it.
+func (this *Chaos) Named(name string) Transport {
+	return &chaosTransport{chaos: this, name: name}
+}
+
+// SetDefaultFaults sets the faults applied to links that have no specific faults.
+func (this *Chaos) SetDefaultFaults(faults *Faults) {
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+	if faults == nil {
+		faults = &Faults{}
+	}
+	this.defaults = faults
+}
+
+// SetFaults sets the faults applied to the link between endpoints a and b.
+func (this *Chaos) SetFaults(a, b string, faults *Faults) {
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+	if faults == nil {
+		delete(this.faults, linkKey(a, b))
+		return
+	}
+	this.faults[linkKey(a, b)] = faults
+}
+
+// Partition cuts the link between endpoints a and b. Existing connections are reset and
+// new ones are refused until the partition heals. A healAfter of 0 means it heals only by Heal.
+func (this *Chaos) Partition(a, b string, healAfter time.Duration) {
+	this.mtx.Lock()
+	healTime := time.Time{}
+	if healAfter > 0 {
+		healTime = time.Now().Add(healAfter)
+	}
+	key := linkKey(a, b)
+	this.partitions[key] = healTime
+	toReset := make([]*chaosConn, 0)
+	for conn, _ := range this.conns {
+		if conn.link == key {
+			toReset = append(toReset, conn)
+		}
+	}
+	this.mtx.Unlock()
+	for _, conn := range toReset {
+		conn.Close()
+	}
+}
+
+// Heal removes the partition between endpoints a and b.
+func (this *Chaos) Heal(a, b string) {
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+	delete(this.partitions, linkKey(a, b))
+}
+
+// HealAll removes all partitions.
+func (this *Chaos) HealAll() {
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+	this.partitions = make(map[string]time.Time)
+}
+
+// Partitioned returns true if the link between endpoints a and b is currently cut.
+func (this *Chaos) Partitioned(a, b string) bool {
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+	return this.partitioned(linkKey(a, b))
+}
+
+func (this *Chaos) partitioned(key string) bool {
+	healTime, ok := this.partitions[key]
+	if !ok {
+		return false
+	}
+	if !healTime.IsZero() && !time.Now().Before(healTime) {
+		delete(this.partitions, key)
+		return false
+	}
+	return true
+}
+
+func (this *Chaos) faultsFor(key string) *Faults {
+	faults, ok := this.faults[key]
+	if ok {
+		return faults
+	}
+	return this.defaults
+}
+
+// chance returns true with the given probability.
+func (this *Chaos) chance(probability float64) bool {
+	if probability <= 0 {
+		return false
+	}
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+	return this.rnd.Float64() < probability
+}
+
+// delay sleeps for the latency and jitter configured on the link.
+func (this *Chaos) delay(latency, jitter time.Duration) {
+	if jitter > 0 {
+		this.mtx.Lock()
+		latency += time.Duration(this.rnd.Int63n(int64(jitter)))
+		this.mtx.Unlock()
+	}
+	if latency > 0 {
+		time.Sleep(latency)
+	}
+}
+
+func linkKey(a, b string) string {
+	if a > b {
+		a, b = b, a
+	}
+	return strings.New(a, "<->", b).String()
+}
+
+// chaosTransport is a named view of the Chaos transport.
+type chaosTransport struct {
+	chaos *Chaos
+	name  string
+}
+
+func (this *chaosTransport) Listen(port uint32) (net.Listener, error) {
+	this.chaos.mtx.Lock()
+	this.chaos.ports[port] = this.name
+	this.chaos.mtx.Unlock()
+	return this.chaos.inner.Listen(port)
+}
+
+func (this *chaosTransport) Dial(host string, port uint32, security ifs.ISecurityProvider) (net.Conn, error) {
+	this.chaos.mtx.Lock()
+	remote, ok := this.chaos.ports[port]
+	if !ok {
+		remote = strings.New(host, ":", int(port)).String()
+	}
+	key := linkKey(this.name, remote)
+	partitioned := this.chaos.partitioned(key)
+	this.chaos.mtx.Unlock()
+	if partitioned {
+		return nil, errors.New(strings.New("Chaos transport link ", key, " is partitioned").String())
+	}
+	conn, err := this.chaos.inner.Dial(host, port, security)
+	if err != nil {
+		return nil, err
+	}
+	cc := &chaosConn{Conn: conn, chaos: this.chaos, link: key}
+	this.chaos.mtx.Lock()
+	this.chaos.conns[cc] = true
+	this.chaos.mtx.Unlock()
+	return cc, nil
+}
+
+// chaosConn is a connection with faults injected on its reads and writes.
+type chaosConn struct {
+	net.Conn
+	chaos *Chaos
+	link  string
+	once  sync.Once
+}
+
+func (this *chaosConn) faults() (*Faults, bool) {
+	this.chaos.mtx.Lock()
+	defer this.chaos.mtx.Unlock()
+	return this.chaos.faultsFor(this.link), this.chaos.partitioned(this.link)
+}
+
+func (this *chaosConn) Read(data []byte) (int, error) {
+	faults, partitioned := this.faults()
+	if partitioned {
+		this.Close()
+		return 0, net.ErrClosed
+	}
+	this.chaos.delay(faults.ReadDelay, 0)
+	n, err := this.Conn.Read(data)
+	if err != nil {
+		return n, err
+	}
+	this.chaos.delay(faults.Latency, faults.Jitter)
+	return n, err
+}
+
+func (this *chaosConn) Write(data []byte) (int, error) {
+	faults, partitioned := this.faults()
+	if partitioned {
+		this.Close()
+		return 0, net.ErrClosed
+	}
+	this.chaos.delay(faults.Latency, faults.Jitter)
+	if this.chaos.chance(faults.ResetRate) {
+		this.Close()
+		return 0, net.ErrClosed
+	}
+	if this.chaos.chance(faults.DropRate) {
+		return len(data), nil
+	}
+	if len(data) > 16 && this.chaos.chance(faults.CorruptRate) {
+		corrupted := append([]byte{}, data...)
+		corrupted[len(corrupted)/2] ^= 0xFF
+		return this.Conn.Write(corrupted)
+	}
+	if len(data) > 1 && this.chaos.chance(faults.PartialRate) {
+		n, _ := this.Conn.Write(data[:len(data)/2])
+		this.Close()
+		return n, net.ErrClosed
+	}
+	return this.Conn.Write(data)
+}
+
+func (this *chaosConn) Close() error {
+	var err error
+	this.once.Do(func() {
+		this.chaos.mtx.Lock()
+		delete(this.chaos.conns, this)
+		this.chaos.mtx.Unlock()
+		err = this.Conn.Close()
+	})
+	return err
+}
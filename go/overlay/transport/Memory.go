@@ -0,0 +1,112 @@
+// © 2025 Sharon Aicler (saichler@gmail.com)
+//
+// Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transport
+
+import (
+	"errors"
+	"net"
+	"sync"
+	"sync/atomic"
+
+	"github.com/saichler/l8types/go/ifs"
+	"github.com/saichler/l8utils/go/utils/strings"
+)
+
+// Memory is an in-process transport. VNets listen on a port inside the Memory instance
+// and VNics dialing that port get a buffered pipe instead of a socket, so a full
+// multi VNet topology can run inside a single test binary. The dialed host is ignored,
+// all VNets sharing a Memory instance are addressed by port only.
+type Memory struct {
+	listeners map[uint32]*memoryListener
+	mtx       *sync.Mutex
+	nextPort  atomic.Int32
+}
+
+// NewMemory creates a new, empty, in-process transport.
+func NewMemory() *Memory {
+	m := &Memory{}
+	m.listeners = make(map[uint32]*memoryListener)
+	m.mtx = &sync.Mutex{}
+	m.nextPort.Store(49152)
+	return m
+}
+
+// Listen registers an in-process listener for the given port.
+func (this *Memory) Listen(port uint32) (net.Listener, error) {
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+	_, ok := this.listeners[port]
+	if ok {
+		return nil, errors.New(strings.New("Memory transport port ", int(port), " is already in use").String())
+	}
+	listener := &memoryListener{port: port, memory: this, conns: make(chan net.Conn, 64), done: make(chan bool)}
+	this.listeners[port] = listener
+	return listener, nil
+}
+
+// Dial connects to the in-process listener on the given port, the security provider is not used
+// as there is no socket to dial, the connection is still validated by the handshake.
+func (this *Memory) Dial(host string, port uint32, security ifs.ISecurityProvider) (net.Conn, error) {
+	this.mtx.Lock()
+	listener, ok := this.listeners[port]
+	this.mtx.Unlock()
+	if !ok {
+		return nil, errors.New(strings.New("Memory transport connection refused on port ", int(port)).String())
+	}
+	clientAddr := &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: int(this.nextPort.Add(1))}
+	serverAddr := &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: int(port)}
+	client, server := NewPipe(clientAddr, serverAddr)
+	select {
+	case listener.conns <- server:
+		return client, nil
+	case <-listener.done:
+		return nil, errors.New(strings.New("Memory transport connection refused on port ", int(port)).String())
+	}
+}
+
+func (this *Memory) unlisten(port uint32) {
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+	delete(this.listeners, port)
+}
+
+// memoryListener is the net.Listener returned by Memory.Listen.
+type memoryListener struct {
+	port   uint32
+	memory *Memory
+	conns  chan net.Conn
+	done   chan bool
+	once   sync.Once
+}
+
+func (this *memoryListener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-this.conns:
+		return conn, nil
+	case <-this.done:
+		return nil, net.ErrClosed
+	}
+}
+
+func (this *memoryListener) Close() error {
+	this.once.Do(func() {
+		this.memory.unlisten(this.port)
+		close(this.done)
+	})
+	return nil
+}
+
+func (this *memoryListener) Addr() net.Addr {
+	return &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: int(this.port)}
+}
@@ -0,0 +1,152 @@
+// © 2025 Sharon Aicler (saichler@gmail.com)
+//
+// Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transport
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+// pipeBuffer is one direction of an in-process connection.
+// Unlike net.Pipe, writes are buffered so both sides may write before reading,
+// the same way a TCP socket behaves during the connection handshake.
+type pipeBuffer struct {
+	mtx      *sync.Mutex
+	cond     *sync.Cond
+	buf      bytes.Buffer
+	closed   bool
+	deadline time.Time
+	timer    *time.Timer
+}
+
+func newPipeBuffer() *pipeBuffer {
+	pb := &pipeBuffer{mtx: &sync.Mutex{}}
+	pb.cond = sync.NewCond(pb.mtx)
+	return pb
+}
+
+func (this *pipeBuffer) read(data []byte) (int, error) {
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+	for this.buf.Len() == 0 {
+		if this.closed {
+			return 0, io.EOF
+		}
+		if !this.deadline.IsZero() && !time.Now().Before(this.deadline) {
+			return 0, os.ErrDeadlineExceeded
+		}
+		this.cond.Wait()
+	}
+	return this.buf.Read(data)
+}
+
+func (this *pipeBuffer) write(data []byte) (int, error) {
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+	if this.closed {
+		return 0, io.ErrClosedPipe
+	}
+	n, err := this.buf.Write(data)
+	this.cond.Broadcast()
+	return n, err
+}
+
+func (this *pipeBuffer) close() {
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+	this.closed = true
+	if this.timer != nil {
+		this.timer.Stop()
+	}
+	this.cond.Broadcast()
+}
+
+func (this *pipeBuffer) setDeadline(t time.Time) {
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+	this.deadline = t
+	if this.timer != nil {
+		this.timer.Stop()
+		this.timer = nil
+	}
+	if !t.IsZero() {
+		// wake up any blocked reader once the deadline passes
+		this.timer = time.AfterFunc(time.Until(t), func() {
+			this.mtx.Lock()
+			defer this.mtx.Unlock()
+			this.cond.Broadcast()
+		})
+	}
+	this.cond.Broadcast()
+}
+
+// PipeConn is one end of an in-process, buffered, full duplex connection.
+type PipeConn struct {
+	rx     *pipeBuffer
+	tx     *pipeBuffer
+	local  net.Addr
+	remote net.Addr
+}
+
+// NewPipe creates a pair of connected PipeConn, reporting the given addresses.
+func NewPipe(addrA, addrB net.Addr) (*PipeConn, *PipeConn) {
+	ab := newPipeBuffer()
+	ba := newPipeBuffer()
+	a := &PipeConn{rx: ba, tx: ab, local: addrA, remote: addrB}
+	b := &PipeConn{rx: ab, tx: ba, local: addrB, remote: addrA}
+	return a, b
+}
+
+// Read reads data sent by the other end of the pipe.
+func (this *PipeConn) Read(data []byte) (int, error) {
+	return this.rx.read(data)
+}
+
+// Write sends data to the other end of the pipe, it never blocks.
+func (this *PipeConn) Write(data []byte) (int, error) {
+	return this.tx.write(data)
+}
+
+// Close closes both directions of the pipe.
+func (this *PipeConn) Close() error {
+	this.rx.close()
+	this.tx.close()
+	return nil
+}
+
+func (this *PipeConn) LocalAddr() net.Addr {
+	return this.local
+}
+
+func (this *PipeConn) RemoteAddr() net.Addr {
+	return this.remote
+}
+
+func (this *PipeConn) SetDeadline(t time.Time) error {
+	return this.SetReadDeadline(t)
+}
+
+func (this *PipeConn) SetReadDeadline(t time.Time) error {
+	this.rx.setDeadline(t)
+	return nil
+}
+
+// SetWriteDeadline is a no-op as writes to a pipe never block.
+func (this *PipeConn) SetWriteDeadline(t time.Time) error {
+	return nil
+}
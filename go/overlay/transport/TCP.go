@@ -0,0 +1,40 @@
+// © 2025 Sharon Aicler (saichler@gmail.com)
+//
+// Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transport
+
+import (
+	"net"
+
+	"github.com/saichler/l8types/go/ifs"
+	"github.com/saichler/l8utils/go/utils/strings"
+)
+
+// TCP is the socket based transport, dialing through the security provider.
+type TCP struct {
+}
+
+// NewTCP creates a new TCP transport.
+func NewTCP() *TCP {
+	return &TCP{}
+}
+
+// Listen binds a TCP listener on all interfaces for the given port.
+func (this *TCP) Listen(port uint32) (net.Listener, error) {
+	return net.Listen("tcp", strings.New(":", int(port)).String())
+}
+
+// Dial connects to host:port using the security provider.
+func (this *TCP) Dial(host string, port uint32, security ifs.ISecurityProvider) (net.Conn, error) {
+	return security.CanDial(host, port)
+}
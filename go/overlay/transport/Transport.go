@@ -0,0 +1,47 @@
+// © 2025 Sharon Aicler (saichler@gmail.com)
+//
+// Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transport
+
+import (
+	"net"
+	"sync"
+
+	"github.com/saichler/l8types/go/ifs"
+)
+
+// Transport abstracts how a VNet accepts connections and how a VNic or a peer VNet
+// dials them. The default transport is TCP, tests may replace it with an in-process one.
+type Transport interface {
+	// Listen binds the transport to the given vnet port.
+	Listen(port uint32) (net.Listener, error)
+	// Dial connects to the vnet listening on host:port.
+	Dial(host string, port uint32, security ifs.ISecurityProvider) (net.Conn, error)
+}
+
+var defaultTransport Transport = NewTCP()
+var defaultMtx = &sync.RWMutex{}
+
+// Default returns the transport used by newly created VNets and VNics.
+func Default() Transport {
+	defaultMtx.RLock()
+	defer defaultMtx.RUnlock()
+	return defaultTransport
+}
+
+// SetDefault replaces the transport used by newly created VNets and VNics.
+func SetDefault(transport Transport) {
+	defaultMtx.Lock()
+	defer defaultMtx.Unlock()
+	defaultTransport = transport
+}
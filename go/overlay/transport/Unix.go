@@ -0,0 +1,148 @@
+// © 2025 Sharon Aicler (saichler@gmail.com)
+//
+// Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transport
+
+import (
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+
+	"github.com/saichler/l8types/go/ifs"
+	"github.com/saichler/l8utils/go/utils/strings"
+)
+
+// UnixSocket_Enabled controls whether a TCP VNet also listens on a unix domain socket,
+// and whether VNics on the same host prefer it over TCP.
+var UnixSocket_Enabled = true
+
+// UnixSocketDir is the directory where the VNet unix domain sockets are created. It must be
+// owned by the user running the VNet, or by root, and not writable by others, otherwise
+// another local user could replace the sockets. By default it is private to the user.
+var UnixSocketDir = filepath.Join(os.TempDir(), strings.New("l8bus-", os.Getuid()).String())
+
+// UnixSocketPath returns the unix domain socket path of the VNet listening on the given port.
+func UnixSocketPath(port uint32) string {
+	return filepath.Join(UnixSocketDir, strings.New("l8bus-", int(port), ".sock").String())
+}
+
+// Unix is the unix domain socket transport for VNic to VNet links on the same host.
+type Unix struct {
+}
+
+// NewUnix creates a new unix domain socket transport.
+func NewUnix() *Unix {
+	return &Unix{}
+}
+
+// Listen binds the unix domain socket for the given vnet port, removing a stale socket file
+// left by a previous process. The vnet tcp port is bound first, so the file is never in use.
+func (this *Unix) Listen(port uint32) (net.Listener, error) {
+	err := os.MkdirAll(UnixSocketDir, 0750)
+	if err != nil {
+		return nil, err
+	}
+	if !trustedDir(UnixSocketDir) {
+		return nil, errors.New(strings.New("Unix socket directory ", UnixSocketDir,
+			" is not owned by this user or is writable by others").String())
+	}
+	path := UnixSocketPath(port)
+	os.Remove(path)
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	err = os.Chmod(path, 0660)
+	if err != nil {
+		listener.Close()
+		return nil, err
+	}
+	return listener, nil
+}
+
+// Dial connects to the unix domain socket of the vnet listening on the given port. The host
+// must be this host, as the socket of a remote vnet is not reachable.
+func (this *Unix) Dial(host string, port uint32, security ifs.ISecurityProvider) (net.Conn, error) {
+	if !IsLocalHost(host) {
+		return nil, errors.New(strings.New("Host ", host, " is not this host").String())
+	}
+	if !trustedDir(UnixSocketDir) {
+		return nil, errors.New(strings.New("Unix socket directory ", UnixSocketDir, " is not trusted").String())
+	}
+	return net.Dial("unix", UnixSocketPath(port))
+}
+
+// PreferLocal returns true if a VNic dialing the host through the given transport should use
+// the unix domain socket of the vnet on the given port, e.g. the host resolves to this host
+// and the socket exists in the trusted socket directory.
+func PreferLocal(t Transport, host string, port uint32) bool {
+	if !UnixSocket_Enabled {
+		return false
+	}
+	if _, ok := t.(*TCP); !ok {
+		return false
+	}
+	if !IsLocalHost(host) || !trustedDir(UnixSocketDir) {
+		return false
+	}
+	info, err := os.Lstat(UnixSocketPath(port))
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeSocket != 0
+}
+
+// IsLocalHost returns true if every address the host resolves to belongs to this host.
+func IsLocalHost(host string) bool {
+	ips, err := net.LookupIP(host)
+	if err != nil || len(ips) == 0 {
+		return false
+	}
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return false
+	}
+	for _, ip := range ips {
+		if !ip.IsLoopback() && !hasAddress(addrs, ip) {
+			return false
+		}
+	}
+	return true
+}
+
+func hasAddress(addrs []net.Addr, ip net.IP) bool {
+	for _, addr := range addrs {
+		ipNet, ok := addr.(*net.IPNet)
+		if ok && ipNet.IP.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// trustedDir returns true if the directory is a real directory, owned by this user or by root
+// and not writable by others, so the sockets in it were created by a trusted vnet.
+func trustedDir(dir string) bool {
+	info, err := os.Lstat(dir)
+	if err != nil || !info.IsDir() || info.Mode().Perm()&0022 != 0 {
+		return false
+	}
+	return ownedByUser(info)
+}
+
+// IsLocalLink returns true if the connection is a same host link, e.g. a unix domain socket.
+func IsLocalLink(conn net.Conn) bool {
+	_, ok := conn.(*net.UnixConn)
+	return ok
+}
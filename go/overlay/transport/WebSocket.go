@@ -0,0 +1,131 @@
+// © 2025 Sharon Aicler (saichler@gmail.com)
+//
+// Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transport
+
+import (
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/saichler/l8types/go/ifs"
+	"github.com/saichler/l8utils/go/utils/strings"
+)
+
+// WebSocket is a dial only transport connecting to the websocket endpoint of a VNet,
+// for vnics that can only reach the VNet through http proxies or load balancers.
+type WebSocket struct {
+	path string
+}
+
+// NewWebSocket creates a websocket transport dialing the given endpoint path, e.g. /bus.
+func NewWebSocket(path string) *WebSocket {
+	return &WebSocket{path: path}
+}
+
+// Listen is not supported, the VNet serves websockets via StartWebSocket.
+func (this *WebSocket) Listen(port uint32) (net.Listener, error) {
+	return nil, errors.New("WebSocket transport does not support listening")
+}
+
+// Dial connects to the websocket endpoint on the given host & port.
+func (this *WebSocket) Dial(host string, port uint32, security ifs.ISecurityProvider) (net.Conn, error) {
+	url := strings.New("ws://", host, ":", int(port), this.path).String()
+	ws, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return NewWebSocketConn(ws), nil
+}
+
+// WebSocketConn adapts a websocket to a net.Conn, so the regular framing and handshake
+// can run over it. Every Write is sent as one binary message and reads stream the
+// binary messages back to back, text messages are ignored.
+type WebSocketConn struct {
+	ws     *websocket.Conn
+	reader io.Reader
+	rMtx   *sync.Mutex
+	wMtx   *sync.Mutex
+}
+
+// NewWebSocketConn wraps the websocket as a net.Conn.
+func NewWebSocketConn(ws *websocket.Conn) *WebSocketConn {
+	return &WebSocketConn{ws: ws, rMtx: &sync.Mutex{}, wMtx: &sync.Mutex{}}
+}
+
+func (this *WebSocketConn) Read(data []byte) (int, error) {
+	this.rMtx.Lock()
+	defer this.rMtx.Unlock()
+	for {
+		if this.reader == nil {
+			messageType, reader, err := this.ws.NextReader()
+			if err != nil {
+				return 0, err
+			}
+			if messageType != websocket.BinaryMessage {
+				continue
+			}
+			this.reader = reader
+		}
+		n, err := this.reader.Read(data)
+		if err == io.EOF {
+			this.reader = nil
+			if n > 0 {
+				return n, nil
+			}
+			continue
+		}
+		return n, err
+	}
+}
+
+func (this *WebSocketConn) Write(data []byte) (int, error) {
+	this.wMtx.Lock()
+	defer this.wMtx.Unlock()
+	err := this.ws.WriteMessage(websocket.BinaryMessage, data)
+	if err != nil {
+		return 0, err
+	}
+	return len(data), nil
+}
+
+func (this *WebSocketConn) Close() error {
+	return this.ws.Close()
+}
+
+func (this *WebSocketConn) LocalAddr() net.Addr {
+	return this.ws.LocalAddr()
+}
+
+func (this *WebSocketConn) RemoteAddr() net.Addr {
+	return this.ws.RemoteAddr()
+}
+
+func (this *WebSocketConn) SetDeadline(t time.Time) error {
+	err := this.ws.SetReadDeadline(t)
+	if err != nil {
+		return err
+	}
+	return this.ws.SetWriteDeadline(t)
+}
+
+func (this *WebSocketConn) SetReadDeadline(t time.Time) error {
+	return this.ws.SetReadDeadline(t)
+}
+
+func (this *WebSocketConn) SetWriteDeadline(t time.Time) error {
+	return this.ws.SetWriteDeadline(t)
+}
@@ -0,0 +1,235 @@
+// © 2025 Sharon Aicler (saichler@gmail.com)
+//
+// Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vnet
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/saichler/l8bus/go/overlay/health"
+	"github.com/saichler/l8bus/go/overlay/protocol"
+	"github.com/saichler/l8srlz/go/serialize/object"
+	"github.com/saichler/l8types/go/ifs"
+	"github.com/saichler/l8utils/go/utils/strings"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// AdminServiceName is the service answering with the switch table of the VNet
+const AdminServiceName = "VNetAdmin"
+
+// SwitchTableInfo is the state of a VNet as returned by the admin service
+type SwitchTableInfo struct {
+	Uuid          string
+	Alias         string
+	Internal      []*ConnectionInfo
+	ExternalVnets []*ConnectionInfo
+	ExternalVnics []*ConnectionInfo
+	Routes        map[string]string
+	Services      []*ServiceInfo
+	Queues        map[string]int
+	Health        []*HealthInfo
+}
+
+// ConnectionInfo is a connection of the VNet, queue depths are the messages waiting to be
+// written to, or read from, the connection. Version & Agreed are what was negotiated with
+// the peer, version 0 if it did not send a hello.
+type ConnectionInfo struct {
+	Uuid    string
+	Alias   string
+	Address string
+	Running bool
+	TxQueue int
+	RxQueue int
+	Version int
+	Agreed  []protocol.Capability
+}
+
+// ServiceInfo is a service area, the instances providing it and the leader the VNet selects
+type ServiceInfo struct {
+	Name   string
+	Area   byte
+	Uuids  []string
+	Leader string
+}
+
+// HealthInfo is a node known to the health service
+type HealthInfo struct {
+	Uuid   string
+	Alias  string
+	IsVnet bool
+}
+
+// queueDepths is implemented by the vnics that report their queue depths
+type queueDepths interface {
+	QueueDepths() (int, int)
+}
+
+// peerOf is a connection that negotiated with its peer
+type peerOf interface {
+	Peer() *protocol.Peer
+}
+
+// SwitchTable returns the current state of the VNet connections, routes, services,
+// task queues and health.
+func (this *VNet) SwitchTable() *SwitchTableInfo {
+	info := &SwitchTableInfo{Uuid: this.vnetUuid, Alias: this.resources.SysConfig().LocalAlias}
+	conns := this.switchTable.conns
+	info.Internal = connectionsInfo(conns.internal)
+	info.ExternalVnets = connectionsInfo(conns.externalVnet)
+	info.ExternalVnics = connectionsInfo(conns.externalVnic)
+
+	info.Routes = make(map[string]string)
+	this.switchTable.routeTable.routes.Range(func(key, value interface{}) bool {
+		info.Routes[key.(string)] = value.(string)
+		return true
+	})
+
+	this.switchTable.services.services.Range(func(key, value interface{}) bool {
+		name := key.(string)
+		value.(*sync.Map).Range(func(key, value interface{}) bool {
+			service := &ServiceInfo{Name: name, Area: key.(byte), Uuids: make([]string, 0)}
+			value.(*sync.Map).Range(func(key, value interface{}) bool {
+				service.Uuids = append(service.Uuids, key.(string))
+				return true
+			})
+			sort.Strings(service.Uuids)
+			service.Leader = this.ServiceLeader(name, service.Area)
+			info.Services = append(info.Services, service)
+			return true
+		})
+		return true
+	})
+	sort.Slice(info.Services, func(i, j int) bool {
+		if info.Services[i].Name != info.Services[j].Name {
+			return info.Services[i].Name < info.Services[j].Name
+		}
+		return info.Services[i].Area < info.Services[j].Area
+	})
+
+	info.Queues = map[string]int{
+		"system":       this.vnetSystemTasks.Size(),
+		"service":      this.vnetServiceTasks.Size(),
+		"handleData":   this.handleDataTasks.Size(),
+		"healthReport": this.healthReport.Size(),
+	}
+
+	for uuid, hp := range health.All(this.resources) {
+		info.Health = append(info.Health, &HealthInfo{Uuid: uuid, Alias: hp.Alias, IsVnet: hp.IsVnet})
+	}
+	sort.Slice(info.Health, func(i, j int) bool {
+		return info.Health[i].Alias < info.Health[j].Alias
+	})
+	return info
+}
+
+func connectionsInfo(conns *sync.Map) []*ConnectionInfo {
+	result := make([]*ConnectionInfo, 0)
+	conns.Range(func(key, value interface{}) bool {
+		vnic := value.(ifs.IVNic)
+		config := vnic.Resources().SysConfig()
+		conn := &ConnectionInfo{Uuid: key.(string), Alias: config.RemoteAlias, Address: config.Address, Running: vnic.Running()}
+		depths, ok := vnic.(queueDepths)
+		if ok {
+			conn.TxQueue, conn.RxQueue = depths.QueueDepths()
+		}
+		negotiated, ok := vnic.(peerOf)
+		if ok {
+			peer := negotiated.Peer()
+			if peer != nil {
+				conn.Version, conn.Agreed = peer.Version, peer.Agreed
+			}
+		}
+		result = append(result, conn)
+		return true
+	})
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Alias < result[j].Alias
+	})
+	return result
+}
+
+// SwitchTableFrom decodes the response of the admin service.
+func SwitchTableFrom(resp ifs.IElements) (*SwitchTableInfo, error) {
+	if resp == nil {
+		return nil, errors.New(strings.New("No response from ", AdminServiceName, " service").String())
+	}
+	if resp.Error() != nil {
+		return nil, resp.Error()
+	}
+	data, ok := resp.Element().(*wrapperspb.BytesValue)
+	if !ok {
+		return nil, errors.New(strings.New("Unexpected ", AdminServiceName, " response type").String())
+	}
+	info := &SwitchTableInfo{}
+	err := json.Unmarshal(data.Value, info)
+	if err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// AdminService answers a Get with the json of the VNet switch table, wrapped in a
+// BytesValue.
+type AdminService struct {
+	vnet *VNet
+}
+
+// Activate registers the response type with the registry when the service starts.
+func (this *AdminService) Activate(sla *ifs.ServiceLevelAgreement, vnic ifs.IVNic) error {
+	vnic.Resources().Registry().Register(&wrapperspb.BytesValue{})
+	return nil
+}
+
+// DeActivate is called when the service is stopped.
+func (this *AdminService) DeActivate() error {
+	return nil
+}
+
+func (this *AdminService) Post(pb ifs.IElements, vnic ifs.IVNic) ifs.IElements {
+	return nil
+}
+func (this *AdminService) Put(pb ifs.IElements, vnic ifs.IVNic) ifs.IElements {
+	return nil
+}
+func (this *AdminService) Patch(pb ifs.IElements, vnic ifs.IVNic) ifs.IElements {
+	return nil
+}
+func (this *AdminService) Delete(pb ifs.IElements, vnic ifs.IVNic) ifs.IElements {
+	return nil
+}
+func (this *AdminService) GetCopy(pb ifs.IElements, vnic ifs.IVNic) ifs.IElements {
+	return nil
+}
+
+// Get returns the switch table of the VNet.
+func (this *AdminService) Get(pb ifs.IElements, vnic ifs.IVNic) ifs.IElements {
+	data, err := json.Marshal(this.vnet.SwitchTable())
+	if err != nil {
+		return object.NewError(err.Error())
+	}
+	return object.New(nil, &wrapperspb.BytesValue{Value: data})
+}
+func (this *AdminService) Failed(pb ifs.IElements, vnic ifs.IVNic, msg *ifs.Message) ifs.IElements {
+	return nil
+}
+
+func (this *AdminService) TransactionConfig() ifs.ITransactionConfig {
+	return nil
+}
+
+func (this *AdminService) WebService() ifs.IWebService {
+	return nil
+}
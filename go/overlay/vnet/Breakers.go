@@ -0,0 +1,168 @@
+// © 2025 Sharon Aicler (saichler@gmail.com)
+//
+// Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vnet
+
+import (
+	"sync"
+	"time"
+
+	"github.com/saichler/l8bus/go/overlay/metrics"
+	"github.com/saichler/l8types/go/ifs"
+)
+
+// defaultRequestTimeout is used for requests routed by the vnet that do not carry a timeout
+const defaultRequestTimeout = 15
+
+// ServiceBreakers tracks the requests the vnet routes to a service instance it selected,
+// e.g. round robin & proximity requests, against a circuit breaker per service, area & instance.
+// A request is a success when its reply passes back through the vnet and a failure when it
+// times out, instances with an open circuit breaker are skipped by the service selection.
+type ServiceBreakers struct {
+	manager *metrics.CircuitBreakerManager
+	// requester uuid -> *sync.Map of message sequence -> *pendingRequest
+	pending *sync.Map
+	// guards adding & removing the requester maps of pending
+	mtx *sync.Mutex
+}
+
+type pendingRequest struct {
+	breaker  *metrics.CircuitBreaker
+	deadline time.Time
+}
+
+func newServiceBreakers(manager *metrics.CircuitBreakerManager) *ServiceBreakers {
+	return &ServiceBreakers{manager: manager, pending: &sync.Map{}, mtx: &sync.Mutex{}}
+}
+
+// isOpen returns true if the circuit breaker of the service instance is open.
+func (this *ServiceBreakers) isOpen(serviceName string, serviceArea byte, uuid string) bool {
+	return this.manager.IsOpen(serviceName, serviceArea, uuid)
+}
+
+// track registers a request routed to the selected service instance.
+func (this *ServiceBreakers) track(msg *ifs.Message, destination string) {
+	if !msg.Request() {
+		return
+	}
+	timeout := int64(defaultRequestTimeout)
+	if msg.Tr_Timeout() > 0 {
+		timeout = msg.Tr_Timeout()
+	}
+	pending := &pendingRequest{
+		breaker:  this.manager.ForService(msg.ServiceName(), msg.ServiceArea(), destination),
+		deadline: time.Now().Add(time.Second * time.Duration(timeout)),
+	}
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+	requests, _ := this.pending.LoadOrStore(msg.Source(), &sync.Map{})
+	requests.(*sync.Map).Store(msg.Sequence(), pending)
+}
+
+// expecting returns true if there are tracked requests from the destination, e.g. a message
+// to it may be the reply of a tracked request.
+func (this *ServiceBreakers) expecting(destination string) bool {
+	_, ok := this.pending.Load(destination)
+	return ok
+}
+
+// replied marks the tracked request of the reply as a success.
+func (this *ServiceBreakers) replied(msg *ifs.Message, destination string) {
+	if !msg.Reply() {
+		return
+	}
+	requests, ok := this.pending.Load(destination)
+	if !ok {
+		return
+	}
+	pending, ok := requests.(*sync.Map).LoadAndDelete(msg.Sequence())
+	if !ok {
+		return
+	}
+	if msg.FailMessage() != "" {
+		pending.(*pendingRequest).breaker.RecordFailure()
+		return
+	}
+	pending.(*pendingRequest).breaker.RecordSuccess()
+}
+
+// expire marks the tracked requests that passed their deadline as failures. A requester
+// with no tracked requests left is removed under the lock, so a request tracked meanwhile
+// is not removed with it.
+func (this *ServiceBreakers) expire() {
+	now := time.Now()
+	this.pending.Range(func(source, value interface{}) bool {
+		requests := value.(*sync.Map)
+		requests.Range(func(sequence, value interface{}) bool {
+			pending := value.(*pendingRequest)
+			if now.After(pending.deadline) {
+				requests.Delete(sequence)
+				pending.breaker.RecordFailure()
+			}
+			return true
+		})
+		this.mtx.Lock()
+		empty := true
+		requests.Range(func(sequence, value interface{}) bool {
+			empty = false
+			return false
+		})
+		if empty {
+			this.pending.Delete(source)
+		}
+		this.mtx.Unlock()
+		return true
+	})
+}
+
+// remove removes the circuit breakers of the service instances on the disconnected uuid.
+func (this *ServiceBreakers) remove(uuid string) {
+	this.manager.RemoveDestination(uuid)
+}
+
+// expireRequests periodically expires the tracked requests while the vnet is running.
+func (this *VNet) expireRequests() {
+	for this.running {
+		time.Sleep(time.Second)
+		this.breakers.expire()
+	}
+}
+
+// trackRequest tracks a request the vnet routed to a service instance it selected.
+func (this *VNet) trackRequest(data []byte, destination string, mode ifs.MulticastMode) {
+	if mode != ifs.M_RoundRobin && mode != ifs.M_Proximity {
+		return
+	}
+	msg, err := this.protocol.MessageOf(data)
+	if err != nil {
+		return
+	}
+	this.breakers.track(msg, destination)
+}
+
+// trackReply completes a tracked request if the message is its reply.
+func (this *VNet) trackReply(data []byte, destination string) {
+	if !this.breakers.expecting(destination) {
+		return
+	}
+	msg, err := this.protocol.MessageOf(data)
+	if err != nil {
+		return
+	}
+	this.breakers.replied(msg, destination)
+}
+
+// CircuitBreakers returns the circuit breakers of this vnet, shared with its ports.
+func (this *VNet) CircuitBreakers() *metrics.CircuitBreakerManager {
+	return this.breakers.manager
+}
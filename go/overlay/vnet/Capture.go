@@ -0,0 +1,55 @@
+// © 2025 Sharon Aicler (saichler@gmail.com)
+//
+// Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vnet
+
+import (
+	"github.com/saichler/l8bus/go/overlay/capture"
+	"github.com/saichler/l8types/go/ifs"
+)
+
+// StartCapture records the frames the VNet switches into the capture file, replacing
+// a running capture. A nil filter captures all the frames.
+func (this *VNet) StartCapture(filename string, filter *capture.Filter) error {
+	writer, err := capture.NewWriter(filename, filter)
+	if err != nil {
+		return err
+	}
+	previous := this.captureWriter.Swap(writer)
+	if previous != nil {
+		previous.Close()
+	}
+	return nil
+}
+
+// StopCapture stops the running capture and closes its file.
+func (this *VNet) StopCapture() error {
+	writer := this.captureWriter.Swap(nil)
+	if writer != nil {
+		return writer.Close()
+	}
+	return nil
+}
+
+// captureFrame records a frame received from the vnic when a capture is running, the
+// connection is the uuid of the vnic peer.
+func (this *VNet) captureFrame(data []byte, vnic ifs.IVNic) {
+	writer := this.captureWriter.Load()
+	if writer == nil {
+		return
+	}
+	err := writer.Capture(capture.In, vnic.Resources().SysConfig().RemoteUuid, data)
+	if err != nil {
+		this.resources.Logger().Error("Failed to capture frame: ", err.Error())
+	}
+}
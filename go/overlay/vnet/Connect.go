@@ -25,7 +25,7 @@ import (
 func (this *VNet) ConnectNetworks(host string, destPort uint32) error {
 	sec := this.resources.Security()
 	// Dial the destination and validate the secret and key
-	conn, err := sec.CanDial(host, destPort)
+	conn, err := this.transport.Dial(host, destPort, sec)
 	if err != nil {
 		return err
 	}
@@ -47,6 +47,8 @@ func (this *VNet) ConnectNetworks(host string, destPort uint32) error {
 	resources.Set(this)
 
 	vnic := vnic2.NewVirtualNetworkInterface(resources, conn)
+	vnic.SetTransport(this.transport)
+	vnic.SetCapabilities(this.Capabilities())
 
 	err = sec.ValidateConnection(conn, config)
 	if err != nil {
@@ -54,7 +56,12 @@ func (this *VNet) ConnectNetworks(host string, destPort uint32) error {
 	}
 
 	vnic.Start()
-	this.addHealthForVNic(vnic.Resources().SysConfig())
+	this.addHealthForVNic(vnic.Resources().SysConfig(), false)
 	this.notifyNewVNic(vnic)
+	// the dialing VNet starts the negotiation, the remote VNet answers on this connection
+	err = vnic.SendHello(false)
+	if err != nil {
+		this.resources.Logger().Error("Failed to send hello: ", err.Error())
+	}
 	return nil
 }
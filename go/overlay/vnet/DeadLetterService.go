@@ -0,0 +1,168 @@
+// © 2025 Sharon Aicler (saichler@gmail.com)
+//
+// Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vnet
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/saichler/l8srlz/go/serialize/object"
+	"github.com/saichler/l8types/go/ifs"
+	"github.com/saichler/l8utils/go/utils/strings"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// DeadLetterServiceName is the service of the VNet dead letters, a Get lists them, a Post
+// redrives them and a Delete purges them. Each takes a DeadLetterQuery json wrapped in a
+// BytesValue and answers with a DeadLetterResult, once the security provider allowed the
+// action to the requesting node.
+const DeadLetterServiceName = "VNetDLQ"
+
+// authorized checks the requests of the dead letter service with the security provider,
+// as its letters are the traffic of other nodes and a redrive resends them as their
+// source.
+func (this *VNet) authorized(serviceName string, action ifs.Action, pb ifs.IElements, source, token string) error {
+	if serviceName != DeadLetterServiceName {
+		return nil
+	}
+	err := this.resources.Security().CanDoAction(action, pb, source, token)
+	if err != nil {
+		return errors.New(strings.New(DeadLetterServiceName, " request from ", source, " denied: ", err.Error()).String())
+	}
+	return nil
+}
+
+// DeadLetterRequest sends the query to the dead letter service of a VNet and decodes its
+// answer.
+func DeadLetterRequest(vnic ifs.IVNic, vnetUuid string, action ifs.Action, query *DeadLetterQuery, timeoutSeconds int) (*DeadLetterResult, error) {
+	if query == nil {
+		query = &DeadLetterQuery{}
+	}
+	data, err := json.Marshal(query)
+	if err != nil {
+		return nil, err
+	}
+	resp := vnic.Request(vnetUuid, DeadLetterServiceName, 0, action, &wrapperspb.BytesValue{Value: data}, timeoutSeconds)
+	return DeadLetterResultFrom(resp)
+}
+
+// DeadLetterResultFrom decodes the response of the dead letter service.
+func DeadLetterResultFrom(resp ifs.IElements) (*DeadLetterResult, error) {
+	if resp == nil {
+		return nil, errors.New(strings.New("No response from ", DeadLetterServiceName, " service").String())
+	}
+	if resp.Error() != nil {
+		return nil, resp.Error()
+	}
+	data, ok := resp.Element().(*wrapperspb.BytesValue)
+	if !ok {
+		return nil, errors.New(strings.New("Unexpected ", DeadLetterServiceName, " response type").String())
+	}
+	result := &DeadLetterResult{}
+	err := json.Unmarshal(data.Value, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// deadLetterQueryOf decodes the query of a request, no query selects all the letters
+func deadLetterQueryOf(pb ifs.IElements) (*DeadLetterQuery, error) {
+	query := &DeadLetterQuery{}
+	if pb == nil {
+		return query, nil
+	}
+	data, ok := pb.Element().(*wrapperspb.BytesValue)
+	if !ok || len(data.Value) == 0 {
+		return query, nil
+	}
+	err := json.Unmarshal(data.Value, query)
+	if err != nil {
+		return nil, err
+	}
+	return query, nil
+}
+
+// deadLetterAnswer wraps the result json in a BytesValue
+func deadLetterAnswer(result *DeadLetterResult) ifs.IElements {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return object.NewError(err.Error())
+	}
+	return object.New(nil, &wrapperspb.BytesValue{Value: data})
+}
+
+// DeadLetterService lists, redrives & purges the dead letters of the VNet.
+type DeadLetterService struct {
+	vnet *VNet
+}
+
+// Activate registers the request & response type with the registry when the service starts.
+func (this *DeadLetterService) Activate(sla *ifs.ServiceLevelAgreement, vnic ifs.IVNic) error {
+	vnic.Resources().Registry().Register(&wrapperspb.BytesValue{})
+	return nil
+}
+
+// DeActivate is called when the service is stopped.
+func (this *DeadLetterService) DeActivate() error {
+	return nil
+}
+
+// Post redrives the dead letters matching the query.
+func (this *DeadLetterService) Post(pb ifs.IElements, vnic ifs.IVNic) ifs.IElements {
+	query, err := deadLetterQueryOf(pb)
+	if err != nil {
+		return object.NewError(err.Error())
+	}
+	redriven, expired := this.vnet.Redrive(query)
+	return deadLetterAnswer(&DeadLetterResult{Count: redriven, Expired: expired})
+}
+func (this *DeadLetterService) Put(pb ifs.IElements, vnic ifs.IVNic) ifs.IElements {
+	return nil
+}
+func (this *DeadLetterService) Patch(pb ifs.IElements, vnic ifs.IVNic) ifs.IElements {
+	return nil
+}
+
+// Delete purges the dead letters matching the query.
+func (this *DeadLetterService) Delete(pb ifs.IElements, vnic ifs.IVNic) ifs.IElements {
+	query, err := deadLetterQueryOf(pb)
+	if err != nil {
+		return object.NewError(err.Error())
+	}
+	return deadLetterAnswer(&DeadLetterResult{Count: this.vnet.PurgeDeadLetters(query)})
+}
+func (this *DeadLetterService) GetCopy(pb ifs.IElements, vnic ifs.IVNic) ifs.IElements {
+	return nil
+}
+
+// Get lists the dead letters matching the query.
+func (this *DeadLetterService) Get(pb ifs.IElements, vnic ifs.IVNic) ifs.IElements {
+	query, err := deadLetterQueryOf(pb)
+	if err != nil {
+		return object.NewError(err.Error())
+	}
+	return deadLetterAnswer(this.vnet.DeadLetters(query))
+}
+func (this *DeadLetterService) Failed(pb ifs.IElements, vnic ifs.IVNic, msg *ifs.Message) ifs.IElements {
+	return nil
+}
+
+func (this *DeadLetterService) TransactionConfig() ifs.ITransactionConfig {
+	return nil
+}
+
+func (this *DeadLetterService) WebService() ifs.IWebService {
+	return nil
+}
@@ -0,0 +1,264 @@
+// © 2025 Sharon Aicler (saichler@gmail.com)
+//
+// Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vnet
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/saichler/l8bus/go/overlay/metrics"
+	"github.com/saichler/l8bus/go/overlay/protocol"
+	"github.com/saichler/l8utils/go/utils/strings"
+)
+
+// DefaultDeadLetterCapacity is the number of dead letters a VNet keeps and
+// DefaultDeadLetterMaxBytes the total size of their messages, the oldest are dropped
+// above either.
+const (
+	DefaultDeadLetterCapacity = 1024
+	DefaultDeadLetterMaxBytes = 16 * 1024 * 1024
+)
+
+// Dead letter reasons that are not the error of a send
+const (
+	ReasonNoInstances = "No instances of the service"
+)
+
+// DeadLetter is a message the VNet could not deliver, with the reason and the time it
+// failed. Data is the message bytes, returned only when asked for.
+type DeadLetter struct {
+	Id          int64  `json:"id"`
+	Time        int64  `json:"time"`
+	Reason      string `json:"reason"`
+	Source      string `json:"source,omitempty"`
+	Destination string `json:"destination,omitempty"`
+	ServiceName string `json:"serviceName,omitempty"`
+	ServiceArea byte   `json:"serviceArea"`
+	Size        int    `json:"size"`
+	Data        []byte `json:"data,omitempty"`
+}
+
+// DeadLetterQuery selects dead letters by id, by service name & by source, an empty query
+// selects all of them. Limit caps the number listed and WithData adds the message bytes.
+type DeadLetterQuery struct {
+	Ids         []int64 `json:"ids,omitempty"`
+	ServiceName string  `json:"serviceName,omitempty"`
+	Source      string  `json:"source,omitempty"`
+	Limit       int     `json:"limit,omitempty"`
+	WithData    bool    `json:"withData,omitempty"`
+}
+
+// DeadLetterResult is the answer of the dead letter service, Letters are the listed dead
+// letters, Count the number redriven or purged and Dropped the number evicted when the
+// store was full.
+type DeadLetterResult struct {
+	Letters []*DeadLetter `json:"letters,omitempty"`
+	Count   int           `json:"count"`
+	Expired int           `json:"expired,omitempty"`
+	Dropped int64         `json:"dropped"`
+}
+
+// DeadLetters is the bounded store of the dead letters of a VNet, a ring of the letters
+// oldest first, bounded by their number and the total size of their messages.
+type DeadLetters struct {
+	mtx      *sync.Mutex
+	ring     []*DeadLetter
+	head     int
+	count    int
+	bytes    int
+	maxBytes int
+	nextId   int64
+	dropped  int64
+}
+
+func newDeadLetters(capacity, maxBytes int) *DeadLetters {
+	return &DeadLetters{mtx: &sync.Mutex{}, ring: make([]*DeadLetter, capacity), maxBytes: maxBytes}
+}
+
+// add keeps a copy of the message with the reason it was not delivered
+func (this *DeadLetters) add(data []byte, reason string) *DeadLetter {
+	letter := &DeadLetter{Time: time.Now().UnixMilli(), Reason: reason, Size: len(data),
+		Data: append([]byte{}, data...)}
+	header, err := protocol.HeaderOf(data)
+	if err == nil {
+		letter.Source, letter.Destination = header.Source, header.Destination
+		letter.ServiceName, letter.ServiceArea = header.ServiceName, header.ServiceArea
+	}
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+	this.nextId++
+	letter.Id = this.nextId
+	if len(this.ring) == 0 || letter.Size > this.maxBytes {
+		this.dropped++
+		return letter
+	}
+	if this.count == len(this.ring) {
+		this.evict()
+	}
+	this.ring[(this.head+this.count)%len(this.ring)] = letter
+	this.count++
+	this.bytes += letter.Size
+	for this.bytes > this.maxBytes {
+		this.evict()
+	}
+	return letter
+}
+
+// evict drops the oldest letter
+func (this *DeadLetters) evict() {
+	this.bytes -= this.ring[this.head].Size
+	this.ring[this.head] = nil
+	this.head = (this.head + 1) % len(this.ring)
+	this.count--
+	this.dropped++
+}
+
+// letters returns the letters oldest first
+func (this *DeadLetters) letters() []*DeadLetter {
+	letters := make([]*DeadLetter, 0, this.count)
+	for i := 0; i < this.count; i++ {
+		letters = append(letters, this.ring[(this.head+i)%len(this.ring)])
+	}
+	return letters
+}
+
+// reset refills a ring of the capacity with the newest of the letters, oldest first,
+// that fit the capacity & the max bytes, the others are dropped
+func (this *DeadLetters) reset(letters []*DeadLetter, capacity int) {
+	start, size := len(letters), 0
+	for start > 0 && len(letters)-start < capacity && size+letters[start-1].Size <= this.maxBytes {
+		start--
+		size += letters[start].Size
+	}
+	this.dropped += int64(start)
+	this.ring, this.head, this.count, this.bytes = make([]*DeadLetter, capacity), 0, 0, size
+	for _, letter := range letters[start:] {
+		this.ring[this.count] = letter
+		this.count++
+	}
+}
+
+// list returns the dead letters matching the query, without their data unless asked for
+func (this *DeadLetters) list(query *DeadLetterQuery) *DeadLetterResult {
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+	result := &DeadLetterResult{Letters: make([]*DeadLetter, 0), Dropped: this.dropped}
+	for _, letter := range this.letters() {
+		if !query.match(letter) {
+			continue
+		}
+		if query.Limit > 0 && len(result.Letters) == query.Limit {
+			break
+		}
+		listed := *letter
+		if !query.WithData {
+			listed.Data = nil
+		}
+		result.Letters = append(result.Letters, &listed)
+	}
+	result.Count = len(result.Letters)
+	return result
+}
+
+// remove takes the dead letters matching the query out of the store and returns them
+func (this *DeadLetters) remove(query *DeadLetterQuery) []*DeadLetter {
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+	removed := make([]*DeadLetter, 0)
+	kept := make([]*DeadLetter, 0, this.count)
+	for _, letter := range this.letters() {
+		if query.match(letter) {
+			removed = append(removed, letter)
+		} else {
+			kept = append(kept, letter)
+		}
+	}
+	this.reset(kept, len(this.ring))
+	return removed
+}
+
+// setLimits changes the capacity & the max bytes, dropping the oldest letters above them
+func (this *DeadLetters) setLimits(capacity, maxBytes int) error {
+	if capacity < 0 || maxBytes < 0 {
+		return errors.New(strings.New("Invalid dead letter limits, capacity ", capacity, " max bytes ", maxBytes).String())
+	}
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+	this.maxBytes = maxBytes
+	this.reset(this.letters(), capacity)
+	return nil
+}
+
+func (this *DeadLetterQuery) match(letter *DeadLetter) bool {
+	if this == nil {
+		return true
+	}
+	if this.ServiceName != "" && this.ServiceName != letter.ServiceName {
+		return false
+	}
+	if this.Source != "" && this.Source != letter.Source {
+		return false
+	}
+	if len(this.Ids) == 0 {
+		return true
+	}
+	for _, id := range this.Ids {
+		if id == letter.Id {
+			return true
+		}
+	}
+	return false
+}
+
+// deadLetter keeps a message the VNet could not deliver and counts it
+func (this *VNet) deadLetter(data []byte, reason string) {
+	letter := this.deadLetters.add(data, reason)
+	metrics.GetGlobalRegistry(this.resources.Logger()).Counter("layer8_dead_letters_total",
+		map[string]string{"vnic_id": this.vnetUuid, "service": letter.ServiceName}).Inc()
+	this.resources.Logger().Debug("Dead letter ", letter.Id, " to ", letter.ServiceName, ": ", reason)
+}
+
+// SetDeadLetterLimits sets the number of dead letters the VNet keeps and the total size
+// of their messages, a negative limit is an error.
+func (this *VNet) SetDeadLetterLimits(capacity, maxBytes int) error {
+	return this.deadLetters.setLimits(capacity, maxBytes)
+}
+
+// DeadLetters lists the dead letters of the VNet matching the query
+func (this *VNet) DeadLetters(query *DeadLetterQuery) *DeadLetterResult {
+	return this.deadLetters.list(query)
+}
+
+// Redrive takes the dead letters matching the query out of the store and routes them
+// again, a letter that fails again becomes a new dead letter. A letter past its expiry
+// is dropped instead, as it would only expire again. It returns the number of letters
+// redriven and the number dropped as expired.
+func (this *VNet) Redrive(query *DeadLetterQuery) (int, int) {
+	letters := this.deadLetters.remove(query)
+	expired := 0
+	for _, letter := range letters {
+		if protocol.Expired(letter.Data) {
+			expired++
+			continue
+		}
+		this.addVnetTask(QHandleData, letter.Data, this.vnic)
+	}
+	return len(letters) - expired, expired
+}
+
+// PurgeDeadLetters drops the dead letters matching the query and returns their number
+func (this *VNet) PurgeDeadLetters(query *DeadLetterQuery) int {
+	return len(this.deadLetters.remove(query))
+}
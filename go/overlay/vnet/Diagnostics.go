@@ -0,0 +1,62 @@
+// © 2025 Sharon Aicler (saichler@gmail.com)
+//
+// Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vnet
+
+import (
+	"github.com/saichler/l8bus/go/overlay/protocol"
+	"github.com/saichler/l8srlz/go/serialize/object"
+	"github.com/saichler/l8types/go/types/l8health"
+)
+
+// isProbe returns true if the system message is a diagnostics probe
+func isProbe(data []byte) bool {
+	_, ok := protocol.ExtensionOf(data, protocol.Ext_Probe)
+	return ok
+}
+
+// probeReceived handles the diagnostics probes sent by the vnic Ping & Traceroute. The
+// hop limit of the probe is decremented, when it expires or when this vnet is the
+// destination the vnet answers with its alias & uuid. Otherwise the probe is returned
+// with the decremented limit to be routed as usual. It returns true if the probe was
+// answered.
+func (this *VNet) probeReceived(data []byte, destination string) ([]byte, bool) {
+	value, ok := protocol.ExtensionOf(data, protocol.Ext_Probe)
+	if !ok || len(value) != 1 {
+		return data, false
+	}
+	hopLimit := value[0]
+	if hopLimit > 0 {
+		hopLimit--
+	}
+	if hopLimit > 0 && destination != this.vnetUuid {
+		return protocol.WithExtension(data, protocol.Ext_Probe, []byte{hopLimit}), false
+	}
+	msg, err := this.protocol.MessageOf(data)
+	if err != nil {
+		this.resources.Logger().Error(err)
+		return data, true
+	}
+	if !msg.Request() {
+		return data, true
+	}
+	hp := &l8health.L8Health{}
+	hp.AUuid = this.vnetUuid
+	hp.Alias = this.resources.SysConfig().LocalAlias
+	hp.IsVnet = true
+	err = this.vnic.Reply(msg, object.New(nil, hp))
+	if err != nil {
+		this.resources.Logger().Error(err)
+	}
+	return data, true
+}
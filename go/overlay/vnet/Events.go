@@ -0,0 +1,109 @@
+// © 2025 Sharon Aicler (saichler@gmail.com)
+//
+// Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vnet
+
+import (
+	"time"
+
+	"github.com/saichler/l8bus/go/overlay/events"
+	"github.com/saichler/l8srlz/go/serialize/object"
+	"github.com/saichler/l8types/go/ifs"
+	"github.com/saichler/l8types/go/types/l8system"
+	"github.com/saichler/l8utils/go/utils/strings"
+)
+
+// Events returns the event bus of the VNet, for local subscribers to topology changes.
+func (this *VNet) Events() *events.EventBus {
+	return this.events
+}
+
+// publishEvent stamps the event with the VNet & time, delivers it to the local
+// subscribers and multicasts it to the nodes running the events service.
+func (this *VNet) publishEvent(event *events.Event) {
+	event.Vnet = this.vnetUuid
+	event.VnetAlias = this.resources.SysConfig().LocalAlias
+	event.Time = time.Now().UnixMilli()
+	this.events.Publish(event)
+
+	if len(this.switchTable.services.serviceUuids(events.ServiceName, events.ServiceArea)) == 0 {
+		return
+	}
+	data, err := event.ToBytes()
+	if err != nil {
+		this.resources.Logger().Error(err)
+		return
+	}
+	eventData, err := this.protocol.Create(this.newMessage(events.ServiceName, events.ServiceArea, ifs.POST), object.New(nil, data))
+	if err != nil {
+		this.resources.Logger().Error(err)
+		return
+	}
+	this.addVnetTask(QHandleData, eventData, this.vnic)
+}
+
+// vnicConnected publishes the connection of a vnic with its link kind.
+func (this *VNet) vnicConnected(uuid, alias, link string) {
+	this.publishEvent(&events.Event{Type: events.VNicConnected, Uuid: uuid, Alias: alias, Link: link})
+}
+
+// vnicDisconnected publishes the disconnection of a vnic.
+func (this *VNet) vnicDisconnected(uuid, alias string) {
+	this.publishEvent(&events.Event{Type: events.VNicDisconnected, Uuid: uuid, Alias: alias})
+}
+
+// routesChanged publishes an event per route, uuid to the VNet it is reachable by.
+func (this *VNet) routesChanged(eventType events.EventType, routes map[string]string) {
+	for uuid, vnetUuid := range routes {
+		this.publishEvent(&events.Event{Type: eventType, Uuid: uuid, RouteVnet: vnetUuid})
+	}
+}
+
+// serviceAdded publishes a new service instance and a leader change it caused.
+func (this *VNet) serviceAdded(data *l8system.L8ServiceData) {
+	this.publishEvent(&events.Event{Type: events.ServiceAdded, Uuid: data.ServiceUuid,
+		ServiceName: data.ServiceName, ServiceArea: byte(data.ServiceArea)})
+	this.checkLeader(data.ServiceName, byte(data.ServiceArea))
+}
+
+// servicesRemoved publishes the removed service instances and the leader changes they caused.
+func (this *VNet) servicesRemoved(removed []*l8system.L8ServiceData) {
+	for _, data := range removed {
+		this.publishEvent(&events.Event{Type: events.ServiceRemoved, Uuid: data.ServiceUuid,
+			ServiceName: data.ServiceName, ServiceArea: byte(data.ServiceArea)})
+	}
+	for _, data := range removed {
+		this.checkLeader(data.ServiceName, byte(data.ServiceArea))
+	}
+}
+
+// checkLeader compares the current leader of a service with the last known one and
+// publishes a LeaderChanged event when they differ.
+func (this *VNet) checkLeader(serviceName string, serviceArea byte) {
+	key := strings.New(serviceName, "/", int(serviceArea)).String()
+	leader := ""
+	if len(this.switchTable.services.serviceUuids(serviceName, serviceArea)) > 0 {
+		leader = this.ServiceLeader(serviceName, serviceArea)
+	}
+	previous, ok := this.leaders.Load(key)
+	if (ok && previous.(string) == leader) || (!ok && leader == "") {
+		return
+	}
+	this.leaders.Store(key, leader)
+	previousLeader := ""
+	if ok {
+		previousLeader = previous.(string)
+	}
+	this.publishEvent(&events.Event{Type: events.LeaderChanged, Uuid: leader, PreviousLeader: previousLeader,
+		ServiceName: serviceName, ServiceArea: serviceArea})
+}
@@ -0,0 +1,37 @@
+// © 2025 Sharon Aicler (saichler@gmail.com)
+//
+// Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vnet
+
+import (
+	"github.com/saichler/l8bus/go/overlay/metrics"
+	"github.com/saichler/l8bus/go/overlay/protocol"
+	"github.com/saichler/l8srlz/go/serialize/object"
+)
+
+// MessageExpired drops a message that waited in a queue past its expiry, it is kept as a
+// dead letter and a request is failed back to its sender, so it does not wait out its
+// own timeout.
+func (this *VNet) MessageExpired(data []byte) {
+	metrics.GetGlobalRegistry(this.resources.Logger()).Counter("layer8_expired_messages_total",
+		map[string]string{"vnic_id": this.vnetUuid}).Inc()
+	this.deadLetter(data, protocol.ExpiredMessage)
+	msg, err := this.protocol.MessageOf(data)
+	if err != nil || !msg.Request() {
+		return
+	}
+	err = this.vnic.Reply(msg, object.NewError(protocol.ExpiredMessage))
+	if err != nil {
+		this.resources.Logger().Error(err)
+	}
+}
@@ -0,0 +1,164 @@
+// © 2025 Sharon Aicler (saichler@gmail.com)
+//
+// Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vnet
+
+import (
+	"sync"
+
+	"github.com/saichler/l8bus/go/overlay/protocol"
+	"github.com/saichler/l8srlz/go/serialize/object"
+	"github.com/saichler/l8types/go/ifs"
+	"github.com/saichler/l8utils/go/utils/strings"
+)
+
+// gatewaySubscriptions holds the gateway clients subscribed to service notifications,
+// keyed by service name & area and then by client id.
+type gatewaySubscriptions struct {
+	mtx  *sync.RWMutex
+	subs map[string]map[string]func(ifs.IElements)
+}
+
+func newGatewaySubscriptions() *gatewaySubscriptions {
+	return &gatewaySubscriptions{mtx: &sync.RWMutex{}, subs: make(map[string]map[string]func(ifs.IElements))}
+}
+
+func subscriptionKey(serviceName string, serviceArea byte) string {
+	return strings.New(serviceName, ":", int(serviceArea)).String()
+}
+
+// ServiceRequest sends a request on behalf of a gateway client and waits for the reply.
+// When the destination is empty, the provider is selected by the multicast mode.
+func (this *VNet) ServiceRequest(destination, serviceName string, serviceArea byte, action ifs.Action,
+	mode ifs.MulticastMode, data interface{}, timeout int, token string) ifs.IElements {
+	if destination == "" {
+		destination = this.switchTable.services.serviceFor(serviceName, serviceArea, this.vnetUuid, mode)
+	}
+	if destination == "" {
+		return object.NewError(protocol.Failure(protocol.ErrNoProvider, serviceName, " area ", int(serviceArea)).Error())
+	}
+	if destination == this.vnetUuid {
+		return this.localServiceRequest(serviceName, serviceArea, action, data, token)
+	}
+	_, conn := this.switchTable.conns.getConnection(destination, true)
+	if conn == nil {
+		return object.NewError(protocol.Failure(protocol.ErrNoDestination, destination).Error())
+	}
+	return conn.Request(destination, serviceName, serviceArea, action, data, timeout, token)
+}
+
+// localServiceRequest handles a gateway request for a service hosted by the vnet itself.
+func (this *VNet) localServiceRequest(serviceName string, serviceArea byte, action ifs.Action, data interface{}, token string) ifs.IElements {
+	handler, ok := this.resources.Services().ServiceHandler(serviceName, serviceArea)
+	if !ok {
+		return object.NewError(protocol.Failure(protocol.ErrNoProvider, serviceName, " area ", int(serviceArea)).Error())
+	}
+	var elems ifs.IElements
+	query, ok := data.(string)
+	if ok {
+		q, err := object.NewQuery(query, this.resources)
+		if err != nil {
+			return object.NewError(err.Error())
+		}
+		elems = q
+	} else {
+		elems = object.New(nil, data)
+	}
+	err := this.authorized(serviceName, action, elems, this.vnetUuid, token)
+	if err != nil {
+		return object.NewError(err.Error())
+	}
+	switch action {
+	case ifs.POST:
+		return handler.Post(elems, this.vnic)
+	case ifs.PUT:
+		return handler.Put(elems, this.vnic)
+	case ifs.PATCH:
+		return handler.Patch(elems, this.vnic)
+	case ifs.DELETE:
+		return handler.Delete(elems, this.vnic)
+	case ifs.GET:
+		return handler.Get(elems, this.vnic)
+	}
+	return object.NewError(strings.New("Unsupported action ", int(action)).String())
+}
+
+// Subscribe registers a gateway client for the notifications of the service passing through this vnet.
+func (this *VNet) Subscribe(id, serviceName string, serviceArea byte, fn func(notification ifs.IElements)) {
+	this.gatewaySubs.mtx.Lock()
+	defer this.gatewaySubs.mtx.Unlock()
+	key := subscriptionKey(serviceName, serviceArea)
+	subs, ok := this.gatewaySubs.subs[key]
+	if !ok {
+		subs = make(map[string]func(ifs.IElements))
+		this.gatewaySubs.subs[key] = subs
+	}
+	subs[id] = fn
+}
+
+// Unsubscribe removes the gateway client subscription to the service.
+func (this *VNet) Unsubscribe(id, serviceName string, serviceArea byte) {
+	this.gatewaySubs.mtx.Lock()
+	defer this.gatewaySubs.mtx.Unlock()
+	key := subscriptionKey(serviceName, serviceArea)
+	subs, ok := this.gatewaySubs.subs[key]
+	if !ok {
+		return
+	}
+	delete(subs, id)
+	if len(subs) == 0 {
+		delete(this.gatewaySubs.subs, key)
+	}
+}
+
+// UnsubscribeAll removes all the subscriptions of the gateway client.
+func (this *VNet) UnsubscribeAll(id string) {
+	this.gatewaySubs.mtx.Lock()
+	defer this.gatewaySubs.mtx.Unlock()
+	for key, subs := range this.gatewaySubs.subs {
+		delete(subs, id)
+		if len(subs) == 0 {
+			delete(this.gatewaySubs.subs, key)
+		}
+	}
+}
+
+// notifySubscribers delivers a multicast notification to the subscribed gateway clients,
+// returning true if there are subscribers to the service.
+func (this *VNet) notifySubscribers(serviceName string, serviceArea byte, data []byte) bool {
+	this.gatewaySubs.mtx.RLock()
+	subs, ok := this.gatewaySubs.subs[subscriptionKey(serviceName, serviceArea)]
+	if !ok || len(subs) == 0 {
+		this.gatewaySubs.mtx.RUnlock()
+		return false
+	}
+	fns := make([]func(ifs.IElements), 0, len(subs))
+	for _, fn := range subs {
+		fns = append(fns, fn)
+	}
+	this.gatewaySubs.mtx.RUnlock()
+
+	msg, err := this.protocol.MessageOf(data)
+	if err != nil || msg.Action() != ifs.Notify {
+		return true
+	}
+	elems, err := this.protocol.ElementsOf(msg)
+	if err != nil {
+		this.resources.Logger().Error(err)
+		return true
+	}
+	for _, fn := range fns {
+		go fn(elems)
+	}
+	return true
+}
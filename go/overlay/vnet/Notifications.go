@@ -14,8 +14,6 @@
 package vnet
 
 import (
-	"fmt"
-
 	"github.com/saichler/l8bus/go/overlay/protocol"
 	"github.com/saichler/l8srlz/go/serialize/object"
 	"github.com/saichler/l8types/go/ifs"
@@ -23,38 +21,32 @@ import (
 	"github.com/saichler/l8types/go/types/l8system"
 )
 
+// newMessage returns the options of a message the VNet sends from itself.
+func (this *VNet) newMessage(serviceName string, serviceArea byte, action ifs.Action) *protocol.MessageOptions {
+	return protocol.NewMessage(serviceName, serviceArea, action).From(this.vnetUuid, this.vnetUuid).
+		WithPriority(ifs.P1).WithSequence(this.protocol.NextMessageNumber())
+}
+
 // PropertyChangeNotification handles property change notifications from services (primarily health),
 // broadcasting the notification to local VNics in the network.
 func (this *VNet) PropertyChangeNotification(set *l8notify.L8NotificationSet) {
 	//only health service will call this callback so check if the notification is from a local source
 	//if it is from local source, then just notify local vnics
-	protocol.MsgLog.AddLog(set.ServiceName, byte(set.ServiceArea), ifs.Notify)
-	vnetUuid := this.resources.SysConfig().LocalUuid
-	nextId := this.protocol.NextMessageNumber()
-	syncData, _ := this.protocol.CreateMessageFor("", set.ServiceName, byte(set.ServiceArea), ifs.P1, ifs.M_All,
-		ifs.Notify, vnetUuid, vnetUuid, object.New(nil, set), false, false,
-		nextId, ifs.NotATransaction, "", "",
-		-1, -1, -1, -1, -1, 0, false, "")
+	syncData, _ := this.protocol.Create(this.newMessage(set.ServiceName, byte(set.ServiceArea), ifs.Notify), object.New(nil, set))
 	this.addVnetTask(QHandleData, syncData, this.vnic)
 }
 
 // publishRoutes broadcasts the current route table to all external VNet connections.
 func (this *VNet) publishRoutes() {
-	vnetUuid := this.resources.SysConfig().LocalUuid
 	vnetName := this.resources.SysConfig().LocalAlias
 
-	nextId := this.protocol.NextMessageNumber()
-
 	routeTable := &l8system.L8RouteTable{Rows: this.switchTable.conns.Routes()}
 	this.resources.Logger().Debug("Vnet ", vnetName, " publish routes ", len(routeTable.Rows))
 
 	data := &l8system.L8SystemMessage_RouteTable{RouteTable: routeTable}
 	routes := &l8system.L8SystemMessage{Action: l8system.L8SystemAction_Routes_Add, Data: data}
 
-	routesData, _ := this.protocol.CreateMessageFor("", ifs.SysMsg, ifs.SysAreaPrimary, ifs.P1, ifs.M_All,
-		ifs.POST, vnetUuid, vnetUuid, object.New(nil, routes), false, false,
-		nextId, ifs.NotATransaction, "", "",
-		-1, -1, -1, -1, -1, 0, false, "")
+	routesData, _ := this.protocol.Create(this.newMessage(ifs.SysMsg, ifs.SysAreaPrimary, ifs.POST), object.New(nil, routes))
 
 	allExternal := this.switchTable.conns.allExternalVnets()
 	for _, external := range allExternal {
@@ -64,17 +56,11 @@ func (this *VNet) publishRoutes() {
 
 // publishRemovedRoutes broadcasts route removal messages to all external VNet connections.
 func (this *VNet) publishRemovedRoutes(removed map[string]string) {
-	vnetUuid := this.resources.SysConfig().LocalUuid
-	nextId := this.protocol.NextMessageNumber()
-
 	routeTable := &l8system.L8RouteTable{Rows: removed}
 	data := &l8system.L8SystemMessage_RouteTable{RouteTable: routeTable}
 	routes := &l8system.L8SystemMessage{Action: l8system.L8SystemAction_Routes_Remove, Data: data}
 
-	routesData, _ := this.protocol.CreateMessageFor("", ifs.SysMsg, ifs.SysAreaPrimary, ifs.P1, ifs.M_All,
-		ifs.POST, vnetUuid, vnetUuid, object.New(nil, routes), false, false,
-		nextId, ifs.NotATransaction, "", "",
-		-1, -1, -1, -1, -1, 0, false, "")
+	routesData, _ := this.protocol.Create(this.newMessage(ifs.SysMsg, ifs.SysAreaPrimary, ifs.POST), object.New(nil, routes))
 
 	allExternal := this.switchTable.conns.allExternalVnets()
 	for _, external := range allExternal {
@@ -84,26 +70,12 @@ func (this *VNet) publishRemovedRoutes(removed map[string]string) {
 
 // publishSystemMessage broadcasts a system control message to all external VNet connections.
 func (this *VNet) publishSystemMessage(sysmsg *l8system.L8SystemMessage) {
-	vnetUuid := this.resources.SysConfig().LocalUuid
-	nextId := this.protocol.NextMessageNumber()
-
 	sysmsg.Publish = false
 
-	sysmsgData, _ := this.protocol.CreateMessageFor("", ifs.SysMsg, ifs.SysAreaPrimary, ifs.P1, ifs.M_All,
-		ifs.POST, vnetUuid, vnetUuid, object.New(nil, sysmsg), false, false,
-		nextId, ifs.NotATransaction, "", "",
-		-1, -1, -1, -1, -1, 0, false, "")
+	sysmsgData, _ := this.protocol.Create(this.newMessage(ifs.SysMsg, ifs.SysAreaPrimary, ifs.POST), object.New(nil, sysmsg))
 
 	allExternal := this.switchTable.conns.allExternalVnets()
-	svcData := sysmsg.GetServiceData()
-	if svcData != nil {
-		fmt.Printf("[VNET-FWD-SVCADD] vnet=%s service=(%s,%d) externalVnets=%d\n",
-			this.resources.SysConfig().LocalAlias, svcData.ServiceName,
-			svcData.ServiceArea, len(allExternal))
-	} else {
-		fmt.Printf("[VNET-FWD-SYSMSG] vnet=%s action=%v externalVnets=%d\n",
-			this.resources.SysConfig().LocalAlias, sysmsg.Action, len(allExternal))
-	}
+	this.resources.Logger().Debug("Publishing system message ", sysmsg.Action, " to ", len(allExternal), " external vnets")
 	for _, external := range allExternal {
 		external.SendMessage(sysmsgData)
 	}
@@ -31,6 +31,8 @@ type Services struct {
 	services   *sync.Map
 	routeTable *RouteTable
 	roundrobin *sync.Map
+	// isOpen returns true if the circuit breaker of a service instance is open
+	isOpen func(serviceName string, serviceArea byte, uuid string) bool
 }
 
 // newServices creates a new Services manager with the given route table.
@@ -39,7 +41,8 @@ func newServices(routeTable *RouteTable) *Services {
 }
 
 // addService registers a service with its name, area, and UUID for discovery.
-func (this *Services) addService(data *l8system.L8ServiceData) {
+// It returns true if the service instance was not registered before.
+func (this *Services) addService(data *l8system.L8ServiceData) bool {
 	m1, ok := this.services.Load(data.ServiceName)
 	if !ok {
 		m1 = &sync.Map{}
@@ -51,23 +54,33 @@ func (this *Services) addService(data *l8system.L8ServiceData) {
 		m2 = &sync.Map{}
 		m1.(*sync.Map).Store(area, m2)
 	}
+	_, exist := m2.(*sync.Map).Load(data.ServiceUuid)
 	m2.(*sync.Map).Store(data.ServiceUuid, time.Now().UnixMilli())
+	return !exist
 }
 
-// removeService unregisters services matching the UUIDs in the removed map.
-func (this *Services) removeService(removed map[string]string) {
+// removeService unregisters services matching the UUIDs in the removed map,
+// returning the service instances that were removed.
+func (this *Services) removeService(removed map[string]string) []*l8system.L8ServiceData {
+	result := make([]*l8system.L8ServiceData, 0)
 	for uuid, _ := range removed {
 		this.services.Range(func(key, value interface{}) bool {
+			serviceName := key.(string)
 			m1 := value.(*sync.Map)
 			m1.Range(func(key, value interface{}) bool {
 				m2 := value.(*sync.Map)
-				m2.Delete(uuid)
+				_, ok := m2.LoadAndDelete(uuid)
+				if ok {
+					result = append(result, &l8system.L8ServiceData{ServiceName: serviceName,
+						ServiceArea: int32(key.(byte)), ServiceUuid: uuid})
+				}
 				return true
 			})
 			return true
 		},
 		)
 	}
+	return result
 }
 
 // serviceUuids returns all service UUIDs for a given service name and area, with their registration timestamps.
@@ -96,7 +109,22 @@ func (this *Services) serviceUuids(serviceName string, serviceArea byte) map[str
 // M_Leader: select the earliest registered service (leader election)
 // M_RoundRobin: distribute requests across services in rotation
 // M_All: select any available service
+// M_RoundRobin & M_Proximity skip instances whose circuit breaker is open, unless all are open.
 func (this *Services) serviceFor(serviceName string, serviceArea byte, source string, mode ifs.MulticastMode) string {
+	if this.isOpen != nil && (mode == ifs.M_RoundRobin || mode == ifs.M_Proximity) {
+		result := this.selectService(serviceName, serviceArea, source, mode, func(uuid string) bool {
+			return this.isOpen(serviceName, serviceArea, uuid)
+		})
+		if result != "" {
+			return result
+		}
+	}
+	return this.selectService(serviceName, serviceArea, source, mode, nil)
+}
+
+// selectService selects a service UUID per the multicast mode, skipping the instances
+// the skip function returns true for.
+func (this *Services) selectService(serviceName string, serviceArea byte, source string, mode ifs.MulticastMode, skip func(uuid string) bool) string {
 	m1, ok := this.services.Load(serviceName)
 	if !ok {
 		return ""
@@ -111,6 +139,9 @@ func (this *Services) serviceFor(serviceName string, serviceArea byte, source st
 		sourceVnet, _ := this.routeTable.vnetOf(source)
 		m2.(*sync.Map).Range(func(key, value interface{}) bool {
 			k := key.(string)
+			if skip != nil && skip(k) {
+				return true
+			}
 			result = k // make sure if there is a service,use it anyway even if there is no proximity
 			v, _ := this.routeTable.vnetOf(k)
 			if v == sourceVnet {
@@ -154,6 +185,9 @@ func (this *Services) serviceFor(serviceName string, serviceArea byte, source st
 		found := false
 		m2.(*sync.Map).Range(func(key, value interface{}) bool {
 			k := key.(string)
+			if skip != nil && skip(k) {
+				return true
+			}
 			result = k // make sure we have a result in anyway
 			_, ok = rrS.Load(k)
 			if !ok {
@@ -163,7 +197,7 @@ func (this *Services) serviceFor(serviceName string, serviceArea byte, source st
 			}
 			return true
 		})
-		if !found {
+		if !found && result != "" {
 			rrS.Clear()
 			rrS.Store(result, true)
 		}
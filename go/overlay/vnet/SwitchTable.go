@@ -40,6 +40,7 @@ func newSwitchTable(switchService *VNet) *SwitchTable {
 	switchTable.routeTable = newRouteTable(vnetUuid)
 	switchTable.conns = newConnections(vnetUuid, switchTable.routeTable, switchService.resources.Logger())
 	switchTable.services = newServices(switchTable.routeTable)
+	switchTable.services.isOpen = switchService.breakers.isOpen
 	switchTable.switchService = switchService
 	switchTable.desc = strings.New("SwitchTable (", switchService.resources.SysConfig().LocalUuid, ") - ").String()
 	go switchTable.monitor()
@@ -49,13 +50,16 @@ func newSwitchTable(switchService *VNet) *SwitchTable {
 func (this *SwitchTable) addVNic(vnic ifs.IVNic) {
 	config := vnic.Resources().SysConfig()
 	//check if this port is local to the machine, e.g. not belong to public subnet
-	isLocal := ipsegment.IpSegment.IsLocal(config.Address)
+	isLocal := isLocal(vnic)
 	isExternalVnic := config.RemoteVnet != ""
+	link := LinkExternalVnet
 	if isExternalVnic {
+		link = LinkExternalVnic
 		this.conns.addExternalVnic(config.RemoteUuid, vnic)
 	} else
 	// If it is local, add it to the internal map
 	if isLocal && !config.ForceExternal {
+		link = LinkInternal
 		this.conns.addInternal(config.RemoteUuid, vnic)
 		this.switchService.addVnetTask(QHealthReport, []byte(config.RemoteUuid), this.switchService.vnic)
 	} else {
@@ -65,6 +69,22 @@ func (this *SwitchTable) addVNic(vnic ifs.IVNic) {
 		this.switchService.resources.Services().TriggerElections(this.switchService.vnic)
 	}
 	this.switchService.publishRoutes()
+	this.switchService.vnicConnected(config.RemoteUuid, config.RemoteAlias, link)
+}
+
+// localLink is implemented by vnics that know if their link is on the same host.
+type localLink interface {
+	LocalLink() bool
+}
+
+// isLocal returns true if the vnic is on this machine, either over a same host link
+// such as a unix domain socket, or with an address that does not belong to the public subnet.
+func isLocal(vnic ifs.IVNic) bool {
+	link, ok := vnic.(localLink)
+	if ok && link.LocalLink() {
+		return true
+	}
+	return ipsegment.IpSegment.IsLocal(vnic.Resources().SysConfig().Address)
 }
 
 func (this *SwitchTable) connectionsForService(serviceName string, serviceArea byte, sourceSwitch string, mode ifs.MulticastMode) map[string]ifs.IVNic {
@@ -0,0 +1,241 @@
+// © 2025 Sharon Aicler (saichler@gmail.com)
+//
+// Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vnet
+
+import (
+	"sort"
+	stdstrings "strings"
+	"sync"
+
+	"github.com/saichler/l8bus/go/overlay/health"
+	"github.com/saichler/l8types/go/ifs"
+	"github.com/saichler/l8types/go/types/l8health"
+	"github.com/saichler/l8utils/go/utils/strings"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// Link kinds of the topology, a routed link is known only from a route table as the
+// VNet of the vnic did not answer
+const (
+	LinkInternal     = "internal"
+	LinkExternalVnet = "external-vnet"
+	LinkExternalVnic = "external-vnic"
+	LinkRouted       = "routed"
+)
+
+// Topology is the VNets & VNics of the overlay, how they are linked and their services
+type Topology struct {
+	Nodes []*TopologyNode `json:"nodes"`
+	Links []*TopologyLink `json:"links"`
+}
+
+// TopologyNode is a VNet or a VNic, services are "name/area"
+type TopologyNode struct {
+	Uuid     string   `json:"uuid"`
+	Alias    string   `json:"alias"`
+	IsVnet   bool     `json:"isVnet"`
+	Services []string `json:"services,omitempty"`
+}
+
+// TopologyLink is a connection between two nodes
+type TopologyLink struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Kind string `json:"kind"`
+}
+
+// CollectTopology fetches the switch table of every VNet known to the health service and
+// builds the topology of the overlay. VNets that fail to answer within the timeout are
+// logged, the vnics attached to them are linked by the route tables of the others.
+func CollectTopology(vnic ifs.IVNic, timeoutSeconds int) *Topology {
+	nodes := health.All(vnic.Resources())
+	tables := make([]*SwitchTableInfo, 0)
+	mtx := &sync.Mutex{}
+	wg := &sync.WaitGroup{}
+	for uuid, hp := range nodes {
+		if !hp.IsVnet {
+			continue
+		}
+		wg.Add(1)
+		go func(uuid string) {
+			defer wg.Done()
+			resp := vnic.Request(uuid, AdminServiceName, 0, ifs.GET, &wrapperspb.BytesValue{}, timeoutSeconds)
+			table, err := SwitchTableFrom(resp)
+			if err != nil {
+				vnic.Resources().Logger().Warning("Failed to fetch the switch table of ", uuid, ": ", err.Error())
+				return
+			}
+			mtx.Lock()
+			tables = append(tables, table)
+			mtx.Unlock()
+		}(uuid)
+	}
+	wg.Wait()
+	return TopologyOf(tables, nodes)
+}
+
+// TopologyOf builds the topology from the switch tables of the VNets and the health
+// records of the nodes. Links between VNets appear once whichever side reported them.
+func TopologyOf(tables []*SwitchTableInfo, nodes map[string]*l8health.L8Health) *Topology {
+	topology := &Topology{Nodes: make([]*TopologyNode, 0), Links: make([]*TopologyLink, 0)}
+	known := make(map[string]*TopologyNode)
+	node := func(uuid, alias string, isVnet bool) {
+		n, ok := known[uuid]
+		if !ok {
+			n = &TopologyNode{Uuid: uuid, Alias: alias, IsVnet: isVnet}
+			known[uuid] = n
+		}
+		if n.Alias == "" {
+			n.Alias = alias
+		}
+		n.IsVnet = n.IsVnet || isVnet
+	}
+	for uuid, hp := range nodes {
+		node(uuid, hp.Alias, hp.IsVnet)
+		known[uuid].Services = servicesOf(hp)
+	}
+
+	linked := make(map[string]bool)
+	link := func(from, to, kind string) {
+		key := strings.New(from, to).String()
+		if from > to {
+			key = strings.New(to, from).String()
+		}
+		if linked[key] {
+			return
+		}
+		linked[key] = true
+		topology.Links = append(topology.Links, &TopologyLink{From: from, To: to, Kind: kind})
+	}
+	// links between vnets first, so they are classified as external by either side
+	for _, table := range tables {
+		node(table.Uuid, table.Alias, true)
+		for _, conn := range table.ExternalVnets {
+			node(conn.Uuid, conn.Alias, true)
+			link(table.Uuid, conn.Uuid, LinkExternalVnet)
+		}
+	}
+	attached := make(map[string]bool)
+	for _, table := range tables {
+		for _, conn := range table.Internal {
+			node(conn.Uuid, conn.Alias, false)
+			link(table.Uuid, conn.Uuid, LinkInternal)
+			attached[conn.Uuid] = true
+		}
+		for _, conn := range table.ExternalVnics {
+			node(conn.Uuid, conn.Alias, false)
+			link(table.Uuid, conn.Uuid, LinkExternalVnic)
+			attached[conn.Uuid] = true
+		}
+	}
+	for _, table := range tables {
+		for uuid, vnetUuid := range table.Routes {
+			n, ok := known[uuid]
+			if ok && n.IsVnet || attached[uuid] {
+				continue
+			}
+			node(uuid, "", false)
+			node(vnetUuid, "", true)
+			link(vnetUuid, uuid, LinkRouted)
+			attached[uuid] = true
+		}
+	}
+
+	for _, n := range known {
+		topology.Nodes = append(topology.Nodes, n)
+	}
+	sort.Slice(topology.Nodes, func(i, j int) bool {
+		if topology.Nodes[i].IsVnet != topology.Nodes[j].IsVnet {
+			return topology.Nodes[i].IsVnet
+		}
+		return topology.Nodes[i].Alias < topology.Nodes[j].Alias
+	})
+	sort.Slice(topology.Links, func(i, j int) bool {
+		if topology.Links[i].From != topology.Links[j].From {
+			return topology.Links[i].From < topology.Links[j].From
+		}
+		return topology.Links[i].To < topology.Links[j].To
+	})
+	return topology
+}
+
+// servicesOf returns the services of a health record as "name/area", sorted
+func servicesOf(hp *l8health.L8Health) []string {
+	result := make([]string, 0)
+	if hp.Services == nil {
+		return result
+	}
+	for name, areas := range hp.Services.ServiceToAreas {
+		if areas == nil {
+			continue
+		}
+		for area := range areas.Areas {
+			result = append(result, strings.New(name, "/", int(area)).String())
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// Dot renders the topology in the Graphviz DOT format, VNets are boxes and VNics are
+// ellipses labeled with their services, links between VNets are bold and routed links
+// are dashed.
+func (this *Topology) Dot() string {
+	dot := &stdstrings.Builder{}
+	dot.WriteString("graph overlay {\n")
+	for _, n := range this.Nodes {
+		label := n.Alias
+		if label == "" {
+			label = n.Uuid
+		}
+		for _, service := range n.Services {
+			label = label + "\n" + service
+		}
+		shape := "ellipse"
+		if n.IsVnet {
+			shape = "box"
+		}
+		dot.WriteString(strings.New("  ", dotQuote(n.Uuid), " [label=", dotQuote(label), " shape=", shape, "];\n").String())
+	}
+	for _, l := range this.Links {
+		style := "solid"
+		switch l.Kind {
+		case LinkExternalVnet:
+			style = "bold"
+		case LinkRouted:
+			style = "dashed"
+		}
+		dot.WriteString(strings.New("  ", dotQuote(l.From), " -- ", dotQuote(l.To), " [label=", dotQuote(l.Kind), " style=", style, "];\n").String())
+	}
+	dot.WriteString("}\n")
+	return dot.String()
+}
+
+// dotQuote quotes a DOT identifier, escaping quotes & backslashes, new lines are kept
+// as the \n escape of DOT labels
+func dotQuote(value string) string {
+	result := make([]byte, 0, len(value)+2)
+	result = append(result, '"')
+	for i := 0; i < len(value); i++ {
+		switch value[i] {
+		case '"', '\\':
+			result = append(result, '\\', value[i])
+		case '\n':
+			result = append(result, '\\', 'n')
+		default:
+			result = append(result, value[i])
+		}
+	}
+	return string(append(result, '"'))
+}
@@ -0,0 +1,36 @@
+// © 2025 Sharon Aicler (saichler@gmail.com)
+//
+// Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vnet
+
+import (
+	"github.com/saichler/l8bus/go/overlay/tracing"
+	"github.com/saichler/l8utils/go/utils/strings"
+)
+
+// traceRoute starts the span of routing a traced message through this vnet and returns
+// the message bytes carrying the span as the parent of the next hop. Messages without
+// a trace context are returned as is with no span.
+func (this *VNet) traceRoute(data []byte, serviceName string, serviceArea byte) ([]byte, *tracing.Span) {
+	trace := tracing.Extract(data)
+	if trace == nil {
+		return data, nil
+	}
+	name := strings.New("route ", serviceName, "/", int(serviceArea)).String()
+	span := tracing.StartSpan(this.resources.SysConfig().LocalAlias, name, tracing.SpanKindInternal, trace)
+	if span == nil {
+		return data, nil
+	}
+	span.SetAttribute("vnet", this.vnetUuid)
+	return tracing.Inject(data, span.Context()), span
+}
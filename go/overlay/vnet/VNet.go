@@ -18,10 +18,17 @@ import (
 	"fmt"
 	"github.com/saichler/l8utils/go/utils/queues"
 	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/saichler/l8bus/go/overlay/capture"
+	"github.com/saichler/l8bus/go/overlay/events"
 	"github.com/saichler/l8bus/go/overlay/health"
+	"github.com/saichler/l8bus/go/overlay/metrics"
 	"github.com/saichler/l8bus/go/overlay/protocol"
+	"github.com/saichler/l8bus/go/overlay/transport"
 	vnic2 "github.com/saichler/l8bus/go/overlay/vnic"
 	"github.com/saichler/l8srlz/go/serialize/object"
 	"github.com/saichler/l8types/go/ifs"
@@ -31,7 +38,6 @@ import (
 	"github.com/saichler/l8types/go/types/l8system"
 	"github.com/saichler/l8types/go/types/l8web"
 	resources2 "github.com/saichler/l8utils/go/utils/resources"
-	"github.com/saichler/l8utils/go/utils/strings"
 )
 
 // VNet represents a Virtual Network switch that manages connections between
@@ -40,6 +46,8 @@ import (
 type VNet struct {
 	resources        ifs.IResources
 	socket           net.Listener
+	localSocket      net.Listener
+	transport        transport.Transport
 	running          bool
 	ready            bool
 	switchTable      *SwitchTable
@@ -52,6 +60,16 @@ type VNet struct {
 	healthReport     *queues.Queue
 	vnetServices     map[string]bool
 	vnetUuid         string
+	webServer        *http.Server
+	metricsServer    *http.Server
+	statisticsSink   atomic.Pointer[protocol.StatisticsSink]
+	captureWriter    atomic.Pointer[capture.Writer]
+	gatewaySubs      *gatewaySubscriptions
+	breakers         *ServiceBreakers
+	events           *events.EventBus
+	deadLetters      *DeadLetters
+	leaders          *sync.Map
+	capabilities     atomic.Pointer[[]protocol.Capability]
 }
 
 // NewVNet creates and initializes a new VNet instance. It registers required
@@ -62,23 +80,33 @@ func NewVNet(resources ifs.IResources, hasSecondary ...bool) *VNet {
 	resources.Registry().Register(&l8web.L8Empty{})
 	resources.Registry().Register(&l8health.L8Top{})
 	net := &VNet{}
-	net.vnetServices = map[string]bool{health.ServiceName: true, "tokens": true, "users": true, "roles": true, "Creds": true, ifs.SystemServiceGroup: true}
+	net.vnetServices = map[string]bool{health.ServiceName: true, AdminServiceName: true, DeadLetterServiceName: true, metrics.ServiceName: true, "tokens": true, "users": true, "roles": true, "Creds": true, ifs.SystemServiceGroup: true}
 	net.vnetServiceTasks = queues.NewQueue("vnetServiceTasks", int(resources2.DEFAULT_QUEUE_SIZE))
 	net.vnetSystemTasks = queues.NewQueue("vnetSystemTasks", queues.NO_LIMIT)
 	net.handleDataTasks = queues.NewQueue("vnicVnetUnicastTasks", int(resources2.DEFAULT_QUEUE_SIZE))
 	net.healthReport = queues.NewQueue("healthReport", int(resources2.DEFAULT_QUEUE_SIZE))
 	net.resources = resources
+	net.transport = transport.Default()
+	net.gatewaySubs = newGatewaySubscriptions()
+	net.events = events.NewEventBus(resources.Logger(), func(event *events.Event) {
+		resources.Logger().Warning("Event bus is full, dropped ", string(event.Type), " event")
+	})
+	net.leaders = &sync.Map{}
+	net.SetCapabilities(protocol.DefaultCapabilities())
+	net.deadLetters = newDeadLetters(DefaultDeadLetterCapacity, DefaultDeadLetterMaxBytes)
 	net.resources.Set(net)
 	net.vnic = newVnicVnet(net)
 	net.protocol = protocol.New(net.vnic)
 	net.running = true
 	net.resources.SysConfig().LocalUuid = ifs.NewUuid()
 	net.vnetUuid = net.resources.SysConfig().LocalUuid
+	net.breakers = newServiceBreakers(metrics.NewCircuitBreakerManager(metrics.GetGlobalRegistry(resources.Logger()), resources.Logger()))
 	net.switchTable = newSwitchTable(net)
 	go net.processTasks(net.vnetSystemTasks, net.systemMessageReceived)
 	go net.processTasks(net.vnetServiceTasks, net.vnetServiceRequest)
 	go net.processTasks(net.handleDataTasks, net.HandleData)
 	go net.processTasks(net.healthReport, net.sendHealthReport)
+	go net.expireRequests()
 
 	secService, ok := net.resources.Security().(ifs.ISecurityProviderActivate)
 	if ok {
@@ -93,6 +121,12 @@ func NewVNet(resources ifs.IResources, hasSecondary ...bool) *VNet {
 		health.Activate(net.vnic, true)
 		net.resources.SysConfig().RemoteVnet = ""
 	}
+	metrics.Activate(net.vnic)
+	vnic2.ActivateHello(net.vnic)
+	adminSla := ifs.NewServiceLevelAgreement(&AdminService{vnet: net}, AdminServiceName, 0, false, nil)
+	net.resources.Services().Activate(adminSla, net.vnic)
+	dlqSla := ifs.NewServiceLevelAgreement(&DeadLetterService{vnet: net}, DeadLetterServiceName, 0, false, nil)
+	net.resources.Services().Activate(dlqSla, net.vnic)
 
 	net.discovery = NewDiscovery(net)
 
@@ -123,10 +157,42 @@ func (this *VNet) Start() error {
 		time.Sleep(time.Millisecond * 50)
 	}
 	time.Sleep(time.Millisecond * 50)
-	this.discovery.Discover()
+	// Discovery is UDP broadcast based, so it is only relevant when running over sockets
+	if _, ok := this.transport.(*transport.TCP); ok {
+		this.discovery.Discover()
+	}
 	return err
 }
 
+// SetCapabilities sets the capabilities the connections of this VNet announce in their
+// hello, the connections established after it is called use them.
+func (this *VNet) SetCapabilities(capabilities []protocol.Capability) {
+	this.capabilities.Store(&capabilities)
+}
+
+// Capabilities returns the capabilities the connections of this VNet announce
+func (this *VNet) Capabilities() []protocol.Capability {
+	return *this.capabilities.Load()
+}
+
+// SetChecksums enables or disables the frame checksum on the connections of this VNet
+// established after it is called, a connection uses it once its other side enabled it
+// as well.
+func (this *VNet) SetChecksums(enabled bool) {
+	this.SetCapabilities(protocol.WithCapability(this.Capabilities(), protocol.CapChecksum, enabled))
+}
+
+// SetTransport replaces the transport used to accept and dial connections,
+// it should be called before Start.
+func (this *VNet) SetTransport(t transport.Transport) {
+	this.transport = t
+}
+
+// Transport returns the transport used by this VNet.
+func (this *VNet) Transport() transport.Transport {
+	return this.transport
+}
+
 func (this *VNet) start(err *error) {
 	this.resources.Logger().Debug("VNet.start: Starting VNet ")
 	if this.resources.SysConfig().VnetPort == 0 {
@@ -140,6 +206,7 @@ func (this *VNet) start(err *error) {
 		err = &er
 		return
 	}
+	this.bindLocal()
 
 	for this.running {
 		this.ready = true
@@ -157,7 +224,7 @@ func (this *VNet) start(err *error) {
 }
 
 func (this *VNet) bind() error {
-	socket, e := net.Listen("tcp", strings.New(":", int(this.resources.SysConfig().VnetPort)).String())
+	socket, e := this.transport.Listen(this.resources.SysConfig().VnetPort)
 	if e != nil {
 		return this.resources.Logger().Error("Unable to bind to port ",
 			this.resources.SysConfig().VnetPort, e.Error())
@@ -168,11 +235,51 @@ func (this *VNet) bind() error {
 	return nil
 }
 
+// bindLocal additionally listens on a unix domain socket, so vnics on the same host
+// can connect without going through the tcp stack or the exposed port.
+func (this *VNet) bindLocal() {
+	if !transport.UnixSocket_Enabled {
+		return
+	}
+	if _, ok := this.transport.(*transport.TCP); !ok {
+		return
+	}
+	socket, e := transport.NewUnix().Listen(this.resources.SysConfig().VnetPort)
+	if e != nil {
+		this.resources.Logger().Error("Unable to bind unix socket for port ",
+			this.resources.SysConfig().VnetPort, e.Error())
+		return
+	}
+	this.resources.Logger().Debug("Bind Successfully to unix socket ",
+		transport.UnixSocketPath(this.resources.SysConfig().VnetPort))
+	this.localSocket = socket
+	go this.acceptLocal()
+}
+
+func (this *VNet) acceptLocal() {
+	for this.running {
+		conn, e := this.localSocket.Accept()
+		if e != nil {
+			if !this.running || errors.Is(e, net.ErrClosed) {
+				return
+			}
+			this.resources.Logger().Error("Failed to accept unix socket connection:", e)
+			time.Sleep(time.Millisecond * 100)
+			continue
+		}
+		if this.running {
+			this.resources.Logger().Debug("Accepted unix socket connection...")
+			go this.connect(conn)
+		}
+	}
+}
+
 func (this *VNet) connect(conn net.Conn) {
 	sec := this.resources.Security()
 	err := sec.CanAccept(conn)
 	if err != nil {
 		this.resources.Logger().Error(err)
+		conn.Close()
 		return
 	}
 
@@ -195,6 +302,8 @@ func (this *VNet) connect(conn net.Conn) {
 
 	vnic := vnic2.NewVirtualNetworkInterface(resources, conn)
 	vnic.Resources().SysConfig().LocalUuid = this.resources.SysConfig().LocalUuid
+	vnic.SetCircuitBreakers(this.breakers.manager)
+	vnic.SetCapabilities(this.Capabilities())
 
 	err = sec.ValidateConnection(conn, config)
 	if err != nil {
@@ -202,7 +311,7 @@ func (this *VNet) connect(conn net.Conn) {
 		return
 	}
 
-	this.addHealthForVNic(vnic.Resources().SysConfig())
+	this.addHealthForVNic(vnic.Resources().SysConfig(), isLocal(vnic))
 
 	vnic.Start()
 	this.notifyNewVNic(vnic)
@@ -217,12 +326,27 @@ func (this *VNet) Shutdown() {
 	this.resources.Logger().Debug("Shutdown called!")
 	this.running = false
 	this.socket.Close()
+	if this.localSocket != nil {
+		this.localSocket.Close()
+	}
+	if this.webServer != nil {
+		this.webServer.Close()
+	}
+	if this.metricsServer != nil {
+		this.metricsServer.Close()
+	}
+	if sink := this.statisticsSink.Swap(nil); sink != nil {
+		sink.Stop()
+	}
+	this.StopCapture()
 	this.switchTable.shutdown()
+	this.events.Close()
 }
 
-// Failed handles message delivery failures by creating and sending a failure
-// response back to the originating VNic with the specified error message.
+// Failed handles message delivery failures by keeping the message as a dead letter and
+// sending a failure response back to the originating VNic with the specified error message.
 func (this *VNet) Failed(data []byte, vnic ifs.IVNic, failMsg string) {
+	this.deadLetter(data, failMsg)
 	msg, err := this.protocol.MessageOf(data)
 	if err != nil {
 		this.resources.Logger().Error(err)
@@ -239,14 +363,35 @@ func (this *VNet) Failed(data []byte, vnic ifs.IVNic, failMsg string) {
 // destination based on message headers. It supports unicast, multicast, and
 // service-based routing modes.
 func (this *VNet) HandleData(data []byte, vnic ifs.IVNic) {
-	source, sourceVnet, destination, serviceName, serviceArea, _, multicastMode := ifs.HeaderOf(data)
-	protocol.MsgLog.AddLog(serviceName, serviceArea, ifs.Handle)
+	header, err := protocol.HeaderOf(data)
+	if err != nil {
+		this.resources.Logger().Error("Dropped a frame from ", vnic.Resources().SysConfig().RemoteAlias, ": ", err.Error())
+		return
+	}
+	if protocol.Expired(data) {
+		this.MessageExpired(data)
+		return
+	}
+	source, sourceVnet, destination, serviceName, serviceArea, multicastMode := header.Source, header.SourceVnet,
+		header.Destination, header.ServiceName, header.ServiceArea, header.MulticastMode
+	this.protocol.Statistics().Record(source, destination, serviceName, serviceArea, ifs.Handle, len(data))
+	this.captureFrame(data, vnic)
 
 	if serviceName == ifs.SysMsg && serviceArea == ifs.SysAreaPrimary {
-		this.addVnetTask(QSystem, data, vnic)
-		return
+		if !isProbe(data) {
+			this.addVnetTask(QSystem, data, vnic)
+			return
+		}
+		var answered bool
+		data, answered = this.probeReceived(data, destination)
+		if answered {
+			return
+		}
 	}
 
+	data, span := this.traceRoute(data, serviceName, serviceArea)
+	defer span.Finish()
+
 	if destination != "" {
 		//The destination is the vnet
 		if destination == this.vnetUuid {
@@ -256,7 +401,13 @@ func (this *VNet) HandleData(data []byte, vnic ifs.IVNic) {
 
 		if destination == ifs.DESTINATION_Single {
 			destination = this.switchTable.services.serviceFor(serviceName, serviceArea, source, multicastMode)
+			if destination != "" && destination != this.vnetUuid {
+				this.trackRequest(data, destination, multicastMode)
+			}
+		} else {
+			this.trackReply(data, destination)
 		}
+		span.SetAttribute("destination", destination)
 		//Incase the destination is the vnet after the service sele
 		if destination == this.vnetUuid {
 			this.addVnetTask(QService, data, vnic)
@@ -265,7 +416,9 @@ func (this *VNet) HandleData(data []byte, vnic ifs.IVNic) {
 		//The destination is a single port
 		_, p := this.switchTable.conns.getConnection(destination, true)
 		if p == nil {
-			this.Failed(data, vnic, strings.New("Cannot find destination port for ", destination).String())
+			failure := protocol.Failure(protocol.ErrNoDestination, destination).Error()
+			span.SetError(failure)
+			this.Failed(data, vnic, failure)
 			return
 		}
 
@@ -276,16 +429,21 @@ func (this *VNet) HandleData(data []byte, vnic ifs.IVNic) {
 				hp := health.HealthOf(uuid, this.resources)
 				this.sendHealth(hp)
 			}
-			this.Failed(data, vnic, strings.New("Error sending data:", err.Error()).String())
+			span.SetError(err.Error())
+			this.Failed(data, vnic, protocol.Failure(protocol.ErrSendFailed, err.Error()).Error())
 			return
 		}
 	} else {
 		connections := this.switchTable.connectionsForService(serviceName, serviceArea, sourceVnet, multicastMode)
 		this.uniCastToPorts(connections, data)
+		subscribed := this.notifySubscribers(serviceName, serviceArea, data)
 		_, ok := this.vnetServices[serviceName]
 		if ok && source != this.vnetUuid {
 			this.addVnetTask(QService, data, vnic)
 		}
+		if len(connections) == 0 && !subscribed && !ok {
+			this.deadLetter(data, ReasonNoInstances)
+		}
 		return
 	}
 }
@@ -301,10 +459,14 @@ func (this *VNet) uniCastToPorts(connections map[string]ifs.IVNic, data []byte)
 func (this *VNet) ShutdownVNic(vnic ifs.IVNic) {
 	uuid := vnic.Resources().SysConfig().RemoteUuid
 	removed := map[string]string{uuid: ""}
-	this.switchTable.routeTable.removeRoutes(removed)
-	this.switchTable.services.removeService(removed)
+	removedRoutes := this.switchTable.routeTable.removeRoutes(removed)
+	removedServices := this.switchTable.services.removeService(removed)
+	this.breakers.remove(uuid)
 	this.removeHealth(removed)
 	this.publishRemovedRoutes(removed)
+	this.vnicDisconnected(uuid, vnic.Resources().SysConfig().RemoteAlias)
+	this.routesChanged(events.RouteRemoved, removedRoutes)
+	this.servicesRemoved(removedServices)
 }
 
 // Resources returns the IResources instance containing configuration,
@@ -314,15 +476,42 @@ func (this *VNet) Resources() ifs.IResources {
 }
 
 func (this *VNet) sendHealth(hp *l8health.L8Health) {
-	vnetUuid := this.resources.SysConfig().LocalUuid
-	nextId := this.protocol.NextMessageNumber()
-	h, _ := this.protocol.CreateMessageFor("", health.ServiceName, 0, ifs.P1, ifs.M_All,
-		ifs.POST, vnetUuid, vnetUuid, object.New(nil, hp), false, false,
-		nextId, ifs.NotATransaction, "", "",
-		-1, -1, -1, -1, -1, 0, false, "")
+	h, _ := this.protocol.Create(this.newMessage(health.ServiceName, 0, ifs.POST), object.New(nil, hp))
 	this.addVnetTask(QHandleData, h, this.vnic)
 }
 
+// StartMetrics exposes the metrics registry for scraping on the given port under /metrics.
+func (this *VNet) StartMetrics(port uint32) error {
+	server, err := metrics.GetGlobalRegistry(this.resources.Logger()).Serve(port)
+	if err != nil {
+		return err
+	}
+	this.metricsServer = server
+	return nil
+}
+
+// StartStatistics enables the statistics of the messages this VNet switched and writes
+// them periodically per the config, until the VNet is shut down or the statistics are
+// started again.
+func (this *VNet) StartStatistics(config *protocol.StatisticsConfig) error {
+	sink, err := protocol.NewStatisticsSink(this.protocol.Statistics(), config, this.resources.Logger())
+	if err != nil {
+		return err
+	}
+	this.protocol.Statistics().Enable(true)
+	// a sink started before is stopped first, so its goroutine does not keep writing
+	if previous := this.statisticsSink.Swap(sink); previous != nil {
+		previous.Stop()
+	}
+	sink.Start()
+	return nil
+}
+
+// Statistics returns the statistics of the messages this VNet created & switched.
+func (this *VNet) Statistics() *protocol.MessageStatistics {
+	return this.protocol.Statistics()
+}
+
 // VnetVnic returns the internal VNic used by the VNet for its own service communication.
 func (this *VNet) VnetVnic() ifs.IVNic {
 	return this.vnic
@@ -14,8 +14,7 @@
 package vnet
 
 import (
-	"fmt"
-
+	"github.com/saichler/l8bus/go/overlay/events"
 	"github.com/saichler/l8bus/go/overlay/health"
 	"github.com/saichler/l8srlz/go/serialize/object"
 	"github.com/saichler/l8types/go/ifs"
@@ -43,30 +42,37 @@ func (this *VNet) systemMessageReceived(data []byte, vnic ifs.IVNic) {
 		return
 	}
 
-	systemMessage := pb.Element().(*l8system.L8SystemMessage)
+	systemMessage, ok := pb.Element().(*l8system.L8SystemMessage)
+	if !ok {
+		this.resources.Logger().Error("Unexpected system message from ", msg.Source())
+		return
+	}
 
 	switch systemMessage.Action {
 	case l8system.L8SystemAction_Routes_Add:
 		added := this.switchTable.routeTable.addRoutes(systemMessage.GetRouteTable().Rows)
 		this.routesAdded(added)
+		this.routesChanged(events.RouteAdded, added)
 		return
 	case l8system.L8SystemAction_Routes_Remove:
 		removed := this.switchTable.routeTable.removeRoutes(systemMessage.GetRouteTable().Rows)
 		this.routesRemoved(removed)
+		this.routesChanged(events.RouteRemoved, removed)
 		return
 	case l8system.L8SystemAction_Service_Add:
 		serviceData := systemMessage.GetServiceData()
-		fmt.Printf("[VNET-RECV-SVCADD] vnet=%s service=(%s,%d) uuid=%s publish=%v\n",
-			this.resources.SysConfig().LocalAlias, serviceData.ServiceName,
-			serviceData.ServiceArea, serviceData.ServiceUuid, systemMessage.Publish)
-		this.switchTable.services.addService(serviceData)
+		this.resources.Logger().Debug("Service ", serviceData.ServiceName, " area ", serviceData.ServiceArea,
+			" added by ", serviceData.ServiceUuid, ", publish ", systemMessage.Publish)
+		if this.switchTable.services.addService(serviceData) {
+			this.serviceAdded(serviceData)
+		}
 		if systemMessage.Publish {
 			this.publishSystemMessage(systemMessage)
 			//go health.AddServiceToHealth(msg.Source(), serviceData.ServiceName, serviceData.ServiceArea, this.resources)
 		}
 		return
 	default:
-		panic("unknown system action")
+		this.resources.Logger().Error("Unknown system action ", systemMessage.Action, " from ", msg.Source())
 	}
 }
 
@@ -80,7 +86,10 @@ func (this *VNet) routesAdded(added map[string]string) {
 // routesRemoved handles cleanup when routes are removed, including service deregistration and health removal.
 func (this *VNet) routesRemoved(removed map[string]string) {
 	if len(removed) > 0 {
-		this.switchTable.services.removeService(removed)
+		this.servicesRemoved(this.switchTable.services.removeService(removed))
+		for uuid := range removed {
+			this.breakers.remove(uuid)
+		}
 		this.publishRemovedRoutes(removed)
 		this.removeHealth(removed)
 	}
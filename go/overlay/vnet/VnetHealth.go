@@ -22,28 +22,32 @@ import (
 	"github.com/saichler/l8types/go/types/l8health"
 	"github.com/saichler/l8types/go/types/l8sysconfig"
 	"github.com/saichler/l8types/go/types/l8system"
-	"github.com/saichler/l8utils/go/utils/ipsegment"
 )
 
 // addHealthForVNic registers a VNic's health information in the health service,
 // creating or updating the health record based on the VNic's configuration.
-func (this *VNet) addHealthForVNic(config *l8sysconfig.L8SysConfig) {
+// isLocal indicates the VNic is on this machine.
+func (this *VNet) addHealthForVNic(config *l8sysconfig.L8SysConfig, isLocal bool) {
 	serviceData := &l8system.L8ServiceData{}
 	serviceData.ServiceName = health.ServiceName
 	serviceData.ServiceArea = int32(health.ServiceAreaByConfig(config))
 	serviceData.ServiceUuid = config.RemoteUuid
-	this.switchTable.services.addService(serviceData)
+	if this.switchTable.services.addService(serviceData) {
+		this.serviceAdded(serviceData)
+	}
 
 	sysGroupData := &l8system.L8ServiceData{}
 	sysGroupData.ServiceName = ifs.SystemServiceGroup
 	sysGroupData.ServiceArea = 0
 	sysGroupData.ServiceUuid = config.RemoteUuid
-	this.switchTable.services.addService(sysGroupData)
+	if this.switchTable.services.addService(sysGroupData) {
+		this.serviceAdded(sysGroupData)
+	}
 
 	hp := health.HealthOf(config.RemoteUuid, this.resources)
 	hs, _ := health.HealthService(this.resources)
 	if hp == nil {
-		hp = this.newHealth(config)
+		hp = this.newHealth(config, isLocal)
 		hs.Post(object.New(nil, hp), nil)
 	} else {
 		hp.Services = config.Services
@@ -52,7 +56,7 @@ func (this *VNet) addHealthForVNic(config *l8sysconfig.L8SysConfig) {
 }
 
 // newHealth creates a new L8Health record from a VNic's system configuration.
-func (this *VNet) newHealth(config *l8sysconfig.L8SysConfig) *l8health.L8Health {
+func (this *VNet) newHealth(config *l8sysconfig.L8SysConfig, isLocal bool) *l8health.L8Health {
 	hp := &l8health.L8Health{}
 	hp.Alias = config.RemoteAlias
 	hp.AUuid = config.RemoteUuid
@@ -66,7 +70,6 @@ func (this *VNet) newHealth(config *l8sysconfig.L8SysConfig) *l8health.L8Health
 	hp.Stats.RxDataCont = -1
 	hp.Stats.TxDataCount = -1
 	hp.Stats.MemoryUsage = 1
-	isLocal := ipsegment.IpSegment.IsLocal(config.Address)
 	hp.IsVnet = config.ForceExternal || !isLocal
 
 	if !hp.IsVnet {
@@ -14,6 +14,7 @@
 package vnet
 
 import (
+	"github.com/saichler/l8srlz/go/serialize/object"
 	"github.com/saichler/l8types/go/ifs"
 )
 
@@ -42,7 +43,6 @@ func (this *VNet) vnetServiceRequest(data []byte, vnic ifs.IVNic) {
 	if msg.Action() == ifs.Notify {
 		resp := this.resources.Services().Notify(pb, vnic, msg, false)
 		if resp != nil && resp.Error() != nil {
-			panic(pb)
 			this.resources.Logger().Error(resp.Error())
 		}
 		return
@@ -53,6 +53,14 @@ func (this *VNet) vnetServiceRequest(data []byte, vnic ifs.IVNic) {
 		return
 	}
 	var resp ifs.IElements
+	err = this.authorized(msg.ServiceName(), msg.Action(), pb, msg.Source(), msg.AAAId())
+	if err != nil {
+		this.resources.Logger().Warning(err.Error())
+		if msg.Request() {
+			vnic.Reply(msg, object.NewError(err.Error()))
+		}
+		return
+	}
 	if this.internal(msg) {
 		resp = this.resources.Services().Handle(pb, msg.Action(), msg, this.vnic)
 	} else {
@@ -79,6 +87,11 @@ func (this *VNet) LocalCount() int32 {
 	return this.switchTable.conns.sizeInternal.Load()
 }
 
+// ServiceLeader returns the UUID this VNet currently selects as the leader for the service.
+func (this *VNet) ServiceLeader(serviceName string, serviceArea byte) string {
+	return this.switchTable.services.serviceFor(serviceName, serviceArea, this.vnetUuid, ifs.M_Leader)
+}
+
 // internal checks if a message should be handled internally by the VNet's internal VNic.
 func (this *VNet) internal(msg *ifs.Message) bool {
 	if msg.Action() >= ifs.MapR_POST && msg.Action() <= ifs.MapR_GET {
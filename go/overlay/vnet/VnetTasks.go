@@ -1,8 +1,15 @@
 package vnet
 
 import (
+	"fmt"
+	"runtime/debug"
+
+	"github.com/saichler/l8bus/go/overlay/metrics"
+	"github.com/saichler/l8bus/go/overlay/protocol"
+	"github.com/saichler/l8srlz/go/serialize/object"
 	"github.com/saichler/l8types/go/ifs"
 	"github.com/saichler/l8utils/go/utils/queues"
+	"github.com/saichler/l8utils/go/utils/strings"
 )
 
 type VnetTask struct {
@@ -40,7 +47,65 @@ func (this *VNet) processTasks(queue *queues.Queue, f func(data []byte, vnic ifs
 		tsk := queue.Next()
 		if tsk != nil {
 			task := tsk.(*VnetTask)
-			f(task.data, task.vnic)
+			this.runTask(task, f)
+		}
+	}
+}
+
+// runTask processes a single task, an expired message is dropped and a panic while
+// processing it fails the message back to its sender instead of taking down the VNet.
+func (this *VNet) runTask(task *VnetTask, f func(data []byte, vnic ifs.IVNic)) {
+	defer func() {
+		if r := recover(); r != nil {
+			this.taskPanicked(task, r)
+		}
+	}()
+	if protocol.Expired(task.data) {
+		this.MessageExpired(task.data)
+		return
+	}
+	f(task.data, task.vnic)
+}
+
+// taskPanicked logs the panic of a task with its stack and counts it, the sender of a
+// request gets the failure as its reply and the sender of any other message, unless it
+// is a reply or a failure itself, gets a failed message.
+func (this *VNet) taskPanicked(task *VnetTask, r interface{}) {
+	serviceName := ""
+	var serviceArea byte
+	header, err := protocol.HeaderOf(task.data)
+	if err == nil {
+		serviceName, serviceArea = header.ServiceName, header.ServiceArea
+	}
+	failure := strings.New("Handling of ", serviceName, " area ", int(serviceArea), " panicked: ", fmt.Sprint(r)).String()
+	this.resources.Logger().Error(failure, "\n", string(debug.Stack()))
+	metrics.GetGlobalRegistry(this.resources.Logger()).Counter("layer8_handler_panics_total",
+		map[string]string{"vnic_id": this.vnetUuid, "service": serviceName}).Inc()
+
+	if task.data == nil || task.vnic == nil {
+		return
+	}
+	this.failTask(task, failure)
+}
+
+// failTask fails a panicked task back to its sender. It parses the same data that just
+// panicked, so a second panic is only logged instead of taking down the VNet.
+func (this *VNet) failTask(task *VnetTask, failure string) {
+	defer func() {
+		if r := recover(); r != nil {
+			this.resources.Logger().Error("Failing a panicked task panicked: ", fmt.Sprint(r), "\n", string(debug.Stack()))
 		}
+	}()
+	msg, err := this.protocol.MessageOf(task.data)
+	if err != nil || msg.Reply() || msg.FailMessage() != "" {
+		return
+	}
+	if msg.Request() {
+		err = task.vnic.Reply(msg, object.NewError(failure))
+		if err != nil {
+			this.resources.Logger().Error(err)
+		}
+		return
 	}
+	this.Failed(task.data, task.vnic, failure)
 }
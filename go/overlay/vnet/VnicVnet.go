@@ -16,6 +16,7 @@ package vnet
 import (
 	"fmt"
 	"github.com/saichler/l8bus/go/overlay/health"
+	"github.com/saichler/l8bus/go/overlay/metrics"
 	"github.com/saichler/l8bus/go/overlay/vnic"
 	"github.com/saichler/l8srlz/go/serialize/object"
 	"github.com/saichler/l8types/go/ifs"
@@ -35,20 +36,17 @@ func newVnicVnet(vnet *VNet) *VnicVnet {
 	return &VnicVnet{vnet: vnet}
 }
 
-// Start is not implemented for VnicVnet as it operates through the parent VNet.
+// Start does nothing for VnicVnet as it operates through the parent VNet.
 func (this *VnicVnet) Start() {
-	panic("implement me")
 }
 
-// Shutdown is not implemented for VnicVnet as it operates through the parent VNet.
+// Shutdown does nothing for VnicVnet as it operates through the parent VNet.
 func (this *VnicVnet) Shutdown() {
-	panic("implement me")
 }
 
-// Name is not implemented for VnicVnet.
+// Name returns the alias of the parent VNet.
 func (this *VnicVnet) Name() string {
-	panic("implement me")
-	return ""
+	return this.vnet.resources.SysConfig().LocalAlias
 }
 
 // SendMessage is not implemented for VnicVnet; use Unicast or Multicast instead.
@@ -60,10 +58,7 @@ func (this *VnicVnet) SendMessage(data []byte) error {
 // Unicast sends a message to a specific destination VNic by UUID.
 func (this *VnicVnet) Unicast(destination string, serviceName string, serviceArea byte, action ifs.Action, data interface{}) error {
 	elems := object.New(nil, data)
-	bts, err := this.vnet.protocol.CreateMessageFor(destination, serviceName, serviceArea, ifs.P1, ifs.M_All, action,
-		this.Resources().SysConfig().LocalUuid, this.Resources().SysConfig().LocalUuid, elems,
-		false, false, this.vnet.protocol.NextMessageNumber(), ifs.NotATransaction,
-		"", "", -1, -1, -1, -1, -1, 0, false, "")
+	bts, err := this.vnet.protocol.Create(this.vnet.newMessage(serviceName, serviceArea, action).To(destination), elems)
 	if err != nil {
 		return err
 	}
@@ -127,10 +122,8 @@ func (this *VnicVnet) Multicast(serviceName string, serviceArea byte, action ifs
 		connections = this.vnet.switchTable.conns.allExternalVnets()
 	}
 	for uuid, connection := range connections {
-		data, err = this.vnet.protocol.CreateMessageFor(uuid, serviceName, serviceArea, ifs.P1, ifs.M_All, action,
-			myUuid, uuid, object.New(nil, any), false, false, this.vnet.protocol.NextMessageNumber(),
-			ifs.NotATransaction, "", "", -1, -1, -1, -1,
-			-1, 0, false, "")
+		data, err = this.vnet.protocol.Create(this.vnet.newMessage(serviceName, serviceArea, action).To(uuid).
+			From(myUuid, uuid), object.New(nil, any))
 		if err != nil {
 			continue
 		}
@@ -139,52 +132,47 @@ func (this *VnicVnet) Multicast(serviceName string, serviceArea byte, action ifs
 			err = e
 		}
 	}
-	data, err = this.vnet.protocol.CreateMessageFor(myUuid, serviceName, serviceArea, ifs.P1, ifs.M_All, action,
-		myUuid, myUuid, object.New(nil, any), false, false, this.vnet.protocol.NextMessageNumber(),
-		ifs.NotATransaction, "", "", -1, -1, -1, -1,
-		-1, 0, false, "")
+	data, err = this.vnet.protocol.Create(this.vnet.newMessage(serviceName, serviceArea, action).To(myUuid), object.New(nil, any))
 	this.vnet.addVnetTask(QHandleData, data, this)
 	return err
 }
 
+// notSupported is the error of the send modes a VNet does not use for its own messages,
+// it addresses vnics by their uuid or multicasts to all of them.
+func notSupported(method string) error {
+	return fmt.Errorf("%s is not supported by the VNet", method)
+}
+
 func (this *VnicVnet) RoundRobin(serviceName string, area byte, action ifs.Action, data interface{}) error {
-	panic("implement me")
-	return nil
+	return notSupported("RoundRobin")
 }
 
 func (this *VnicVnet) RoundRobinRequest(serviceName string, area byte, action ifs.Action, data interface{}, timeout int, returnAttributes ...string) ifs.IElements {
-	panic("implement me")
-	return nil
+	return object.NewError(notSupported("RoundRobinRequest").Error())
 }
 
 func (this *VnicVnet) Proximity(serviceName string, area byte, action ifs.Action, data interface{}) error {
-	panic("implement me")
-	return nil
+	return notSupported("Proximity")
 }
 
 func (this *VnicVnet) ProximityRequest(serviceName string, area byte, action ifs.Action, data interface{}, timeout int, returnAttributes ...string) ifs.IElements {
-	panic("implement me")
-	return nil
+	return object.NewError(notSupported("ProximityRequest").Error())
 }
 
 func (this *VnicVnet) Leader(serviceName string, area byte, action ifs.Action, data interface{}) error {
-	panic("implement me")
-	return nil
+	return notSupported("Leader")
 }
 
 func (this *VnicVnet) LeaderRequest(serviceName string, area byte, action ifs.Action, data interface{}, timeout int, returnAttributes ...string) ifs.IElements {
-	panic("implement me")
-	return nil
+	return object.NewError(notSupported("LeaderRequest").Error())
 }
 
 func (this *VnicVnet) Local(serviceName string, area byte, action ifs.Action, data interface{}) error {
-	panic("implement me")
-	return nil
+	return notSupported("Local")
 }
 
 func (this *VnicVnet) LocalRequest(serviceName string, area byte, action ifs.Action, data interface{}, timeout int, returnAttributes ...string) ifs.IElements {
-	panic("implement me")
-	return nil
+	return object.NewError(notSupported("LocalRequest").Error())
 }
 
 // Forward sends a message to a destination and returns the response.
@@ -220,6 +208,11 @@ func (this *VnicVnet) ServiceAPI(serviceName string, area byte) ifs.ServiceAPI {
 	return vnic.NewAPI(serviceName, area, this, false, false)
 }
 
+// CircuitBreakers returns the service circuit breakers of the parent VNet.
+func (this *VnicVnet) CircuitBreakers() *metrics.CircuitBreakerManager {
+	return this.vnet.CircuitBreakers()
+}
+
 // Resources returns the IResources from the parent VNet.
 func (this *VnicVnet) Resources() ifs.IResources {
 	return this.vnet.resources
@@ -244,8 +237,9 @@ func (this *VnicVnet) NotifyServiceAdded(serviceNames []string, serviceArea byte
 	return nil
 }
 
+// NotifyServiceRemoved does nothing, the VNet removes services from its switch table
+// when their vnics disconnect.
 func (this *VnicVnet) NotifyServiceRemoved(serviceName string, area byte) error {
-	panic("implement me")
 	return nil
 }
 
@@ -254,13 +248,13 @@ func (this *VnicVnet) PropertyChangeNotification(set *l8notify.L8NotificationSet
 	this.vnet.PropertyChangeNotification(set)
 }
 
+// WaitForConnection returns immediately as the VNet is connected to itself.
 func (this *VnicVnet) WaitForConnection() {
-	panic("implement me")
 }
 
+// Running returns true while the parent VNet is running.
 func (this *VnicVnet) Running() bool {
-	panic("implement me")
-	return false
+	return this.vnet.running
 }
 
 // SetResponse sets the response for a pending request on the source connection.
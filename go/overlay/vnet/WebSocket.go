@@ -0,0 +1,101 @@
+// © 2025 Sharon Aicler (saichler@gmail.com)
+//
+// Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vnet
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+	stdstrings "strings"
+
+	"github.com/gorilla/websocket"
+	"github.com/saichler/l8bus/go/overlay/gateway"
+	"github.com/saichler/l8bus/go/overlay/metrics"
+	"github.com/saichler/l8bus/go/overlay/transport"
+	"github.com/saichler/l8utils/go/utils/strings"
+)
+
+// WebSocket endpoint paths
+const (
+	WebSocketPath     = "/bus"
+	WebSocketJsonPath = "/bus/json"
+	HttpGatewayPath   = "/api"
+	MetricsPath       = "/metrics"
+)
+
+// WebSocketOrigins are the browser origins, in addition to the origin of the vnet itself,
+// allowed to open a websocket to the vnet, e.g. "https://ui.example.com".
+var WebSocketOrigins = []string{}
+
+// WebSocketCheckOrigin decides which browser origins may open a websocket to the vnet, by
+// default the origin of the vnet and WebSocketOrigins. Clients that are not browsers send
+// no origin and are allowed, they are authenticated by the security provider.
+var WebSocketCheckOrigin = func(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err == nil && stdstrings.EqualFold(u.Host, r.Host) {
+		return true
+	}
+	for _, allowed := range WebSocketOrigins {
+		if stdstrings.EqualFold(origin, allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+// StartWebSocket serves the websocket endpoint of this VNet on the given port.
+// WebSocketPath carries the same framed messages as the vnet port, so a client implementing
+// the framing joins the overlay as a vnic and goes through the same security provider handshake.
+// WebSocketJsonPath serves JSON requests and notification subscriptions and HttpGatewayPath
+// serves METHOD /api/service/area requests, see the gateway package. MetricsPath serves the
+// metrics registry for scraping.
+func (this *VNet) StartWebSocket(port uint32) error {
+	listener, err := net.Listen("tcp", strings.New(":", int(port)).String())
+	if err != nil {
+		return this.resources.Logger().Error("Unable to bind websocket to port ", port, " ", err.Error())
+	}
+	upgrader := &websocket.Upgrader{CheckOrigin: WebSocketCheckOrigin}
+	mux := http.NewServeMux()
+	mux.HandleFunc(WebSocketPath, func(w http.ResponseWriter, r *http.Request) {
+		ws, e := upgrader.Upgrade(w, r, nil)
+		if e != nil {
+			this.resources.Logger().Error("Failed to upgrade websocket: ", e.Error())
+			return
+		}
+		this.connect(transport.NewWebSocketConn(ws))
+	})
+	mux.HandleFunc(WebSocketJsonPath, func(w http.ResponseWriter, r *http.Request) {
+		token := r.URL.Query().Get("token")
+		if !gateway.Authenticate(this.resources, token) {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		ws, e := upgrader.Upgrade(w, r, nil)
+		if e != nil {
+			this.resources.Logger().Error("Failed to upgrade websocket: ", e.Error())
+			return
+		}
+		gateway.NewJsonSession(ws, this, token).Serve()
+	})
+	mux.Handle(MetricsPath, metrics.GetGlobalRegistry(this.resources.Logger()).Handler())
+	mux.Handle(HttpGatewayPath+"/", http.StripPrefix(HttpGatewayPath, gateway.NewHttpGateway(this)))
+	this.webServer = &http.Server{Handler: mux}
+	go this.webServer.Serve(listener)
+	this.resources.Logger().Debug("Websocket endpoint listening on port ", port)
+	return nil
+}
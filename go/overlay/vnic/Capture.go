@@ -0,0 +1,53 @@
+// © 2025 Sharon Aicler (saichler@gmail.com)
+//
+// Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vnic
+
+import (
+	"github.com/saichler/l8bus/go/overlay/capture"
+)
+
+// StartCapture records the frames this vnic receives & writes to its socket into the
+// capture file, replacing a running capture. A nil filter captures all the frames.
+func (this *VirtualNetworkInterface) StartCapture(filename string, filter *capture.Filter) error {
+	writer, err := capture.NewWriter(filename, filter)
+	if err != nil {
+		return err
+	}
+	previous := this.captureWriter.Swap(writer)
+	if previous != nil {
+		previous.Close()
+	}
+	return nil
+}
+
+// StopCapture stops the running capture and closes its file.
+func (this *VirtualNetworkInterface) StopCapture() error {
+	writer := this.captureWriter.Swap(nil)
+	if writer != nil {
+		return writer.Close()
+	}
+	return nil
+}
+
+// captureFrame records a frame when a capture is running
+func (this *VirtualNetworkInterface) captureFrame(direction capture.Direction, data []byte) {
+	writer := this.captureWriter.Load()
+	if writer == nil {
+		return
+	}
+	err := writer.Capture(direction, this.resources.SysConfig().RemoteUuid, data)
+	if err != nil {
+		this.resources.Logger().Error("Failed to capture frame: ", err.Error())
+	}
+}
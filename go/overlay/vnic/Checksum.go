@@ -0,0 +1,64 @@
+// © 2025 Sharon Aicler (saichler@gmail.com)
+//
+// Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vnic
+
+import (
+	"errors"
+
+	"github.com/saichler/l8bus/go/overlay/protocol"
+)
+
+// SetChecksums enables or disables the frame checksum on the connection of this vnic, it
+// is used once the other side enabled it as well. It is called before the vnic starts.
+func (this *VirtualNetworkInterface) SetChecksums(enabled bool) {
+	this.capabilities = protocol.WithCapability(this.capabilities, protocol.CapChecksum, enabled)
+}
+
+// verifyFrame checks the checksum of a frame read from a connection that negotiated
+// checksums and returns the frame without it. A frame without a checksum is accepted
+// until the peer sent the first checksum, as it may not have received the hello yet.
+func (this *VirtualNetworkInterface) verifyFrame(data []byte) ([]byte, error) {
+	if !this.Supports(protocol.CapChecksum) {
+		return data, nil
+	}
+	frame, present, err := protocol.VerifyChecksum(data)
+	if err != nil {
+		return nil, err
+	}
+	if present {
+		this.checksummed.Store(true)
+		return frame, nil
+	}
+	if this.checksummed.Load() {
+		return nil, errors.New("Frame is missing its checksum")
+	}
+	return data, nil
+}
+
+// frameCorrupted records a frame that failed its checksum
+func (this *VirtualNetworkInterface) frameCorrupted(err error) {
+	this.corruptedFrames.Add(1)
+	this.RecordError()
+	if this.metricsRegistry != nil {
+		this.metricsRegistry.Counter("layer8_corrupted_frames_total",
+			map[string]string{"vnic_id": this.resources.SysConfig().LocalUuid}).Inc()
+	}
+	this.resources.Logger().Warning("Dropped a corrupted frame from ", this.resources.SysConfig().RemoteAlias, ": ", err.Error())
+}
+
+// CorruptedFrames returns the number of frames received on this vnic that failed their
+// checksum
+func (this *VirtualNetworkInterface) CorruptedFrames() int64 {
+	return this.corruptedFrames.Load()
+}
@@ -0,0 +1,126 @@
+// © 2025 Sharon Aicler (saichler@gmail.com)
+//
+// Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vnic
+
+import (
+	"errors"
+	"time"
+
+	"github.com/saichler/l8bus/go/overlay/protocol"
+	"github.com/saichler/l8srlz/go/serialize/object"
+	"github.com/saichler/l8types/go/ifs"
+	"github.com/saichler/l8types/go/types/l8health"
+	"github.com/saichler/l8utils/go/utils/strings"
+)
+
+// DefaultMaxHops is the number of vnets a traceroute probes before giving up
+const DefaultMaxHops = 16
+
+// Hop is the node that answered a probe and the round trip time to it
+type Hop struct {
+	Uuid   string
+	Alias  string
+	IsVnet bool
+	Rtt    time.Duration
+}
+
+// Ping probes the destination, a vnic or a vnet, and returns its round trip time.
+func (this *VirtualNetworkInterface) Ping(destination string, timeoutSeconds int) (*Hop, error) {
+	return this.probe(destination, DefaultMaxHops, timeoutSeconds)
+}
+
+// Traceroute probes the destination with an increasing hop limit, so every vnet on the
+// path answers with its alias, uuid and round trip time, up to the destination itself.
+// The hops answered so far are returned along with the error of the first failed probe.
+func (this *VirtualNetworkInterface) Traceroute(destination string, timeoutSeconds int) ([]*Hop, error) {
+	hops := make([]*Hop, 0)
+	for limit := 1; limit <= DefaultMaxHops; limit++ {
+		hop, err := this.probe(destination, byte(limit), timeoutSeconds)
+		if err != nil {
+			return hops, err
+		}
+		hops = append(hops, hop)
+		if hop.Uuid == destination {
+			return hops, nil
+		}
+	}
+	return hops, errors.New(strings.New("Destination ", destination, " is more than ", DefaultMaxHops, " hops away").String())
+}
+
+// probe sends a probe over the system channel with the given hop limit, every vnet
+// decrements the limit and answers when it expires or when the vnet is the destination.
+func (this *VirtualNetworkInterface) probe(destination string, hopLimit byte, timeoutSeconds int) (*Hop, error) {
+	request, err := this.requests.NewRequest(this.protocol.NextMessageNumber(), this.resources.SysConfig().LocalUuid, timeoutSeconds, this.resources.Logger())
+	if err != nil {
+		return nil, err
+	}
+	defer this.requests.DelRequest(request.MsgNum(), request.MsgSource())
+
+	opts := protocol.NewMessage(ifs.SysMsg, ifs.SysAreaPrimary, ifs.GET).To(destination).
+		From(this.resources.SysConfig().LocalUuid, this.resources.SysConfig().RemoteUuid).WithPriority(ifs.P1).
+		AsRequest(int64(timeoutSeconds)).WithSequence(request.MsgNum()).
+		WithExtension(protocol.Ext_Probe, []byte{hopLimit})
+	data, err := this.protocol.Create(opts, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	err = this.SendMessage(data)
+	if err != nil {
+		return nil, err
+	}
+	request.Wait()
+	rtt := time.Since(start)
+	resp := request.Response()
+	if resp == nil {
+		return nil, errors.New(strings.New("Probe to ", destination, " timed out").String())
+	}
+	if resp.Error() != nil {
+		return nil, resp.Error()
+	}
+	hp, ok := resp.Element().(*l8health.L8Health)
+	if !ok {
+		return nil, errors.New(strings.New("Unexpected probe response from ", destination).String())
+	}
+	return &Hop{Uuid: hp.AUuid, Alias: hp.Alias, IsVnet: hp.IsVnet, Rtt: rtt}, nil
+}
+
+// probeReceived answers a probe destined to this vnic and completes the probes that
+// a vnet failed to deliver. It returns true if the message was a probe.
+func (this *VirtualNetworkInterface) probeReceived(data []byte, msg *ifs.Message) bool {
+	if msg.ServiceName() != ifs.SysMsg {
+		return false
+	}
+	if msg.Reply() && msg.FailMessage() != "" {
+		request := this.requests.GetRequest(msg.Sequence(), this.resources.SysConfig().LocalUuid)
+		request.SetResponse(object.NewError(msg.FailMessage()))
+		return true
+	}
+	if !msg.Request() {
+		return false
+	}
+	_, ok := protocol.ExtensionOf(data, protocol.Ext_Probe)
+	if !ok {
+		return false
+	}
+	hp := &l8health.L8Health{}
+	hp.AUuid = this.resources.SysConfig().LocalUuid
+	hp.Alias = this.resources.SysConfig().LocalAlias
+	err := this.Reply(msg, object.New(nil, hp))
+	if err != nil {
+		this.resources.Logger().Error(err)
+	}
+	return true
+}
@@ -0,0 +1,46 @@
+// © 2025 Sharon Aicler (saichler@gmail.com)
+//
+// Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vnic
+
+import (
+	"github.com/saichler/l8bus/go/overlay/protocol"
+	"github.com/saichler/l8srlz/go/serialize/object"
+)
+
+// expiryListener is the VNet of a connection, it fails the expired requests back to the
+// vnics that sent them
+type expiryListener interface {
+	MessageExpired(data []byte)
+}
+
+// messageExpired counts a message dropped from the TX queue as it expired, a request of
+// this vnic fails right away and a request forwarded by the VNet is failed by it.
+func (this *VirtualNetworkInterface) messageExpired(data []byte) {
+	if this.metricsRegistry != nil {
+		this.metricsRegistry.Counter("layer8_expired_messages_total",
+			map[string]string{"vnic_id": this.resources.SysConfig().LocalUuid}).Inc()
+	}
+	listener, ok := this.resources.DataListener().(expiryListener)
+	if ok {
+		listener.MessageExpired(data)
+		return
+	}
+	msg, err := this.protocol.MessageOf(data)
+	if err != nil || !msg.Request() || msg.Source() != this.resources.SysConfig().LocalUuid {
+		return
+	}
+	this.resources.Logger().Debug("Dropped an expired request to ", msg.ServiceName())
+	request := this.requests.GetRequest(msg.Sequence(), this.resources.SysConfig().LocalUuid)
+	request.SetResponse(object.NewError(protocol.ExpiredMessage))
+}
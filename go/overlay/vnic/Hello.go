@@ -0,0 +1,151 @@
+// © 2025 Sharon Aicler (saichler@gmail.com)
+//
+// Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vnic
+
+import (
+	"github.com/saichler/l8bus/go/overlay/protocol"
+	"github.com/saichler/l8srlz/go/serialize/object"
+	"github.com/saichler/l8types/go/ifs"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// helloReceiver is a vnic that records the hello of its peer
+type helloReceiver interface {
+	HelloReceived(*protocol.Hello)
+}
+
+// ActivateHello activates the hello service on the vnic, so it can negotiate the version
+// and capabilities with the other side of its connections.
+func ActivateHello(vnic ifs.IVNic) {
+	sla := ifs.NewServiceLevelAgreement(&HelloService{}, protocol.HelloServiceName, protocol.HelloServiceArea, false, nil)
+	vnic.Resources().Services().Activate(sla, vnic)
+}
+
+// SendHello sends the hello of this build to the other side of the connection
+func (this *VirtualNetworkInterface) SendHello(reply bool) error {
+	config := this.resources.SysConfig()
+	hello, err := protocol.NewHello(config.LocalUuid, reply, this.capabilities).ToBytes()
+	if err != nil {
+		return err
+	}
+	opts := protocol.NewMessage(protocol.HelloServiceName, protocol.HelloServiceArea, ifs.POST).To(config.RemoteUuid)
+	if this.IsVNet {
+		opts.From(config.LocalUuid, config.LocalUuid)
+	}
+	opts.WithSequence(this.protocol.NextMessageNumber())
+	return this.components.TX().Unicast(opts, object.New(nil, hello))
+}
+
+// sendHello starts the negotiation with the vnet after connecting to it
+func (this *VirtualNetworkInterface) sendHello() {
+	err := this.SendHello(false)
+	if err != nil {
+		this.resources.Logger().Error("Failed to send hello: ", err.Error())
+	}
+}
+
+// HelloReceived records the peer of a hello sent by the other side of the connection
+// and answers it if it is not an answer itself.
+func (this *VirtualNetworkInterface) HelloReceived(hello *protocol.Hello) {
+	if hello.Uuid != this.resources.SysConfig().RemoteUuid {
+		return
+	}
+	peer := protocol.Negotiate(hello, this.capabilities)
+	this.peer.Store(peer)
+	this.resources.Logger().Debug("Negotiated version ", peer.Version, " with ", this.resources.SysConfig().RemoteAlias)
+	if !hello.Reply {
+		err := this.SendHello(true)
+		if err != nil {
+			this.resources.Logger().Error(err)
+		}
+	}
+}
+
+// SetCapabilities sets the capabilities this vnic announces in its hello, it is called
+// before the vnic starts.
+func (this *VirtualNetworkInterface) SetCapabilities(capabilities []protocol.Capability) {
+	this.capabilities = capabilities
+}
+
+// Capabilities returns the capabilities this vnic announces in its hello
+func (this *VirtualNetworkInterface) Capabilities() []protocol.Capability {
+	return this.capabilities
+}
+
+// Peer returns what was negotiated with the other side of the connection, nil until its
+// hello is received or if it does not send one.
+func (this *VirtualNetworkInterface) Peer() *protocol.Peer {
+	return this.peer.Load()
+}
+
+// Supports returns true if both sides of the connection agreed on the capability
+func (this *VirtualNetworkInterface) Supports(capability protocol.Capability) bool {
+	return this.peer.Load().Supports(capability)
+}
+
+// HelloService receives the hello of the other side of a connection on Post.
+type HelloService struct {
+}
+
+// Activate registers the hello type with the registry when the service starts.
+func (this *HelloService) Activate(sla *ifs.ServiceLevelAgreement, vnic ifs.IVNic) error {
+	vnic.Resources().Registry().Register(&wrapperspb.BytesValue{})
+	return nil
+}
+
+// DeActivate is called when the service is stopped.
+func (this *HelloService) DeActivate() error {
+	return nil
+}
+
+// Post decodes the hello and hands it to the vnic of the connection it was received on.
+func (this *HelloService) Post(pb ifs.IElements, vnic ifs.IVNic) ifs.IElements {
+	hello, err := protocol.HelloOf(pb.Element())
+	if err != nil {
+		vnic.Resources().Logger().Error(err)
+		return nil
+	}
+	receiver, ok := vnic.(helloReceiver)
+	if !ok {
+		return nil
+	}
+	receiver.HelloReceived(hello)
+	return nil
+}
+func (this *HelloService) Put(pb ifs.IElements, vnic ifs.IVNic) ifs.IElements {
+	return nil
+}
+func (this *HelloService) Patch(pb ifs.IElements, vnic ifs.IVNic) ifs.IElements {
+	return nil
+}
+func (this *HelloService) Delete(pb ifs.IElements, vnic ifs.IVNic) ifs.IElements {
+	return nil
+}
+func (this *HelloService) GetCopy(pb ifs.IElements, vnic ifs.IVNic) ifs.IElements {
+	return nil
+}
+func (this *HelloService) Get(pb ifs.IElements, vnic ifs.IVNic) ifs.IElements {
+	return nil
+}
+func (this *HelloService) Failed(pb ifs.IElements, vnic ifs.IVNic, msg *ifs.Message) ifs.IElements {
+	return nil
+}
+
+func (this *HelloService) TransactionConfig() ifs.ITransactionConfig {
+	return nil
+}
+
+func (this *HelloService) WebService() ifs.IWebService {
+	return nil
+}
@@ -0,0 +1,105 @@
+// © 2025 Sharon Aicler (saichler@gmail.com)
+//
+// Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vnic
+
+import (
+	"time"
+
+	"github.com/saichler/l8bus/go/overlay/metrics"
+	"github.com/saichler/l8bus/go/overlay/protocol"
+	"github.com/saichler/l8types/go/ifs"
+)
+
+// trafficCounters are the per message counters, looked up once as they are updated on every message.
+type trafficCounters struct {
+	sent          *metrics.CounterMetric
+	bytesSent     *metrics.CounterMetric
+	received      *metrics.CounterMetric
+	bytesReceived *metrics.CounterMetric
+}
+
+// traffic returns the per message counters of this vnic, they are created on the first
+// message as a vnet port gets its uuid only after it is created.
+func (this *VirtualNetworkInterface) traffic() *trafficCounters {
+	if this.metricsRegistry == nil {
+		return nil
+	}
+	this.trafficOnce.Do(func() {
+		labels := map[string]string{"vnic_id": this.resources.SysConfig().LocalUuid}
+		this.trafficCounters = &trafficCounters{
+			sent:          this.metricsRegistry.Counter("layer8_messages_sent_total", labels),
+			bytesSent:     this.metricsRegistry.Counter("layer8_bytes_sent_total", labels),
+			received:      this.metricsRegistry.Counter("layer8_messages_received_total", labels),
+			bytesReceived: this.metricsRegistry.Counter("layer8_bytes_received_total", labels),
+		}
+	})
+	return this.trafficCounters
+}
+
+// breakerFor returns the circuit breaker guarding requests to the service instance on the
+// destination, or nil when the destination is not a specific uuid. Requests whose destination
+// is selected by the vnet are tracked by the vnet, which routes around open instances.
+func (this *VirtualNetworkInterface) breakerFor(serviceName string, serviceArea byte, destination string) *metrics.CircuitBreaker {
+	if this.circuitBreakerManager == nil || len(destination) != 36 || destination == ifs.DESTINATION_Single {
+		return nil
+	}
+	return this.circuitBreakerManager.ForService(serviceName, serviceArea, destination)
+}
+
+// requestAllowed returns an error if the circuit breaker of the request is open,
+// so the request fails fast instead of waiting for its timeout.
+func (this *VirtualNetworkInterface) requestAllowed(breaker *metrics.CircuitBreaker) error {
+	if breaker == nil {
+		return nil
+	}
+	err := breaker.Allow()
+	if err != nil {
+		this.RecordError()
+	}
+	return err
+}
+
+// requestFailed records a request that could not be sent.
+func (this *VirtualNetworkInterface) requestFailed(breaker *metrics.CircuitBreaker) {
+	this.RecordError()
+	if breaker != nil {
+		breaker.RecordFailure()
+	}
+}
+
+// requestEnded records the outcome of a request, a request with no response has timed out
+// and a request answered with an error has failed. Only a timeout or a failure of the
+// overlay, e.g. a failed message of the vnet, counts against the breaker, an error of the
+// service is an answer of a healthy instance.
+func (this *VirtualNetworkInterface) requestEnded(breaker *metrics.CircuitBreaker, start time.Time, resp ifs.IElements) {
+	if resp == nil {
+		this.RecordTimeout()
+		if breaker != nil {
+			breaker.RecordFailure()
+		}
+		return
+	}
+	this.RecordLatency(time.Since(start).Milliseconds())
+	if resp.Error() != nil {
+		this.RecordError()
+	}
+	if breaker == nil {
+		return
+	}
+	if protocol.FailureOf(resp.Error()) != nil {
+		breaker.RecordFailure()
+		return
+	}
+	breaker.RecordSuccess()
+}
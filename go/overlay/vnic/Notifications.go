@@ -15,7 +15,6 @@ package vnic
 
 import (
 	"github.com/saichler/l8bus/go/overlay/health"
-	"github.com/saichler/l8bus/go/overlay/protocol"
 	"github.com/saichler/l8types/go/ifs"
 	"github.com/saichler/l8types/go/types/l8health"
 	"github.com/saichler/l8types/go/types/l8notify"
@@ -48,7 +47,6 @@ func (this *VirtualNetworkInterface) NotifyServiceRemoved(serviceName string, se
 
 // PropertyChangeNotification broadcasts property change notifications to service subscribers.
 func (this *VirtualNetworkInterface) PropertyChangeNotification(set *l8notify.L8NotificationSet) {
-	protocol.MsgLog.AddLog(set.ServiceName, byte(set.ServiceArea), ifs.Notify)
 	this.Multicast(set.ServiceName, byte(set.ServiceArea), ifs.Notify, set)
 }
 
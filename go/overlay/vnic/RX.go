@@ -14,10 +14,17 @@
 package vnic
 
 import (
+	"fmt"
+	"runtime/debug"
+
+	"github.com/saichler/l8bus/go/overlay/capture"
+	"github.com/saichler/l8bus/go/overlay/protocol"
+	"github.com/saichler/l8bus/go/overlay/tracing"
 	"github.com/saichler/l8srlz/go/serialize/object"
 	"github.com/saichler/l8types/go/ifs"
 	"github.com/saichler/l8types/go/nets"
 	"github.com/saichler/l8utils/go/utils/queues"
+	"github.com/saichler/l8utils/go/utils/strings"
 )
 
 // RX handles incoming message reception for a VNic.
@@ -65,6 +72,7 @@ func (this *RX) readFromSocket() {
 				break
 			}
 			if !this.shuttingDown {
+				this.vnic.RecordError()
 				this.vnic.reconnect()
 				continue
 			} else {
@@ -72,6 +80,17 @@ func (this *RX) readFromSocket() {
 			}
 		}
 		if data != nil {
+			// A corrupted frame is dropped, the stream can no longer be trusted so the
+			// vnic reconnects, or the vnet drops the connection for its peer to reconnect
+			data, err = this.vnic.verifyFrame(data)
+			if err != nil {
+				this.vnic.frameCorrupted(err)
+				if this.vnic.IsVNet {
+					break
+				}
+				this.vnic.reconnect()
+				continue
+			}
 			// If still active, write the data to the RX queue
 			if this.vnic.running {
 				this.rx.Add(data)
@@ -95,80 +114,201 @@ func (this *RX) notifyRawDataListener() {
 		data := this.rx.Next()
 		// If data is not nil
 		if data != nil {
-			this.vnic.healthStatistics.IncrementRx(data)
-			// if there is a dataListener, this is a switch
-			if this.vnic.resources.DataListener() != nil {
-				this.vnic.resources.DataListener().HandleData(data, this.vnic)
-			} else {
-				msg, err := this.vnic.protocol.MessageOf(data)
-				if err != nil {
-					this.vnic.resources.Logger().Error(err)
-					continue
-				}
-				pb, err := this.vnic.protocol.ElementsOf(msg)
-				if err != nil {
-					this.vnic.resources.Logger().Error(err)
-					if msg.Request() {
-						resp := object.NewError(err.Error())
-						err = this.vnic.Reply(msg, resp)
-						if err != nil {
-							this.vnic.resources.Logger().Error(err)
-						}
-					} else if msg.Reply() {
-						resp := object.NewError(err.Error())
-						request := this.vnic.requests.GetRequest(msg.Sequence(), this.vnic.resources.SysConfig().LocalUuid)
-						request.SetResponse(resp)
-					}
-					continue
-				}
-
-				//This is a reply message, should not find a handler
-				//and just notify
-				if msg.Reply() {
-					if msg.FailMessage() != "" {
-						this.handleMessage(msg, pb)
-					} else {
-						request := this.vnic.requests.GetRequest(msg.Sequence(), this.vnic.resources.SysConfig().LocalUuid)
-						request.SetResponse(pb)
-					}
-					continue
-				}
-				// Otherwise call the handler per the action & the type
-				// If Reauest == blocking, hence run in a go routing.
-				if msg.Request() {
-					go this.handleMessage(msg, pb)
-				} else {
-					this.handleMessage(msg, pb)
-				}
-			}
+			this.notifyFrame(data)
 		}
 	}
 	this.vnic.resources.Logger().Debug("ND for ", this.vnic.name, " has Ended")
 	this.vnic.Shutdown()
 }
 
-func (this *RX) handleMessage(msg *ifs.Message, pb ifs.IElements) {
+// notifyFrame hands a frame to the data listener, or parses & handles it. A panic while
+// doing so is handled like the panic of a handler, so a single frame does not end the
+// notifications of the vnic.
+func (this *RX) notifyFrame(data []byte) {
+	var msg *ifs.Message
+	replied := false
+	defer func() {
+		if r := recover(); r != nil {
+			this.framePanicked(data, msg, r, replied)
+		}
+	}()
+	this.vnic.healthStatistics.IncrementRx(data)
+	this.vnic.captureFrame(capture.In, data)
+	this.vnic.RecordMessageReceived(int64(len(data)))
+	// if there is a dataListener, this is a switch
+	if this.vnic.resources.DataListener() != nil {
+		this.vnic.resources.DataListener().HandleData(data, this.vnic)
+		return
+	}
+	trace := tracing.Extract(data)
+	var err error
+	msg, err = this.vnic.protocol.MessageOf(data)
+	if err != nil {
+		this.vnic.resources.Logger().Error(err)
+		return
+	}
+	if this.vnic.probeReceived(data, msg) {
+		return
+	}
+	pb, err := this.vnic.protocol.ElementsOf(msg)
+	if err != nil {
+		this.vnic.resources.Logger().Error(err)
+		if msg.Request() {
+			replied = true
+			resp := object.NewError(err.Error())
+			err = this.vnic.Reply(msg, resp)
+			if err != nil {
+				this.vnic.resources.Logger().Error(err)
+			}
+		} else if msg.Reply() {
+			resp := object.NewError(err.Error())
+			request := this.vnic.requests.GetRequest(msg.Sequence(), this.vnic.resources.SysConfig().LocalUuid)
+			request.SetResponse(resp)
+		}
+		return
+	}
+
+	//This is a reply message, should not find a handler
+	//and just notify
+	if msg.Reply() {
+		if this.vnic.gathered(msg, pb) {
+			return
+		}
+		if msg.FailMessage() != "" {
+			this.handleMessage(msg, pb, trace)
+		} else {
+			request := this.vnic.requests.GetRequest(msg.Sequence(), this.vnic.resources.SysConfig().LocalUuid)
+			request.SetResponse(pb)
+		}
+		return
+	}
+	// Otherwise call the handler per the action & the type
+	// If Reauest == blocking, hence run in a go routing.
+	if msg.Request() {
+		go this.handleMessage(msg, pb, trace)
+	} else {
+		this.handleMessage(msg, pb, trace)
+	}
+}
+
+func (this *RX) handleMessage(msg *ifs.Message, pb ifs.IElements, trace *tracing.TraceContext) {
+	var span *tracing.Span
+	handling, replied := false, false
+	defer func() {
+		if r := recover(); r != nil {
+			failure := this.handlerPanicked(msg, r, replied)
+			if handling {
+				this.vnic.endHandling(msg, trace, span, object.NewError(failure))
+			}
+		}
+	}()
 	if msg.Action() == ifs.Reply {
 		request := this.vnic.requests.GetRequest(msg.Sequence(), this.vnic.resources.SysConfig().LocalUuid)
 		request.SetResponse(pb)
 	} else if msg.Action() == ifs.Notify {
-		resp := this.vnic.resources.Services().Notify(pb, this.vnic, msg, false)
+		span, handling = this.vnic.startHandling(msg, trace), true
+		resp := this.vnic.resources.Services().Notify(pb, this.vnic.handlerVnic(msg), msg, false)
+		handling = false
+		this.vnic.endHandling(msg, trace, span, resp)
 		if resp != nil && resp.Error() != nil {
 			//panic(this.vnic.resources.SysConfig().LocalAlias + " " + resp.Error().Error())
 			this.vnic.resources.Logger().Error(resp.Error())
 		}
 	} else {
 		//Add bool
-		resp := this.vnic.resources.Services().Handle(pb, msg.Action(), msg, this.vnic)
+		span, handling = this.vnic.startHandling(msg, trace), true
+		resp := this.vnic.resources.Services().Handle(pb, msg.Action(), msg, this.vnic.handlerVnic(msg))
 		if resp != nil && resp.Error() != nil {
 			//panic(this.vnic.resources.SysConfig().LocalAlias + " " + resp.Error().Error())
 			this.vnic.resources.Logger().Error(resp.Error())
 		}
 		if msg.Request() {
+			// a panic from here on must not reply a second time
+			replied = true
 			err := this.vnic.Reply(msg, resp)
 			if err != nil {
 				this.vnic.resources.Logger().Error(err)
 			}
 		}
+		handling = false
+		this.vnic.endHandling(msg, trace, span, resp)
+	}
+}
+
+// handlerPanicked logs the panic of a service handler with its stack, counts it and
+// returns the failure. The sender of a request gets the failure as its reply, unless it
+// was replied already, and the sender of any other message, unless it is a reply or a
+// failure itself, gets a failed message.
+func (this *RX) handlerPanicked(msg *ifs.Message, r interface{}, replied bool) string {
+	failure := strings.New("Handler of ", msg.ServiceName(), " area ", int(msg.ServiceArea()), " panicked: ", fmt.Sprint(r)).String()
+	this.panicked(failure, msg.ServiceName())
+	if msg.Request() {
+		if !replied {
+			err := this.vnic.Reply(msg, object.NewError(failure))
+			if err != nil {
+				this.vnic.resources.Logger().Error(err)
+			}
+		}
+		return failure
+	}
+	if msg.Reply() || msg.FailMessage() != "" {
+		return failure
+	}
+	fail := msg.CloneFail(failure, this.vnic.resources.SysConfig().RemoteUuid)
+	data, err := fail.Marshal(nil, this.vnic.resources)
+	if err != nil {
+		this.vnic.resources.Logger().Error(err)
+		return failure
+	}
+	err = this.vnic.SendMessage(data)
+	if err != nil {
+		this.vnic.resources.Logger().Error(err)
+	}
+	return failure
+}
+
+// failedListener is the VNet of a connection, it fails a message back to its sender
+type failedListener interface {
+	Failed(data []byte, vnic ifs.IVNic, failMsg string)
+}
+
+// framePanicked handles the panic of a frame like the panic of its handler, a frame that
+// panicked in the data listener is failed by it and a frame that panicked before its
+// message was parsed is only logged & counted. Failing the frame back may panic again on
+// the same message, so that panic is only logged.
+func (this *RX) framePanicked(data []byte, msg *ifs.Message, r interface{}, replied bool) {
+	defer func() {
+		if r2 := recover(); r2 != nil {
+			this.vnic.resources.Logger().Error("Failing a panicked frame panicked: ", fmt.Sprint(r2), "\n", string(debug.Stack()))
+		}
+	}()
+	if msg != nil {
+		this.handlerPanicked(msg, r, replied)
+		return
+	}
+	serviceName := ""
+	header, err := protocol.HeaderOf(data)
+	if err == nil {
+		serviceName = header.ServiceName
+	}
+	failure := strings.New("Handling of a frame of ", serviceName, " panicked: ", fmt.Sprint(r)).String()
+	this.panicked(failure, serviceName)
+	listener, ok := this.vnic.resources.DataListener().(failedListener)
+	if !ok {
+		return
+	}
+	msg, err = this.vnic.protocol.MessageOf(data)
+	if err != nil || msg.Reply() || msg.FailMessage() != "" {
+		return
+	}
+	listener.Failed(data, this.vnic, failure)
+}
+
+// panicked logs a panic with its stack and counts it per service
+func (this *RX) panicked(failure, serviceName string) {
+	this.vnic.resources.Logger().Error(failure, "\n", string(debug.Stack()))
+	if this.vnic.metricsRegistry != nil {
+		this.vnic.metricsRegistry.Counter("layer8_handler_panics_total",
+			map[string]string{"vnic_id": this.vnic.resources.SysConfig().LocalUuid, "service": serviceName}).Inc()
 	}
 }
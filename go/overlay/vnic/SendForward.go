@@ -14,13 +14,13 @@
 package vnic
 
 import (
-	"reflect"
+	"time"
 
+	"github.com/saichler/l8bus/go/overlay/protocol"
+	"github.com/saichler/l8bus/go/overlay/tracing"
 	"github.com/saichler/l8srlz/go/serialize/object"
 	"github.com/saichler/l8types/go/ifs"
-	"github.com/saichler/l8types/go/types/l8api"
 	"github.com/saichler/l8utils/go/utils/strings"
-	"google.golang.org/protobuf/proto"
 )
 
 // Forward sends a message to a destination and returns the response.
@@ -36,76 +36,41 @@ func (this *VirtualNetworkInterface) Forward(msg *ifs.Message, destination strin
 		timeout = int(msg.Tr_Timeout())
 	}
 
+	span := this.startSpan(spanName("forward", msg.ServiceName(), msg.ServiceArea()), tracing.SpanKindClient, this.traceOf(msg))
+	span.SetAttribute("destination", destination)
+
 	request, err := this.requests.NewRequest(this.protocol.NextMessageNumber(), this.resources.SysConfig().LocalUuid, timeout, this.resources.Logger())
 	if err != nil {
-		return object.NewError(err.Error())
+		resp := object.NewError(err.Error())
+		endSpan(span, resp)
+		return resp
 	}
 
 	defer this.requests.DelRequest(request.MsgNum(), request.MsgSource())
 
-	e := this.components.TX().Unicast(destination, msg.ServiceName(), msg.ServiceArea(), msg.Action(),
-		pb, ifs.P8, ifs.M_All, true, false, request.MsgNum(),
-		msg.Tr_State(), msg.Tr_Id(), msg.Tr_ErrMsg(),
-		msg.Tr_Created(), msg.Tr_Queued(), msg.Tr_Running(), msg.Tr_End(), msg.Tr_Timeout(), msg.Tr_Replica(), msg.Tr_IsReplica(), msg.AAAId())
-	if e != nil {
-		return object.NewError(e.Error())
-	}
-	request.Wait()
-	return request.Response()
-}
-
-// createElements converts various input types to IElements for message transmission.
-// Supports queries, proto messages, slices, and existing IElements.
-func createElements(any interface{}, resources ifs.IResources) (ifs.IElements, error) {
-	if any == nil {
-		return object.New(nil, nil), nil
-	}
-	pq, ok := any.(*l8api.L8Query)
-	if ok {
-		return object.NewQuery(pq.Text, resources)
-	}
-
-	gsql, ok := any.(string)
-	if ok {
-		return object.NewQuery(gsql, resources)
-	}
-
-	elems, ok := any.(ifs.IElements)
-	if ok {
-		return elems, nil
-	}
-
-	pb, ok := any.(proto.Message)
-	if ok {
-		return object.New(nil, pb), nil
+	breaker := this.breakerFor(msg.ServiceName(), msg.ServiceArea(), destination)
+	err = this.requestAllowed(breaker)
+	if err != nil {
+		resp := object.NewError(strings.New("Forward to ", destination, " failed fast: ", err.Error()).String())
+		endSpan(span, resp)
+		return resp
 	}
 
-	v := reflect.ValueOf(any)
-
-	if v.Kind() == reflect.Slice {
-		pbs := make([]proto.Message, v.Len())
-		for i := 0; i < v.Len(); i++ {
-			elm := v.Index(i)
-			elements, ok := elm.Interface().(ifs.IElements)
-			if ok {
-				for _, epb := range elements.Elements() {
-					pb, ok = epb.(proto.Message)
-					if ok {
-						pbs[i] = pb
-					} else {
-						panic(strings.New("Uknown input type ", reflect.ValueOf(pb).String()).String())
-					}
-				}
-			} else {
-				pb, ok = elm.Interface().(proto.Message)
-				if ok {
-					pbs[i] = pb
-				} else {
-					panic(strings.New("Uknown input type ", reflect.ValueOf(pb).String()).String())
-				}
-			}
-		}
-		return object.New(nil, pbs), nil
+	opts := protocol.NewMessage(msg.ServiceName(), msg.ServiceArea(), msg.Action()).To(destination).
+		AsRequest(msg.Tr_Timeout()).WithSequence(request.MsgNum()).WithToken(msg.AAAId()).
+		WithTransaction(protocol.TransactionOf(msg))
+	tracing.WithTrace(opts, span.Context())
+	e := this.components.TX().Unicast(opts, pb)
+	if e != nil {
+		this.requestFailed(breaker)
+		resp := object.NewError(e.Error())
+		endSpan(span, resp)
+		return resp
 	}
-	panic(strings.New("Uknown input type ", reflect.ValueOf(any).String()).String())
+	start := time.Now()
+	request.Wait()
+	resp := request.Response()
+	this.requestEnded(breaker, start, resp)
+	endSpan(span, resp)
+	return resp
 }
@@ -13,7 +13,13 @@
 
 package vnic
 
-import "github.com/saichler/l8types/go/ifs"
+import (
+	"sync"
+	"time"
+
+	"github.com/saichler/l8bus/go/overlay/protocol"
+	"github.com/saichler/l8types/go/ifs"
+)
 
 // Multicast sends a message to all instances of a service across the network.
 func (this *VirtualNetworkInterface) Multicast(serviceName string, serviceArea byte, action ifs.Action, any interface{}) error {
@@ -23,22 +29,87 @@ func (this *VirtualNetworkInterface) Multicast(serviceName string, serviceArea b
 // multicast is the internal implementation for sending messages to service instances
 // based on the specified multicast mode (All, Proximity, RoundRobin, Leader, Local).
 func (this *VirtualNetworkInterface) multicast(priority ifs.Priority, multicastMode ifs.MulticastMode, serviceName string, serviceArea byte, action ifs.Action, any interface{}) error {
-	elems, err := createElements(any, this.resources)
+	elems, err := protocol.ElementsFor(any, this.resources)
 	if err != nil {
 		return err
 	}
-	return this.components.TX().Multicast("", serviceName, serviceArea, action, elems, priority, multicastMode,
-		false, false, this.protocol.NextMessageNumber(), ifs.NotATransaction, "", "",
-		-1, -1, -1, -1, -1, 0, false, "")
+	opts := protocol.NewMessage(serviceName, serviceArea, action).WithPriority(priority).WithMode(multicastMode).
+		WithSequence(this.protocol.NextMessageNumber())
+	return this.components.TX().Multicast(opts, elems)
 }
 
 // multicastLink sends a multicast message using the service link infrastructure.
 func (this *VirtualNetworkInterface) multicastLink(priority ifs.Priority, multicastMode ifs.MulticastMode, serviceName string, serviceArea byte, action ifs.Action, any interface{}) error {
-	elems, err := createElements(any, this.resources)
+	elems, err := protocol.ElementsFor(any, this.resources)
 	if err != nil {
 		return err
 	}
-	return this.components.TX().Multicast("", serviceName, serviceArea, action, elems, priority, multicastMode,
-		false, false, this.protocol.NextMessageNumber(), ifs.NotATransaction, "", "",
-		-1, -1, -1, -1, -1, 0, false, "")
+	opts := protocol.NewMessage(serviceName, serviceArea, action).WithPriority(priority).WithMode(multicastMode).
+		WithSequence(this.protocol.NextMessageNumber())
+	return this.components.TX().Multicast(opts, elems)
+}
+
+// gatherer collects the responses to a multicast request until the expected number arrived.
+type gatherer struct {
+	mtx       *sync.Mutex
+	expected  int
+	responses []ifs.IElements
+	done      chan struct{}
+}
+
+// MulticastRequest sends a single request to all the instances of a service and gathers
+// their responses, until the expected number of responses arrived or the timeout expired.
+func (this *VirtualNetworkInterface) MulticastRequest(serviceName string, serviceArea byte, action ifs.Action,
+	any interface{}, expected, timeoutInSeconds int) []ifs.IElements {
+	return this.multicastRequest(protocol.NewMessage(serviceName, serviceArea, action), any, expected, timeoutInSeconds)
+}
+
+// multicastRequest sends the multicast request per the options and gathers its responses.
+func (this *VirtualNetworkInterface) multicastRequest(opts *protocol.MessageOptions, any interface{},
+	expected, timeoutInSeconds int) []ifs.IElements {
+	elems, err := protocol.ElementsFor(any, this.resources)
+	if err != nil {
+		this.resources.Logger().Error(err)
+		return nil
+	}
+	sequence := this.protocol.NextMessageNumber()
+	g := &gatherer{mtx: &sync.Mutex{}, expected: expected, responses: make([]ifs.IElements, 0, expected),
+		done: make(chan struct{})}
+	this.gatherers.Store(sequence, g)
+	defer this.gatherers.Delete(sequence)
+
+	opts.WithMode(ifs.M_All).AsRequest(int64(timeoutInSeconds)).WithSequence(sequence)
+	err = this.components.TX().Multicast(opts, elems)
+	if err != nil {
+		this.resources.Logger().Error(err)
+		return nil
+	}
+	if expected > 0 {
+		select {
+		case <-g.done:
+		case <-time.After(time.Duration(timeoutInSeconds) * time.Second):
+		}
+	}
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+	return append([]ifs.IElements{}, g.responses...)
+}
+
+// gathered hands a reply to the multicast request waiting for it, returning false if the
+// reply belongs to a unicast request.
+func (this *VirtualNetworkInterface) gathered(msg *ifs.Message, pb ifs.IElements) bool {
+	value, ok := this.gatherers.Load(msg.Sequence())
+	if !ok {
+		return false
+	}
+	g := value.(*gatherer)
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+	if len(g.responses) < g.expected {
+		g.responses = append(g.responses, pb)
+		if len(g.responses) == g.expected {
+			close(g.done)
+		}
+	}
+	return true
 }
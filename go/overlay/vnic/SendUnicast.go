@@ -14,9 +14,14 @@
 package vnic
 
 import (
+	"time"
+
 	"github.com/saichler/l8bus/go/overlay/health"
+	"github.com/saichler/l8bus/go/overlay/protocol"
+	"github.com/saichler/l8bus/go/overlay/tracing"
 	"github.com/saichler/l8srlz/go/serialize/object"
 	"github.com/saichler/l8types/go/ifs"
+	"github.com/saichler/l8utils/go/utils/strings"
 )
 
 // Unicast sends a message to a specific destination VNic by UUID.
@@ -33,13 +38,25 @@ func (this *VirtualNetworkInterface) unicast(destination, serviceName string, se
 		destination = ifs.DESTINATION_Single
 	}
 
-	elems, err := createElements(any, this.resources)
+	opts := protocol.NewMessage(serviceName, serviceArea, action).To(destination).WithPriority(priority).WithMode(multicastMode)
+	return this.Send(opts, any)
+}
+
+// Send sends a message per the options, to their destination if set or otherwise to the
+// service instances selected by their multicast mode. The sequence defaults to the next
+// message number.
+func (this *VirtualNetworkInterface) Send(opts *protocol.MessageOptions, any interface{}) error {
+	elems, err := protocol.ElementsFor(any, this.resources)
 	if err != nil {
 		return err
 	}
-	return this.components.TX().Unicast(destination, serviceName, serviceArea, action, elems, priority, multicastMode,
-		false, false, this.protocol.NextMessageNumber(), ifs.NotATransaction, "", "",
-		-1, -1, -1, -1, -1, 0, false, "")
+	if opts.Sequence == 0 {
+		opts.Sequence = this.protocol.NextMessageNumber()
+	}
+	if opts.Destination == "" {
+		return this.components.TX().Multicast(opts, elems)
+	}
+	return this.components.TX().Unicast(opts, elems)
 }
 
 // Request sends a request to a destination and waits for a response with timeout.
@@ -51,33 +68,72 @@ func (this *VirtualNetworkInterface) Request(destination, serviceName string, se
 // request is the internal implementation for sending requests and waiting for responses.
 func (this *VirtualNetworkInterface) request(destination, serviceName string, serviceArea byte,
 	action ifs.Action, any interface{}, priority ifs.Priority, multicastMode ifs.MulticastMode, timeoutInSeconds int, tokens ...string) ifs.IElements {
+	return this.RequestWith(requestOptions(destination, serviceName, serviceArea, action, priority, multicastMode, tokens...), any, timeoutInSeconds)
+}
 
-	if destination == "" {
-		destination = ifs.DESTINATION_Single
+// requestOptions returns the options of a request, with the token if given.
+func requestOptions(destination, serviceName string, serviceArea byte, action ifs.Action,
+	priority ifs.Priority, multicastMode ifs.MulticastMode, tokens ...string) *protocol.MessageOptions {
+	opts := protocol.NewMessage(serviceName, serviceArea, action).To(destination).WithPriority(priority).WithMode(multicastMode)
+	if tokens != nil && len(tokens) > 0 {
+		opts.WithToken(tokens[0])
+	}
+	return opts
+}
+
+// RequestWith sends a request per the options and waits for the response with timeout,
+// an empty destination selects a single service instance by the multicast mode. A trace
+// context set on the options is continued by the request span.
+func (this *VirtualNetworkInterface) RequestWith(opts *protocol.MessageOptions, any interface{}, timeoutInSeconds int) ifs.IElements {
+	if opts.Destination == "" {
+		opts.Destination = ifs.DESTINATION_Single
 	}
+	destination := opts.Destination
+	serviceName := opts.ServiceName
+	serviceArea := opts.ServiceArea
+
+	span := this.startSpan(spanName("request", serviceName, serviceArea), tracing.SpanKindClient, tracing.TraceOf(opts))
+	span.SetAttribute("destination", destination)
 
 	request, err := this.requests.NewRequest(this.protocol.NextMessageNumber(), this.resources.SysConfig().LocalUuid, timeoutInSeconds, this.resources.Logger())
 	if err != nil {
-		return object.NewError(err.Error())
+		resp := object.NewError(err.Error())
+		endSpan(span, resp)
+		return resp
 	}
 	defer this.requests.DelRequest(request.MsgNum(), request.MsgSource())
 
-	elements, err := createElements(any, this.resources)
+	elements, err := protocol.ElementsFor(any, this.resources)
 	if err != nil {
-		return object.NewError(err.Error())
+		resp := object.NewError(err.Error())
+		endSpan(span, resp)
+		return resp
 	}
-	token := ""
-	if tokens != nil && len(tokens) > 0 {
-		token = tokens[0]
+
+	// the breaker is asked only once the request is ready to be sent, so a local failure
+	// leaves no call of the breaker without its outcome
+	breaker := this.breakerFor(serviceName, serviceArea, destination)
+	err = this.requestAllowed(breaker)
+	if err != nil {
+		resp := object.NewError(strings.New("Request to ", destination, " failed fast: ", err.Error()).String())
+		endSpan(span, resp)
+		return resp
 	}
-	e := this.components.TX().Unicast(destination, serviceName, serviceArea, action, elements, priority, multicastMode,
-		true, false, request.MsgNum(), ifs.NotATransaction, "", "",
-		-1, -1, -1, -1, int64(timeoutInSeconds), 0, false, token)
+	opts.AsRequest(int64(timeoutInSeconds)).WithSequence(request.MsgNum())
+	tracing.WithTrace(opts, span.Context())
+	e := this.components.TX().Unicast(opts, elements)
 	if e != nil {
-		return object.NewError(e.Error())
+		this.requestFailed(breaker)
+		resp := object.NewError(e.Error())
+		endSpan(span, resp)
+		return resp
 	}
+	start := time.Now()
 	request.Wait()
-	return request.Response()
+	resp := request.Response()
+	this.requestEnded(breaker, start, resp)
+	endSpan(span, resp)
+	return resp
 }
 
 // Reply sends a response back to the originator of a request message.
@@ -88,6 +144,7 @@ func (this *VirtualNetworkInterface) Reply(msg *ifs.Message, response ifs.IEleme
 		this.resources.Logger().Error(e)
 		return e
 	}
+	data = tracing.Inject(data, this.traceOf(msg))
 	hp := health.HealthOf(msg.Source(), this.resources)
 	alias := " No Alias Yet"
 	if hp != nil {
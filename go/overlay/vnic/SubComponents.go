@@ -66,3 +66,8 @@ func (egComponents *SubComponents) addComponent(component SubComponent) {
 func (egComponents *SubComponents) TX() *TX {
 	return egComponents.components["TX"].(*TX)
 }
+
+// RX returns the RX (receive) sub-component.
+func (egComponents *SubComponents) RX() *RX {
+	return egComponents.components["RX"].(*RX)
+}
@@ -16,6 +16,8 @@ package vnic
 import (
 	"errors"
 
+	"github.com/saichler/l8bus/go/overlay/capture"
+	"github.com/saichler/l8bus/go/overlay/protocol"
 	"github.com/saichler/l8types/go/ifs"
 	"github.com/saichler/l8types/go/nets"
 	"github.com/saichler/l8utils/go/utils/queues"
@@ -62,23 +64,33 @@ func (this *TX) writeToSocket() {
 		data := this.tx.Next()
 		// if the data is not nil
 		if data != nil && this.vnic.running {
+			// A message that waited in the queue past its expiry is not written
+			if protocol.Expired(data) {
+				this.vnic.messageExpired(data)
+				continue
+			}
 			//Write the data to the socket
-			err := nets.Write(data, this.vnic.conn, this.vnic.resources.SysConfig())
+			err := this.write(data)
 			// If there is an error
 			if err != nil {
+				this.vnic.RecordError()
 				if this.vnic.IsVNet {
 					break
 				}
 				// If this is not a port on the switch, then try to reconnect.
 				if !this.shuttingDown && this.vnic.running {
 					this.vnic.reconnect()
-					err = nets.Write(data, this.vnic.conn, this.vnic.resources.SysConfig())
+					err = this.write(data)
 				} else {
 					break
 				}
 			}
 			this.vnic.healthStatistics.Stamp()
 			this.vnic.healthStatistics.IncrementTX(data)
+			if err == nil {
+				this.vnic.RecordMessageSent(int64(len(data)))
+				this.vnic.captureFrame(capture.Out, data)
+			}
 		} else {
 			// if the data is nil, break and cleanup
 			break
@@ -88,10 +100,33 @@ func (this *TX) writeToSocket() {
 	this.vnic.Shutdown()
 }
 
+// write writes a frame to the socket, with its checksum if the peer negotiated checksums.
+// The checksum is added per write, so once the peer sees a checksum every frame after it
+// carries one as well, and a frame written again after a reconnect follows the checksums
+// negotiated on the new connection.
+func (this *TX) write(data []byte) error {
+	if this.vnic.Supports(protocol.CapChecksum) {
+		data = protocol.AppendChecksum(data)
+	}
+	return nets.Write(data, this.vnic.conn, this.vnic.resources.SysConfig())
+}
+
 // Send Add the raw data to the tx queue to be written to the socket
 func (this *TX) SendMessage(data []byte) error {
 	// if the port is still active
 	if this.vnic.running {
+		// The extensions trailer is sent only to peers that negotiated it, and the trace
+		// context & the expiry only to peers that negotiated them
+		if !this.vnic.Supports(protocol.CapExtensions) {
+			data, _ = protocol.SplitExtensions(data)
+		} else {
+			if !this.vnic.Supports(protocol.CapTracing) {
+				data = protocol.WithoutExtension(data, protocol.Ext_Trace)
+			}
+			if !this.vnic.Supports(protocol.CapExpiry) {
+				data = protocol.WithoutExtension(data, protocol.Ext_Expiry)
+			}
+		}
 		// Add the data to the TX queue
 		this.tx.Add(data)
 	} else {
@@ -100,30 +135,25 @@ func (this *TX) SendMessage(data []byte) error {
 	return nil
 }
 
-// Unicast is wrapping a protobuf with a secure message and send it to the vnet
-func (this *TX) Unicast(destination, serviceName string, serviceArea byte, action ifs.Action, any ifs.IElements,
-	p ifs.Priority, m ifs.MulticastMode, isRequest, isReply bool, msgNum uint32,
-	tr_state ifs.TransactionState, tr_id, tr_errMsg string,
-	tr_created, tr_queued, tr_running, tr_completed, tr_timeout int64, tr_replica byte, tr_isReplica bool, token string) error {
-	if len(destination) != 36 {
-		return errors.New(strings.New("Invalid destination address ", destination, " size ", len(destination)).String())
+// Unicast is wrapping a protobuf with a secure message and send it to the destination of the options
+func (this *TX) Unicast(opts *protocol.MessageOptions, any ifs.IElements) error {
+	if len(opts.Destination) != 36 {
+		return errors.New(strings.New("Invalid destination address ", opts.Destination, " size ", len(opts.Destination)).String())
 	}
-	return this.Multicast(destination, serviceName, serviceArea, action, any, p, m,
-		isRequest, isReply, msgNum, tr_state, tr_id, tr_errMsg, tr_created, tr_queued, tr_running, tr_completed, tr_timeout, tr_replica, tr_isReplica, token)
+	return this.Multicast(opts, any)
 }
 
-// Multicast is wrapping a protobuf with a secure message and send it to the vnet topic
-func (this *TX) Multicast(destination, serviceName string, serviceArea byte, action ifs.Action, any ifs.IElements,
-	p ifs.Priority, m ifs.MulticastMode, isRequest, isReply bool, msgNum uint32,
-	tr_state ifs.TransactionState, tr_id, tr_errMsg string,
-	tr_created, tr_queued, tr_running, tr_completed, tr_timeout int64, tr_replica byte, tr_isReplica bool,
-	token string) error {
+// Multicast is wrapping a protobuf with a secure message and send it to the vnet topic,
+// the source & vnet of the options default to this vnic and its vnet.
+func (this *TX) Multicast(opts *protocol.MessageOptions, any ifs.IElements) error {
+	if opts.Source == "" {
+		opts.Source = this.vnic.resources.SysConfig().LocalUuid
+	}
+	if opts.Vnet == "" {
+		opts.Vnet = this.vnic.resources.SysConfig().RemoteUuid
+	}
 	// Create message payload
-	data, err := this.vnic.protocol.CreateMessageFor(destination, serviceName, serviceArea, p, m, action,
-		this.vnic.resources.SysConfig().LocalUuid, this.vnic.resources.SysConfig().RemoteUuid, any, isRequest, isReply, msgNum,
-		tr_state, tr_id, tr_errMsg,
-		tr_created, tr_queued, tr_running, tr_completed,
-		tr_timeout, tr_replica, tr_isReplica, token)
+	data, err := this.vnic.protocol.Create(opts, any)
 	if err != nil {
 		this.vnic.resources.Logger().Error("Failed to create message:", err)
 		return err
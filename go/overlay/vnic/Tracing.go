@@ -0,0 +1,201 @@
+// © 2025 Sharon Aicler (saichler@gmail.com)
+//
+// Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vnic
+
+import (
+	"github.com/saichler/l8bus/go/overlay/protocol"
+	"github.com/saichler/l8bus/go/overlay/tracing"
+	"github.com/saichler/l8types/go/ifs"
+	"github.com/saichler/l8utils/go/utils/strings"
+)
+
+// spanName names the spans of a service, e.g. "request Health/0"
+func spanName(operation, serviceName string, serviceArea byte) string {
+	return strings.New(operation, " ", serviceName, "/", int(serviceArea)).String()
+}
+
+// startSpan starts a span of this vnic, tagged with the vnic uuid
+func (this *VirtualNetworkInterface) startSpan(name string, kind tracing.SpanKind, parent *tracing.TraceContext) *tracing.Span {
+	span := tracing.StartSpan(this.resources.SysConfig().LocalAlias, name, kind, parent)
+	span.SetAttribute("vnic", this.resources.SysConfig().LocalUuid)
+	return span
+}
+
+// endSpan records the outcome of the response on the span and finishes it,
+// a nil response means the request timed out
+func endSpan(span *tracing.Span, resp ifs.IElements) {
+	if resp == nil {
+		span.SetError("timeout")
+	} else if resp.Error() != nil {
+		span.SetError(resp.Error().Error())
+	}
+	span.Finish()
+}
+
+// traceKey identifies a message being handled by its source & sequence
+func traceKey(msg *ifs.Message) string {
+	return strings.New(msg.Source(), "-", int(msg.Sequence())).String()
+}
+
+// startHandling starts the server span of a message and keeps its trace context while
+// the message is handled, so Forward & Reply propagate it. When tracing is disabled the
+// incoming trace context is kept as is.
+func (this *VirtualNetworkInterface) startHandling(msg *ifs.Message, trace *tracing.TraceContext) *tracing.Span {
+	span := this.startSpan(spanName("handle", msg.ServiceName(), msg.ServiceArea()), tracing.SpanKindServer, trace)
+	span.SetAttribute("source", msg.Source())
+	ctx := span.Context()
+	if ctx == nil {
+		ctx = trace
+	}
+	if ctx != nil {
+		this.traces.Store(traceKey(msg), ctx)
+	}
+	return span
+}
+
+// endHandling finishes the server span of a message
+func (this *VirtualNetworkInterface) endHandling(msg *ifs.Message, trace *tracing.TraceContext, span *tracing.Span, resp ifs.IElements) {
+	if trace != nil || span != nil {
+		this.traces.Delete(traceKey(msg))
+	}
+	if resp != nil && resp.Error() != nil {
+		span.SetError(resp.Error().Error())
+	}
+	span.Finish()
+}
+
+// traceOf returns the trace context of a message being handled, or nil
+func (this *VirtualNetworkInterface) traceOf(msg *ifs.Message) *tracing.TraceContext {
+	ctx, ok := this.traces.Load(traceKey(msg))
+	if !ok {
+		return nil
+	}
+	return ctx.(*tracing.TraceContext)
+}
+
+// handlingVnic is the vnic a service handler gets while handling a traced message, the
+// messages & requests the handler sends continue the trace of the message.
+type handlingVnic struct {
+	*VirtualNetworkInterface
+	trace *tracing.TraceContext
+}
+
+// handlerVnic returns the vnic to give the handler of the message, continuing its trace
+// if it has one.
+func (this *VirtualNetworkInterface) handlerVnic(msg *ifs.Message) ifs.IVNic {
+	trace := this.traceOf(msg)
+	if trace == nil {
+		return this
+	}
+	return &handlingVnic{VirtualNetworkInterface: this, trace: trace}
+}
+
+// traced sets the trace of the handled message on the options, unless they carry a trace
+// context of their own.
+func (this *handlingVnic) traced(opts *protocol.MessageOptions) *protocol.MessageOptions {
+	if tracing.TraceOf(opts) == nil {
+		tracing.WithTrace(opts, this.trace)
+	}
+	return opts
+}
+
+// Send sends a message per the options as a child of the handled message trace.
+func (this *handlingVnic) Send(opts *protocol.MessageOptions, any interface{}) error {
+	return this.VirtualNetworkInterface.Send(this.traced(opts), any)
+}
+
+// Unicast sends a message to the destination as a child of the handled message trace.
+func (this *handlingVnic) Unicast(destination, serviceName string, serviceArea byte,
+	action ifs.Action, any interface{}) error {
+	if destination == "" {
+		destination = ifs.DESTINATION_Single
+	}
+	return this.Send(protocol.NewMessage(serviceName, serviceArea, action).To(destination).WithPriority(ifs.P8).WithMode(ifs.M_All), any)
+}
+
+// Multicast sends a message to all instances of a service as a child of the handled
+// message trace.
+func (this *handlingVnic) Multicast(serviceName string, serviceArea byte, action ifs.Action, any interface{}) error {
+	return this.multicastWith(ifs.M_All, serviceName, serviceArea, action, any)
+}
+
+// Proximity sends a message to the nearest service instance as a child of the handled
+// message trace.
+func (this *handlingVnic) Proximity(serviceName string, serviceArea byte, action ifs.Action, any interface{}) error {
+	return this.multicastWith(ifs.M_Proximity, serviceName, serviceArea, action, any)
+}
+
+// RoundRobin sends a message to the service instances in rotation as a child of the
+// handled message trace.
+func (this *handlingVnic) RoundRobin(serviceName string, serviceArea byte, action ifs.Action, any interface{}) error {
+	return this.multicastWith(ifs.M_RoundRobin, serviceName, serviceArea, action, any)
+}
+
+// Local sends a message to the local service instance as a child of the handled message trace.
+func (this *handlingVnic) Local(serviceName string, serviceArea byte, action ifs.Action, any interface{}) error {
+	return this.multicastWith(ifs.M_Local, serviceName, serviceArea, action, any)
+}
+
+// Leader sends a message to the leader service instance as a child of the handled message trace.
+func (this *handlingVnic) Leader(serviceName string, serviceArea byte, action ifs.Action, any interface{}) error {
+	return this.multicastWith(ifs.M_Leader, serviceName, serviceArea, action, any)
+}
+
+func (this *handlingVnic) multicastWith(multicastMode ifs.MulticastMode, serviceName string, serviceArea byte, action ifs.Action, any interface{}) error {
+	return this.Send(protocol.NewMessage(serviceName, serviceArea, action).WithPriority(ifs.P8).WithMode(multicastMode), any)
+}
+
+// Request sends a request as a child of the handled message trace.
+func (this *handlingVnic) Request(destination, serviceName string, serviceArea byte,
+	action ifs.Action, any interface{}, timeoutSeconds int, tokens ...string) ifs.IElements {
+	opts := requestOptions(destination, serviceName, serviceArea, action, ifs.P8, ifs.M_All, tokens...)
+	return this.RequestWith(opts, any, timeoutSeconds)
+}
+
+// RequestWith sends a request per the options as a child of the handled message trace,
+// unless the options carry a trace context of their own.
+func (this *handlingVnic) RequestWith(opts *protocol.MessageOptions, any interface{}, timeoutInSeconds int) ifs.IElements {
+	return this.VirtualNetworkInterface.RequestWith(this.traced(opts), any, timeoutInSeconds)
+}
+
+// ProximityRequest sends a request to the nearest service instance as a child of the
+// handled message trace.
+func (this *handlingVnic) ProximityRequest(serviceName string, serviceArea byte, action ifs.Action, any interface{}, timeout int, tokens ...string) ifs.IElements {
+	return this.RequestWith(requestOptions("", serviceName, serviceArea, action, ifs.P8, ifs.M_Proximity, tokens...), any, timeout)
+}
+
+// RoundRobinRequest sends a request to the service instances in rotation as a child of
+// the handled message trace.
+func (this *handlingVnic) RoundRobinRequest(serviceName string, serviceArea byte, action ifs.Action, any interface{}, timeout int, tokens ...string) ifs.IElements {
+	return this.RequestWith(requestOptions("", serviceName, serviceArea, action, ifs.P8, ifs.M_RoundRobin, tokens...), any, timeout)
+}
+
+// LocalRequest sends a request to the local service instance as a child of the handled
+// message trace.
+func (this *handlingVnic) LocalRequest(serviceName string, serviceArea byte, action ifs.Action, any interface{}, timeout int, tokens ...string) ifs.IElements {
+	return this.RequestWith(requestOptions("", serviceName, serviceArea, action, ifs.P8, ifs.M_Local, tokens...), any, timeout)
+}
+
+// LeaderRequest sends a request to the leader service instance as a child of the handled
+// message trace.
+func (this *handlingVnic) LeaderRequest(serviceName string, serviceArea byte, action ifs.Action, any interface{}, timeout int, tokens ...string) ifs.IElements {
+	return this.RequestWith(requestOptions("", serviceName, serviceArea, action, ifs.P8, ifs.M_Leader, tokens...), any, timeout)
+}
+
+// MulticastRequest sends a request to all the instances of a service as a child of the
+// handled message trace.
+func (this *handlingVnic) MulticastRequest(serviceName string, serviceArea byte, action ifs.Action,
+	any interface{}, expected, timeoutInSeconds int) []ifs.IElements {
+	return this.multicastRequest(this.traced(protocol.NewMessage(serviceName, serviceArea, action)), any, expected, timeoutInSeconds)
+}
@@ -17,14 +17,18 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"net/http"
 	"os"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/saichler/l8bus/go/overlay/capture"
 	"github.com/saichler/l8bus/go/overlay/health"
 	"github.com/saichler/l8bus/go/overlay/metrics"
 	"github.com/saichler/l8bus/go/overlay/plugins"
 	"github.com/saichler/l8bus/go/overlay/protocol"
+	"github.com/saichler/l8bus/go/overlay/transport"
 	"github.com/saichler/l8types/go/ifs"
 	"github.com/saichler/l8types/go/types/l8services"
 	"github.com/saichler/l8utils/go/utils/ipsegment"
@@ -40,6 +44,8 @@ type VirtualNetworkInterface struct {
 	resources ifs.IResources
 	// The socket connection
 	conn net.Conn
+	// The transport used to dial the vnet
+	transport transport.Transport
 	// The socket connection mutex
 	connMtx *sync.Mutex
 	// is running
@@ -63,6 +69,17 @@ type VirtualNetworkInterface struct {
 	circuitBreakerManager *metrics.CircuitBreakerManager
 	circuitBreakerName    string
 	metricsRegistry       *metrics.MetricsRegistry
+	metricsServer         *http.Server
+	statisticsSink        atomic.Pointer[protocol.StatisticsSink]
+	trafficCounters       *trafficCounters
+	trafficOnce           sync.Once
+	traces                sync.Map
+	gatherers             sync.Map
+	captureWriter         atomic.Pointer[capture.Writer]
+	peer                  atomic.Pointer[protocol.Peer]
+	capabilities          []protocol.Capability
+	checksummed           atomic.Bool
+	corruptedFrames       atomic.Int64
 	connected             bool
 }
 
@@ -74,12 +91,14 @@ func NewVirtualNetworkInterface(resources ifs.IResources, conn net.Conn) *Virtua
 	vnic.conn = conn
 	vnic.resources = resources
 	vnic.connMtx = &sync.Mutex{}
+	vnic.transport = transport.Default()
 	vnic.protocol = protocol.New(vnic)
 	vnic.components = newSubomponents()
 	vnic.components.addComponent(newRX(vnic))
 	vnic.components.addComponent(newTX(vnic))
 	vnic.components.addComponent(newKeepAlive(vnic))
 	vnic.requests = requests2.NewRequests()
+	vnic.capabilities = protocol.DefaultCapabilities()
 	vnic.healthStatistics = &HealthStatistics{}
 	services := vnic.resources.SysConfig().Services
 	if services == nil {
@@ -98,14 +117,17 @@ func NewVirtualNetworkInterface(resources ifs.IResources, conn net.Conn) *Virtua
 	// Initialize metrics system
 	vnic.metricsRegistry = metrics.GetGlobalRegistry(resources.Logger())
 
-	// Initialize connection metrics if we have a connection
+	// Initialize connection metrics, a vnic dialing the vnet has no remote address until it connects
+	remoteAddr := ""
 	if conn != nil {
-		remoteAddr := conn.RemoteAddr().String()
-		connectionID := ifs.NewUuid()
-		vnic.connectionMetrics = metrics.NewConnectionMetrics(connectionID, remoteAddr)
+		remoteAddr = conn.RemoteAddr().String()
+	}
+	connectionID := ifs.NewUuid()
+	vnic.connectionMetrics = metrics.NewConnectionMetrics(connectionID, remoteAddr)
+	vnic.circuitBreakerManager = metrics.NewCircuitBreakerManager(vnic.metricsRegistry, resources.Logger())
 
+	if conn != nil {
 		// Initialize circuit breaker for this connection
-		vnic.circuitBreakerManager = metrics.NewCircuitBreakerManager(vnic.metricsRegistry, resources.Logger())
 		cbConfig := metrics.DefaultCircuitBreakerConfig()
 		vnic.circuitBreakerName = strings.New("vnic_", connectionID).String()
 		vnic.circuitBreaker = vnic.circuitBreakerManager.GetOrCreate(vnic.circuitBreakerName, cbConfig)
@@ -117,6 +139,8 @@ func NewVirtualNetworkInterface(resources ifs.IResources, conn net.Conn) *Virtua
 
 	if conn == nil {
 		health.Activate(vnic, false)
+		metrics.Activate(vnic)
+		ActivateHello(vnic)
 		if resources.SysConfig().RemoteVnet == "" {
 			sla := ifs.NewServiceLevelAgreement(&plugins.PluginService{}, plugins.ServiceName, 0, false, nil)
 			vnic.resources.Services().Activate(sla, vnic)
@@ -131,6 +155,11 @@ func (this *VirtualNetworkInterface) IsVnet() bool {
 	return false
 }
 
+// SetTransport replaces the transport used to dial the vnet, it should be called before Start.
+func (this *VirtualNetworkInterface) SetTransport(t transport.Transport) {
+	this.transport = t
+}
+
 // Start initiates the VNic, either connecting to a VNet switch or accepting
 // an incoming connection. It starts all sub-components (TX, RX, KeepAlive).
 func (this *VirtualNetworkInterface) Start() {
@@ -158,6 +187,7 @@ func (this *VirtualNetworkInterface) connectToSwitch() {
 	}
 	this.components.start()
 	this.connected = true
+	this.sendHello()
 }
 
 func (this *VirtualNetworkInterface) connect() error {
@@ -180,7 +210,7 @@ func (this *VirtualNetworkInterface) connect() error {
 
 	this.resources.Logger().Debug("Trying to connect to vnet at IP - ", destination)
 	// Try to dial to the switch
-	conn, err := this.resources.Security().CanDial(destination, this.resources.SysConfig().VnetPort)
+	conn, err := this.dial(destination)
 	if err != nil {
 		return errors.New(strings.New("Error connecting to the vnet: ", err.Error()).String())
 	}
@@ -199,11 +229,34 @@ func (this *VirtualNetworkInterface) connect() error {
 		return errors.New(strings.New("Error validating connection: ", err.Error()).String())
 	}
 	this.conn = conn
+	// the vnet may have been replaced by another version, negotiate again
+	this.peer.Store(nil)
+	this.checksummed.Store(false)
 	this.resources.SysConfig().Address = conn.LocalAddr().String()
 	this.resources.Logger().Debug("Connected!")
 	return nil
 }
 
+// dial prefers the unix domain socket when the destination vnet is on this host, falling back
+// to the transport if the socket is not available.
+func (this *VirtualNetworkInterface) dial(destination string) (net.Conn, error) {
+	port := this.resources.SysConfig().VnetPort
+	if transport.PreferLocal(this.transport, destination, port) {
+		conn, err := transport.NewUnix().Dial(destination, port, this.resources.Security())
+		if err == nil {
+			return conn, nil
+		}
+		this.resources.Logger().Debug("Unable to dial unix socket, falling back to tcp: ", err.Error())
+	}
+	return this.transport.Dial(destination, port, this.resources.Security())
+}
+
+// LocalLink returns true if this vnic is connected over a same host link, e.g. a unix domain socket.
+func (this *VirtualNetworkInterface) LocalLink() bool {
+	conn := this.conn
+	return conn != nil && transport.IsLocalLink(conn)
+}
+
 func (this *VirtualNetworkInterface) syncServicesWithConfig() {
 	s1 := this.resources.Services().Services()
 	s2 := this.resources.SysConfig().Services
@@ -239,6 +292,14 @@ func (this *VirtualNetworkInterface) Shutdown() {
 	}
 	this.components.shutdown()
 
+	if this.metricsServer != nil {
+		this.metricsServer.Close()
+	}
+	if sink := this.statisticsSink.Swap(nil); sink != nil {
+		sink.Stop()
+	}
+	this.StopCapture()
+
 	// Clean up circuit breaker to prevent memory leak
 	if this.circuitBreakerManager != nil && this.circuitBreakerName != "" {
 		this.circuitBreakerManager.Remove(this.circuitBreakerName)
@@ -259,6 +320,11 @@ func (this *VirtualNetworkInterface) Name() string {
 	return this.name
 }
 
+// QueueDepths returns the number of messages waiting in the TX & RX queues.
+func (this *VirtualNetworkInterface) QueueDepths() (int, int) {
+	return this.components.TX().tx.Size(), this.components.RX().rx.Size()
+}
+
 // SendMessage sends a message through the TX component to the connected VNet.
 func (this *VirtualNetworkInterface) SendMessage(data []byte) error {
 	return this.components.TX().SendMessage(data)
@@ -297,6 +363,7 @@ func (this *VirtualNetworkInterface) reconnect() {
 		this.resources.Logger().Error("***** Failed to reconnect to ", this.resources.SysConfig().RemoteAlias, " *****")
 	} else {
 		this.resources.Logger().Debug("***** Reconnected to ", this.resources.SysConfig().RemoteAlias, " *****")
+		this.sendHello()
 	}
 }
 
@@ -331,14 +398,9 @@ func (this *VirtualNetworkInterface) RecordMessageSent(bytes int64) {
 	}
 
 	// Update global metrics
-	if this.metricsRegistry != nil {
-		sentCounter := this.metricsRegistry.Counter("layer8_messages_sent_total",
-			map[string]string{"vnic_id": this.resources.SysConfig().LocalUuid})
-		sentCounter.Inc()
-
-		bytesCounter := this.metricsRegistry.Counter("layer8_bytes_sent_total",
-			map[string]string{"vnic_id": this.resources.SysConfig().LocalUuid})
-		bytesCounter.Add(bytes)
+	if counters := this.traffic(); counters != nil {
+		counters.sent.Inc()
+		counters.bytesSent.Add(bytes)
 	}
 }
 
@@ -349,14 +411,9 @@ func (this *VirtualNetworkInterface) RecordMessageReceived(bytes int64) {
 	}
 
 	// Update global metrics
-	if this.metricsRegistry != nil {
-		receivedCounter := this.metricsRegistry.Counter("layer8_messages_received_total",
-			map[string]string{"vnic_id": this.resources.SysConfig().LocalUuid})
-		receivedCounter.Inc()
-
-		bytesCounter := this.metricsRegistry.Counter("layer8_bytes_received_total",
-			map[string]string{"vnic_id": this.resources.SysConfig().LocalUuid})
-		bytesCounter.Add(bytes)
+	if counters := this.traffic(); counters != nil {
+		counters.received.Inc()
+		counters.bytesReceived.Add(bytes)
 	}
 }
 
@@ -373,6 +430,19 @@ func (this *VirtualNetworkInterface) RecordError() {
 	}
 }
 
+// RecordTimeout records a request that timed out
+func (this *VirtualNetworkInterface) RecordTimeout() {
+	if this.connectionMetrics != nil {
+		this.connectionMetrics.RecordTimeout()
+	}
+
+	if this.metricsRegistry != nil {
+		timeoutCounter := this.metricsRegistry.Counter("layer8_request_timeouts_total",
+			map[string]string{"vnic_id": this.resources.SysConfig().LocalUuid})
+		timeoutCounter.Inc()
+	}
+}
+
 // RecordLatency records a latency measurement
 func (this *VirtualNetworkInterface) RecordLatency(latencyMs int64) {
 	if this.connectionMetrics != nil {
@@ -383,7 +453,42 @@ func (this *VirtualNetworkInterface) RecordLatency(latencyMs int64) {
 		latencyHistogram := this.metricsRegistry.Histogram("layer8_message_latency_ms",
 			map[string]string{"vnic_id": this.resources.SysConfig().LocalUuid})
 		latencyHistogram.Observe(latencyMs)
+
+		latencyWindow := this.metricsRegistry.Window("layer8_message_latency_window_ms",
+			map[string]string{"vnic_id": this.resources.SysConfig().LocalUuid})
+		latencyWindow.Observe(latencyMs)
+	}
+}
+
+// StartMetrics exposes the metrics registry for scraping on the given port under /metrics.
+func (this *VirtualNetworkInterface) StartMetrics(port uint32) error {
+	server, err := this.metricsRegistry.Serve(port)
+	if err != nil {
+		return err
+	}
+	this.metricsServer = server
+	return nil
+}
+
+// StartStatistics enables the message statistics of this vnic and writes them periodically
+// per the config, until the vnic is shut down or the statistics are started again.
+func (this *VirtualNetworkInterface) StartStatistics(config *protocol.StatisticsConfig) error {
+	sink, err := protocol.NewStatisticsSink(this.protocol.Statistics(), config, this.resources.Logger())
+	if err != nil {
+		return err
+	}
+	this.protocol.Statistics().Enable(true)
+	// a sink started before is stopped first, so its goroutine does not keep writing
+	if previous := this.statisticsSink.Swap(sink); previous != nil {
+		previous.Stop()
 	}
+	sink.Start()
+	return nil
+}
+
+// Statistics returns the statistics of the messages this vnic created.
+func (this *VirtualNetworkInterface) Statistics() *protocol.MessageStatistics {
+	return this.protocol.Statistics()
 }
 
 // GetConnectionHealth returns the current connection health score
@@ -394,7 +499,24 @@ func (this *VirtualNetworkInterface) GetConnectionHealth() int64 {
 	return 100 // Default to healthy if no metrics
 }
 
-// GetCircuitBreaker returns the circuit breaker for this connection
+// CircuitBreakers returns the circuit breakers of this vnic, kept per service, area & destination.
+func (this *VirtualNetworkInterface) CircuitBreakers() *metrics.CircuitBreakerManager {
+	return this.circuitBreakerManager
+}
+
+// SetCircuitBreakers shares the circuit breakers of the vnet with its ports, so the outcome of
+// requests the vnet sends through a port is known when selecting service instances. The
+// breaker of the connection moves to the shared manager, so it is removed on Shutdown.
+func (this *VirtualNetworkInterface) SetCircuitBreakers(manager *metrics.CircuitBreakerManager) {
+	if this.circuitBreaker != nil && this.circuitBreakerManager != manager {
+		this.circuitBreakerManager.Remove(this.circuitBreakerName)
+		this.circuitBreaker = manager.GetOrCreate(this.circuitBreakerName, metrics.DefaultCircuitBreakerConfig())
+	}
+	this.circuitBreakerManager = manager
+}
+
+// GetCircuitBreaker returns the circuit breaker for this connection.
+// Deprecated: requests are guarded per service instance, see CircuitBreakers.
 func (this *VirtualNetworkInterface) GetCircuitBreaker() *metrics.CircuitBreaker {
 	return this.circuitBreaker
 }
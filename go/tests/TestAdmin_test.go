@@ -0,0 +1,68 @@
+// © 2025 Sharon Aicler (saichler@gmail.com)
+//
+// Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"testing"
+
+	"github.com/saichler/l8bus/go/overlay/health"
+	"github.com/saichler/l8bus/go/overlay/vnet"
+	infra "github.com/saichler/l8test/go/infra/t_resources"
+	"github.com/saichler/l8types/go/ifs"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestAdminSwitchTable(t *testing.T) {
+	ct := newChaosTopology(t)
+	defer ct.shutdown()
+	nic1 := ct.nic("nic1_1")
+
+	resp := nic1.Request(ct.uuid("vnet1"), vnet.AdminServiceName, 0, ifs.GET, &wrapperspb.BytesValue{}, 5)
+	info, err := vnet.SwitchTableFrom(resp)
+	if err != nil {
+		infra.Log.Fail(t, "Failed to inspect vnet1: ", err.Error())
+		return
+	}
+	// nic1_1, nic1_2 & vnet2
+	if len(info.Internal)+len(info.ExternalVnets) != 3 {
+		infra.Log.Fail(t, "Expected 3 connections but got ", len(info.Internal)+len(info.ExternalVnets))
+		return
+	}
+	var healthService *vnet.ServiceInfo
+	for _, service := range info.Services {
+		if service.Name == health.ServiceName && service.Area == 0 {
+			healthService = service
+		}
+	}
+	if healthService == nil || healthService.Leader == "" {
+		infra.Log.Fail(t, "Expected the health service with a leader")
+		return
+	}
+	if _, ok := info.Queues["handleData"]; !ok {
+		infra.Log.Fail(t, "Expected the queue depths of vnet1")
+		return
+	}
+
+	// the switch table of the remote vnet is fetched over the vnets link
+	resp = nic1.Request(ct.uuid("vnet2"), vnet.AdminServiceName, 0, ifs.GET, &wrapperspb.BytesValue{}, 5)
+	info, err = vnet.SwitchTableFrom(resp)
+	if err != nil {
+		infra.Log.Fail(t, "Failed to inspect vnet2: ", err.Error())
+		return
+	}
+	if info.Uuid != ct.uuid("vnet2") || len(info.Internal)+len(info.ExternalVnets) != 2 {
+		infra.Log.Fail(t, "Expected the switch table of vnet2")
+		return
+	}
+}
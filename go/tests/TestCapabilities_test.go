@@ -0,0 +1,183 @@
+// © 2025 Sharon Aicler (saichler@gmail.com)
+//
+// Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/saichler/l8bus/go/overlay/capture"
+	"github.com/saichler/l8bus/go/overlay/health"
+	"github.com/saichler/l8bus/go/overlay/protocol"
+	"github.com/saichler/l8bus/go/overlay/tracing"
+	"github.com/saichler/l8bus/go/overlay/vnet"
+	"github.com/saichler/l8bus/go/overlay/vnic"
+	infra "github.com/saichler/l8test/go/infra/t_resources"
+	"github.com/saichler/l8types/go/ifs"
+	"github.com/saichler/l8types/go/types/l8health"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestNegotiate(t *testing.T) {
+	unknown := protocol.Capability("compression")
+	peer := protocol.Negotiate(&protocol.Hello{Version: protocol.Version + 1,
+		Capabilities: []protocol.Capability{protocol.CapTracing, unknown}}, protocol.DefaultCapabilities())
+	if peer.Version != protocol.Version {
+		infra.Log.Fail(t, "Expected the lower version but got ", peer.Version)
+		return
+	}
+	if !peer.Supports(protocol.CapTracing) || peer.Supports(unknown) || peer.Supports(protocol.CapExtensions) {
+		infra.Log.Fail(t, "Expected only the capabilities supported by both sides")
+		return
+	}
+	var none *protocol.Peer
+	if none.Supports(protocol.CapTracing) {
+		infra.Log.Fail(t, "Expected a peer that sent no hello to support nothing")
+		return
+	}
+
+	data := protocol.WithExtension([]byte("message"), protocol.Ext_Trace, []byte("trace"))
+	data = protocol.WithExtension(data, protocol.Ext_Probe, []byte{1})
+	data = protocol.WithoutExtension(data, protocol.Ext_Trace)
+	if _, ok := protocol.ExtensionOf(data, protocol.Ext_Trace); ok {
+		infra.Log.Fail(t, "Expected the trace extension to be removed")
+		return
+	}
+	if _, ok := protocol.ExtensionOf(data, protocol.Ext_Probe); !ok {
+		infra.Log.Fail(t, "Expected the other extensions to be kept")
+		return
+	}
+}
+
+func TestCapabilities(t *testing.T) {
+	ct := newChaosTopology(t)
+	defer ct.shutdown()
+	nic1 := ct.nic("nic1_1")
+
+	ok := waitFor(time.Second*5, func() bool {
+		return nic1.Peer() != nil
+	})
+	if !ok || nic1.Peer().Version != protocol.Version || !nic1.Supports(protocol.CapTracing) {
+		infra.Log.Fail(t, "Expected nic1_1 to negotiate tracing with vnet1")
+		return
+	}
+
+	// both sides of the vnet link and the vnic connections negotiated
+	ok = waitFor(time.Second*5, func() bool {
+		resp := nic1.Request(ct.uuid("vnet1"), vnet.AdminServiceName, 0, ifs.GET, &wrapperspb.BytesValue{}, 5)
+		info, err := vnet.SwitchTableFrom(resp)
+		if err != nil {
+			return false
+		}
+		for _, conn := range append(info.Internal, info.ExternalVnets...) {
+			if conn.Version != protocol.Version || len(conn.Agreed) == 0 {
+				return false
+			}
+		}
+		return len(info.Internal)+len(info.ExternalVnets) == 3
+	})
+	if !ok {
+		infra.Log.Fail(t, "Expected every connection of vnet1 to negotiate its version")
+		return
+	}
+}
+
+func TestExtensionsNotNegotiated(t *testing.T) {
+	exporter := tracing.NewMemoryExporter()
+	tracing.SetExporter(exporter)
+	defer tracing.SetExporter(nil)
+
+	ct := newChaosTopology(t)
+	defer ct.shutdown()
+	// nic1_3 announces tracing but not the extensions trailer
+	ct.addVnic("nic1_3", "vnet1", 3, func(nic *vnic.VirtualNetworkInterface) {
+		nic.SetCapabilities([]protocol.Capability{protocol.CapTracing})
+	})
+	nic1 := ct.nic("nic1_1")
+	nic3 := ct.nic("nic1_3")
+	uuid3 := ct.uuid("nic1_3")
+	if !waitFor(time.Second*5, func() bool { return nic3.Peer() != nil }) || nic3.Supports(protocol.CapExtensions) {
+		infra.Log.Fail(t, "Expected nic1_3 to negotiate without the extensions trailer")
+		return
+	}
+	filename := filepath.Join(t.TempDir(), "nic1_3.l8cap")
+	err := nic3.StartCapture(filename, &capture.Filter{ServiceNames: []string{health.ServiceName}})
+	if err != nil {
+		infra.Log.Fail(t, err)
+		return
+	}
+
+	opts := protocol.NewMessage(health.ServiceName, 0, ifs.GET).To(uuid3).WithTTL(time.Second * 5)
+	resp := nic1.RequestWith(opts, &l8health.L8Health{AUuid: uuid3}, 5)
+	if resp == nil || resp.Error() != nil {
+		infra.Log.Fail(t, "Expected a reply from nic1_3")
+		return
+	}
+	nic3.StopCapture()
+
+	frames, err := capture.ReadAll(filename)
+	if err != nil || len(frames) == 0 {
+		infra.Log.Fail(t, "Expected the frames of nic1_3 to be captured")
+		return
+	}
+	for _, frame := range frames {
+		if _, ext := protocol.SplitExtensions(frame.Data); frame.Direction == capture.In && ext != nil {
+			infra.Log.Fail(t, "Expected nic1_3 to receive no extensions trailer")
+			return
+		}
+	}
+}
+
+func TestExpiryNotNegotiated(t *testing.T) {
+	ct := newChaosTopology(t)
+	defer ct.shutdown()
+	// nic1_3 takes the extensions trailer but does not honor the expiry
+	ct.addVnic("nic1_3", "vnet1", 3, func(nic *vnic.VirtualNetworkInterface) {
+		nic.SetCapabilities([]protocol.Capability{protocol.CapExtensions, protocol.CapTracing})
+	})
+	nic1 := ct.nic("nic1_1")
+	nic3 := ct.nic("nic1_3")
+	uuid3 := ct.uuid("nic1_3")
+	if !waitFor(time.Second*5, func() bool { return nic3.Peer() != nil }) || nic3.Supports(protocol.CapExpiry) {
+		infra.Log.Fail(t, "Expected nic1_3 to negotiate without the expiry")
+		return
+	}
+	filename := filepath.Join(t.TempDir(), "nic1_3.l8cap")
+	err := nic3.StartCapture(filename, &capture.Filter{ServiceNames: []string{health.ServiceName}})
+	if err != nil {
+		infra.Log.Fail(t, err)
+		return
+	}
+
+	opts := protocol.NewMessage(health.ServiceName, 0, ifs.GET).To(uuid3).WithTTL(time.Second * 5)
+	resp := nic1.RequestWith(opts, &l8health.L8Health{AUuid: uuid3}, 5)
+	if resp == nil || resp.Error() != nil {
+		infra.Log.Fail(t, "Expected a reply from nic1_3")
+		return
+	}
+	nic3.StopCapture()
+
+	frames, err := capture.ReadAll(filename)
+	if err != nil || len(frames) == 0 {
+		infra.Log.Fail(t, "Expected the frames of nic1_3 to be captured")
+		return
+	}
+	for _, frame := range frames {
+		if _, ok := protocol.ExtensionOf(frame.Data, protocol.Ext_Expiry); frame.Direction == capture.In && ok {
+			infra.Log.Fail(t, "Expected nic1_3 to receive no expiry")
+			return
+		}
+	}
+}
@@ -0,0 +1,139 @@
+// © 2025 Sharon Aicler (saichler@gmail.com)
+//
+// Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/saichler/l8bus/go/overlay/capture"
+	"github.com/saichler/l8bus/go/overlay/health"
+	infra "github.com/saichler/l8test/go/infra/t_resources"
+	"github.com/saichler/l8types/go/ifs"
+	"github.com/saichler/l8types/go/types/l8health"
+)
+
+func TestCapture(t *testing.T) {
+	ct := newChaosTopology(t)
+	defer ct.shutdown()
+	dir := t.TempDir()
+	nic1 := ct.nic("nic1_1")
+	nic2 := ct.nic("nic2_1")
+	uuid1 := nic1.Resources().SysConfig().LocalUuid
+	uuid2 := nic2.Resources().SysConfig().LocalUuid
+
+	vnetFile := filepath.Join(dir, "vnet1.l8cap")
+	err := ct.vnet1.StartCapture(vnetFile, &capture.Filter{Uuids: []string{uuid1}})
+	if err != nil {
+		infra.Log.Fail(t, err.Error())
+		return
+	}
+	nicFile := filepath.Join(dir, "nic2_1.l8cap")
+	err = nic2.StartCapture(nicFile, &capture.Filter{ServiceNames: []string{health.ServiceName}})
+	if err != nil {
+		infra.Log.Fail(t, err.Error())
+		return
+	}
+
+	for i := 0; i < 5; i++ {
+		nic1.Request(uuid2, health.ServiceName, 0, ifs.GET, &l8health.L8Health{}, 5)
+		time.Sleep(time.Millisecond * 100)
+	}
+	ct.vnet1.StopCapture()
+	nic2.StopCapture()
+
+	frames, err := capture.ReadAll(vnetFile)
+	if err != nil || len(frames) == 0 {
+		infra.Log.Fail(t, "Expected the frames of nic1_1 in the vnet1 capture")
+		return
+	}
+	for _, frame := range frames {
+		source, sourceVnet, destination, _, _, _, _ := ifs.HeaderOf(frame.Data)
+		if source != uuid1 && sourceVnet != uuid1 && destination != uuid1 {
+			infra.Log.Fail(t, "Expected only frames of nic1_1 but got ", source, " -> ", destination)
+			return
+		}
+		if frame.Direction != capture.In {
+			infra.Log.Fail(t, "Expected the vnet to capture received frames")
+			return
+		}
+	}
+
+	frames, err = capture.ReadAll(nicFile)
+	if err != nil {
+		infra.Log.Fail(t, err.Error())
+		return
+	}
+	in, out := 0, 0
+	for _, frame := range frames {
+		_, _, _, serviceName, _, _, _ := ifs.HeaderOf(frame.Data)
+		if serviceName != health.ServiceName {
+			infra.Log.Fail(t, "Expected only frames of the health service but got ", serviceName)
+			return
+		}
+		if frame.Direction == capture.In {
+			in++
+		} else {
+			out++
+		}
+	}
+	if in < 5 || out < 5 {
+		infra.Log.Fail(t, "Expected the requests & replies of nic2_1 but got ", in, " in & ", out, " out")
+		return
+	}
+
+	// replaying at the original speed keeps the intervals between the frames
+	start := time.Now()
+	err = capture.ReplayToServices(nicFile, nic2, capture.OriginalSpeed)
+	if err != nil {
+		infra.Log.Fail(t, err.Error())
+		return
+	}
+	if time.Since(start) < frames[len(frames)-1].Time.Sub(frames[0].Time) {
+		infra.Log.Fail(t, "Expected the replay to keep the original intervals")
+		return
+	}
+	replayed := 0
+	err = capture.Replay(nicFile, capture.AsFastAsPossible, func(frame *capture.Frame) error {
+		replayed++
+		return nil
+	})
+	if err != nil || replayed != len(frames) {
+		infra.Log.Fail(t, "Expected to replay ", len(frames), " frames but replayed ", replayed)
+		return
+	}
+
+	// a frame length above the max frame size is reported as corrupt, not allocated
+	data, err := os.ReadFile(nicFile)
+	if err != nil {
+		infra.Log.Fail(t, err.Error())
+		return
+	}
+	// the file magic & version are followed by the time, direction & connection length
+	binary.BigEndian.PutUint32(data[6+11:], 0xFFFFFFFF)
+	corrupt := filepath.Join(dir, "corrupt.l8cap")
+	err = os.WriteFile(corrupt, data, 0600)
+	if err != nil {
+		infra.Log.Fail(t, err.Error())
+		return
+	}
+	_, err = capture.ReadAll(corrupt)
+	if err == nil {
+		infra.Log.Fail(t, "Expected a frame longer than the max frame size to be corrupt")
+		return
+	}
+}
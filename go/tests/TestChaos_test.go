@@ -0,0 +1,103 @@
+// © 2025 Sharon Aicler (saichler@gmail.com)
+//
+// Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/saichler/l8bus/go/overlay/health"
+	"github.com/saichler/l8bus/go/overlay/transport"
+	infra "github.com/saichler/l8test/go/infra/t_resources"
+	"github.com/saichler/l8types/go/ifs"
+	"github.com/saichler/l8types/go/types/l8health"
+)
+
+func TestChaosRouteRemoval(t *testing.T) {
+	ct := newChaosTopology(t)
+	defer ct.shutdown()
+	nic1 := ct.nic("nic1_1")
+	uuid2 := ct.uuid("nic2_1")
+
+	if !waitFor(time.Second*5, func() bool { return health.HealthOf(uuid2, nic1.Resources()) != nil }) {
+		infra.Log.Fail(t, "Expected nic1_1 to see nic2_1 before the partition")
+		return
+	}
+
+	ct.chaos.Partition("vnet1", "vnet2", 0)
+
+	if !waitFor(time.Second*10, func() bool { return health.HealthOf(uuid2, nic1.Resources()) == nil }) {
+		infra.Log.Fail(t, "Expected the route to nic2_1 to be removed after the partition")
+		return
+	}
+}
+
+func TestChaosReconnect(t *testing.T) {
+	ct := newChaosTopology(t)
+	defer ct.shutdown()
+	ct.chaos.SetDefaultFaults(&transport.Faults{Latency: time.Millisecond * 5, Jitter: time.Millisecond * 5})
+	nic1 := ct.nic("nic1_1")
+	uuid2 := ct.uuid("nic1_2")
+
+	ct.chaos.Partition("nic1_1", "vnet1", time.Second*2)
+	if !ct.chaos.Partitioned("nic1_1", "vnet1") {
+		infra.Log.Fail(t, "Expected nic1_1 to be partitioned from vnet1")
+		return
+	}
+
+	ok := waitFor(time.Second*20, func() bool {
+		resp := nic1.Request(uuid2, health.ServiceName, 0, ifs.GET, &l8health.L8Health{AUuid: uuid2}, 1)
+		return resp != nil && resp.Error() == nil
+	})
+	if !ok {
+		infra.Log.Fail(t, "Expected nic1_1 to reconnect after the partition healed")
+		return
+	}
+	if !nic1.Running() {
+		infra.Log.Fail(t, "Expected nic1_1 to be running after reconnect")
+		return
+	}
+}
+
+func TestChaosLeaderSelection(t *testing.T) {
+	ct := newChaosTopology(t)
+	defer ct.shutdown()
+
+	leaderBefore := ""
+	waitFor(time.Second*5, func() bool {
+		leaderBefore = ct.leader(health.ServiceName, 0)
+		_, known := ct.names[leaderBefore]
+		return known && leaderBefore != ct.uuid("vnet1")
+	})
+	name, ok := ct.names[leaderBefore]
+	if !ok || name == "vnet1" {
+		infra.Log.Fail(t, "Expected the health leader to be a known endpoint, got ", leaderBefore)
+		return
+	}
+
+	if name == "vnet2" || name == "nic2_1" {
+		ct.chaos.Partition("vnet1", "vnet2", 0)
+	} else {
+		ct.chaos.Partition(name, "vnet1", 0)
+	}
+
+	ok = waitFor(time.Second*10, func() bool {
+		leaderAfter := ct.leader(health.ServiceName, 0)
+		return leaderAfter != "" && leaderAfter != leaderBefore
+	})
+	if !ok {
+		infra.Log.Fail(t, "Expected a new leader to be selected after ", name, " was partitioned")
+		return
+	}
+}
@@ -0,0 +1,121 @@
+// © 2025 Sharon Aicler (saichler@gmail.com)
+//
+// Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/saichler/l8bus/go/overlay/capture"
+	"github.com/saichler/l8bus/go/overlay/health"
+	"github.com/saichler/l8bus/go/overlay/metrics"
+	"github.com/saichler/l8bus/go/overlay/protocol"
+	"github.com/saichler/l8bus/go/overlay/transport"
+	"github.com/saichler/l8bus/go/overlay/vnic"
+	infra "github.com/saichler/l8test/go/infra/t_resources"
+	"github.com/saichler/l8types/go/ifs"
+	"github.com/saichler/l8types/go/types/l8health"
+)
+
+func TestChecksumFrames(t *testing.T) {
+	data := protocol.WithExtension([]byte("message"), protocol.Ext_Trace, []byte("trace"))
+	frame := protocol.AppendChecksum(data)
+	verified, present, err := protocol.VerifyChecksum(frame)
+	if err != nil || !present || string(verified) != string(data) {
+		infra.Log.Fail(t, "Expected the frame to match its checksum")
+		return
+	}
+	frame[2] ^= 0xFF
+	_, _, err = protocol.VerifyChecksum(frame)
+	if err != protocol.ErrChecksumMismatch {
+		infra.Log.Fail(t, "Expected a corrupted frame to fail its checksum")
+		return
+	}
+	verified, present, err = protocol.VerifyChecksum(data)
+	if err != nil || present || string(verified) != string(data) {
+		infra.Log.Fail(t, "Expected a frame without a checksum to be returned as is")
+		return
+	}
+}
+
+func TestChecksumCorruption(t *testing.T) {
+	ct := newChaosTopology(t)
+	defer ct.shutdown()
+	// checksums are enabled on the connection of nic1_3 to vnet1 only
+	ct.vnet1.SetChecksums(true)
+	ct.addVnic("nic1_3", "vnet1", 3, func(nic *vnic.VirtualNetworkInterface) {
+		nic.SetChecksums(true)
+	})
+	nic1 := ct.nic("nic1_3")
+	nic2 := ct.nic("nic2_1")
+	if !waitFor(time.Second*5, func() bool { return nic1.Supports(protocol.CapChecksum) }) {
+		infra.Log.Fail(t, "Expected nic1_3 to negotiate checksums with vnet1")
+		return
+	}
+	if ct.nic("nic1_1").Supports(protocol.CapChecksum) {
+		infra.Log.Fail(t, "Expected nic1_1 to keep its connection without checksums")
+		return
+	}
+	filename := filepath.Join(t.TempDir(), "nic1_3.l8cap")
+	err := nic1.StartCapture(filename, &capture.Filter{ServiceNames: []string{health.ServiceName}})
+	if err != nil {
+		infra.Log.Fail(t, err)
+		return
+	}
+	filter := &l8health.L8Health{AUuid: nic2.Resources().SysConfig().LocalUuid}
+	resp := nic1.Request(nic2.Resources().SysConfig().LocalUuid, health.ServiceName, 0, ifs.GET, filter, 5)
+	if resp == nil || resp.Error() != nil {
+		infra.Log.Fail(t, "Expected a response over checksummed connections")
+		return
+	}
+	nic1.StopCapture()
+
+	// the frames are captured without their checksum in both directions
+	frames, err := capture.ReadAll(filename)
+	if err != nil || len(frames) == 0 {
+		infra.Log.Fail(t, "Expected the frames of nic1_3 to be captured")
+		return
+	}
+	for _, frame := range frames {
+		if _, ok := protocol.ExtensionOf(frame.Data, protocol.Ext_Checksum); ok {
+			infra.Log.Fail(t, "Expected the ", frame.Direction, " frame to be captured without its checksum")
+			return
+		}
+	}
+
+	// every frame between nic1_3 & vnet1 is corrupted, either side drops it and the
+	// connection is reestablished
+	registry := metrics.GetGlobalRegistry(nic1.Resources().Logger())
+	corrupted := func() int64 {
+		return registry.Counter("layer8_corrupted_frames_total", map[string]string{"vnic_id": nic1.Resources().SysConfig().LocalUuid}).Get() +
+			registry.Counter("layer8_corrupted_frames_total", map[string]string{"vnic_id": ct.uuid("vnet1")}).Get()
+	}
+	ct.chaos.SetFaults("nic1_3", "vnet1", &transport.Faults{CorruptRate: 1})
+	nic1.Request(nic2.Resources().SysConfig().LocalUuid, health.ServiceName, 0, ifs.GET, filter, 1)
+	if !waitFor(time.Second*5, func() bool { return corrupted() > 0 }) {
+		infra.Log.Fail(t, "Expected the corrupted frames to be counted")
+		return
+	}
+	ct.chaos.SetFaults("nic1_3", "vnet1", nil)
+
+	ok := waitFor(time.Second*20, func() bool {
+		resp = nic1.Request(nic2.Resources().SysConfig().LocalUuid, health.ServiceName, 0, ifs.GET, filter, 1)
+		return resp != nil && resp.Error() == nil
+	})
+	if !ok {
+		infra.Log.Fail(t, "Expected nic1_3 to reconnect after the corrupted frames")
+		return
+	}
+}
@@ -0,0 +1,194 @@
+// © 2025 Sharon Aicler (saichler@gmail.com)
+//
+// Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/saichler/l8bus/go/overlay/events"
+	"github.com/saichler/l8bus/go/overlay/health"
+	"github.com/saichler/l8bus/go/overlay/protocol"
+	"github.com/saichler/l8bus/go/overlay/transport"
+	"github.com/saichler/l8bus/go/overlay/vnet"
+	"github.com/saichler/l8bus/go/overlay/vnic"
+	"github.com/saichler/l8srlz/go/serialize/object"
+	infra "github.com/saichler/l8test/go/infra/t_resources"
+	"github.com/saichler/l8types/go/ifs"
+	"github.com/saichler/l8types/go/types/l8health"
+)
+
+func TestDeadLetters(t *testing.T) {
+	ct := newChaosTopology(t)
+	defer ct.shutdown()
+	nic1 := ct.nic("nic1_1")
+	nic2 := ct.nic("nic2_1")
+	vnetUuid := ct.uuid("vnet1")
+
+	// a multicast with no instances of its service is kept by vnet1
+	event, _ := (&events.Event{Type: "dead-letter"}).ToBytes()
+	nic1.Multicast(events.ServiceName, events.ServiceArea, ifs.POST, event)
+	query := &vnet.DeadLetterQuery{ServiceName: events.ServiceName, WithData: true}
+	var result *vnet.DeadLetterResult
+	ok := waitFor(time.Second*5, func() bool {
+		result, _ = vnet.DeadLetterRequest(nic1, vnetUuid, ifs.GET, query, 5)
+		return result != nil && result.Count == 1
+	})
+	if !ok || result.Letters[0].Reason != vnet.ReasonNoInstances || len(result.Letters[0].Data) == 0 ||
+		result.Letters[0].Source != nic1.Resources().SysConfig().LocalUuid {
+		infra.Log.Fail(t, "Expected the multicast as a dead letter of vnet1")
+		return
+	}
+
+	// once the service has an instance the dead letter is redriven to it
+	received := atomic.Int32{}
+	events.Subscribe(nic2, func(e *events.Event) {
+		if e.Type == "dead-letter" {
+			received.Add(1)
+		}
+	})
+	waitFor(time.Second*5, func() bool {
+		for _, service := range ct.vnet1.SwitchTable().Services {
+			if service.Name == events.ServiceName {
+				return true
+			}
+		}
+		return false
+	})
+	result, err := vnet.DeadLetterRequest(nic1, vnetUuid, ifs.POST, &vnet.DeadLetterQuery{ServiceName: events.ServiceName}, 5)
+	if err != nil || result.Count != 1 {
+		infra.Log.Fail(t, "Expected the dead letter to be redriven")
+		return
+	}
+	if !waitFor(time.Second*5, func() bool { return received.Load() == 1 }) {
+		infra.Log.Fail(t, "Expected nic2_1 to receive the redriven message")
+		return
+	}
+
+	// a unicast to an unknown destination is failed & kept, and can be purged
+	nic1.Unicast(ifs.NewUuid(), health.ServiceName, 0, ifs.POST, &l8health.L8Health{})
+	query = &vnet.DeadLetterQuery{ServiceName: health.ServiceName}
+	ok = waitFor(time.Second*5, func() bool {
+		result, _ = vnet.DeadLetterRequest(nic1, vnetUuid, ifs.GET, query, 5)
+		return result != nil && result.Count > 0
+	})
+	if !ok || len(result.Letters[0].Data) != 0 {
+		infra.Log.Fail(t, "Expected the failed unicast as a dead letter, listed without its data")
+		return
+	}
+	result, err = vnet.DeadLetterRequest(nic1, vnetUuid, ifs.DELETE, query, 5)
+	if err != nil || result.Count == 0 || ct.vnet1.DeadLetters(query).Count != 0 {
+		infra.Log.Fail(t, "Expected the dead letters to be purged")
+		return
+	}
+}
+
+func TestDeadLetterEviction(t *testing.T) {
+	mem := transport.NewMemory()
+	v := memoryVNet(mem, nextPort())
+	defer v.Shutdown()
+	r, _ := infra.CreateResources(13000, 5, ifs.Error_Level)
+	p := protocol.New(vnic.NewVirtualNetworkInterface(r, nil))
+	uuid := r.SysConfig().LocalUuid
+	// multicasts to a service no one runs become dead letters of the vnet
+	deadLetter := func(sequence uint32) {
+		data, err := p.Create(protocol.NewMessage("Nowhere", 0, ifs.POST).From(uuid, uuid).WithSequence(sequence),
+			object.New(nil, &l8health.L8Health{AUuid: uuid}))
+		if err != nil {
+			infra.Log.Fail(t, err)
+			return
+		}
+		v.HandleData(data, v.VnetVnic())
+	}
+
+	if v.SetDeadLetterLimits(-1, vnet.DefaultDeadLetterMaxBytes) == nil {
+		infra.Log.Fail(t, "Expected a negative capacity to be rejected")
+		return
+	}
+	// start from an empty store, the letters the vnet kept so far are dropped
+	v.SetDeadLetterLimits(0, vnet.DefaultDeadLetterMaxBytes)
+	dropped := v.DeadLetters(nil).Dropped
+	err := v.SetDeadLetterLimits(3, vnet.DefaultDeadLetterMaxBytes)
+	if err != nil {
+		infra.Log.Fail(t, err)
+		return
+	}
+	for i := uint32(1); i <= 5; i++ {
+		deadLetter(i)
+	}
+	result := v.DeadLetters(nil)
+	if result.Count != 3 {
+		infra.Log.Fail(t, "Expected the 3 newest dead letters, got ", result.Count)
+		return
+	}
+	first := result.Letters[0].Id
+	if result.Dropped-dropped != 2 || result.Letters[2].Id != first+2 {
+		infra.Log.Fail(t, "Expected the 3 newest dead letters with 2 dropped, got ", result.Count, " ", result.Dropped)
+		return
+	}
+
+	// a byte cap below the size of the letters drops the oldest
+	size := result.Letters[1].Size + result.Letters[2].Size
+	err = v.SetDeadLetterLimits(3, size)
+	if err != nil {
+		infra.Log.Fail(t, err)
+		return
+	}
+	result = v.DeadLetters(nil)
+	if result.Count != 2 || result.Dropped-dropped != 3 || result.Letters[0].Id != first+1 {
+		infra.Log.Fail(t, "Expected the byte cap to drop the oldest dead letter")
+		return
+	}
+	deadLetter(6)
+	result = v.DeadLetters(nil)
+	if result.Count != 2 || result.Dropped-dropped != 4 || result.Letters[0].Id != first+2 || result.Letters[1].Id != first+3 {
+		infra.Log.Fail(t, "Expected a new dead letter to evict the oldest above the byte cap")
+		return
+	}
+}
+
+func TestRedriveExpired(t *testing.T) {
+	mem := transport.NewMemory()
+	v := memoryVNet(mem, nextPort())
+	defer v.Shutdown()
+	r, _ := infra.CreateResources(13000, 5, ifs.Error_Level)
+	p := protocol.New(vnic.NewVirtualNetworkInterface(r, nil))
+	uuid := r.SysConfig().LocalUuid
+	data, err := p.Create(protocol.NewMessage("Expiring", 0, ifs.POST).From(uuid, uuid).WithTTL(time.Millisecond*200),
+		object.New(nil, &l8health.L8Health{AUuid: uuid}))
+	if err != nil {
+		infra.Log.Fail(t, err)
+		return
+	}
+	v.HandleData(data, v.VnetVnic())
+	query := &vnet.DeadLetterQuery{ServiceName: "Expiring"}
+	if !waitFor(time.Second*5, func() bool { return v.DeadLetters(query).Count == 1 }) {
+		infra.Log.Fail(t, "Expected the multicast as a dead letter")
+		return
+	}
+
+	// once expired the letter is dropped instead of redriven
+	time.Sleep(time.Millisecond * 300)
+	redriven, expired := v.Redrive(query)
+	if redriven != 0 || expired != 1 {
+		infra.Log.Fail(t, "Expected the expired dead letter to be dropped, got ", redriven, " ", expired)
+		return
+	}
+	time.Sleep(time.Millisecond * 200)
+	if v.DeadLetters(query).Count != 0 {
+		infra.Log.Fail(t, "Expected the expired dead letter not to be dead lettered again")
+		return
+	}
+}
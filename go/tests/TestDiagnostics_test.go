@@ -0,0 +1,79 @@
+// © 2025 Sharon Aicler (saichler@gmail.com)
+//
+// Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"testing"
+
+	infra "github.com/saichler/l8test/go/infra/t_resources"
+	"github.com/saichler/l8types/go/ifs"
+)
+
+func TestPing(t *testing.T) {
+	ct := newChaosTopology(t)
+	defer ct.shutdown()
+	nic1 := ct.nic("nic1_1")
+	uuid2 := ct.uuid("nic2_1")
+
+	hop, err := nic1.Ping(uuid2, 5)
+	if err != nil {
+		infra.Log.Fail(t, "Failed to ping nic2_1: ", err.Error())
+		return
+	}
+	if hop.Uuid != uuid2 || hop.IsVnet {
+		infra.Log.Fail(t, "Expected nic2_1 to answer the ping but got ", hop.Alias)
+		return
+	}
+
+	vnetUuid := ct.uuid("vnet2")
+	hop, err = nic1.Ping(vnetUuid, 5)
+	if err != nil || hop.Uuid != vnetUuid || !hop.IsVnet {
+		infra.Log.Fail(t, "Expected vnet2 to answer the ping")
+		return
+	}
+
+	_, err = nic1.Ping(ifs.NewUuid(), 2)
+	if err == nil {
+		infra.Log.Fail(t, "Expected the ping of an unknown uuid to fail")
+		return
+	}
+}
+
+func TestTraceroute(t *testing.T) {
+	ct := newChaosTopology(t)
+	defer ct.shutdown()
+	nic1 := ct.nic("nic1_1")
+	uuid2 := ct.uuid("nic2_1")
+
+	hops, err := nic1.Traceroute(uuid2, 5)
+	if err != nil {
+		infra.Log.Fail(t, "Failed to traceroute nic2_1: ", err.Error())
+		return
+	}
+	expected := []string{
+		ct.uuid("vnet1"),
+		ct.uuid("vnet2"),
+		uuid2,
+	}
+	if len(hops) != len(expected) {
+		infra.Log.Fail(t, "Expected ", len(expected), " hops but got ", len(hops))
+		return
+	}
+	for i, hop := range hops {
+		if hop.Uuid != expected[i] {
+			infra.Log.Fail(t, "Unexpected hop ", i+1, " ", hop.Alias)
+			return
+		}
+	}
+}
@@ -0,0 +1,99 @@
+// © 2025 Sharon Aicler (saichler@gmail.com)
+//
+// Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"path/filepath"
+	stdstrings "strings"
+	"testing"
+
+	"github.com/saichler/l8bus/go/overlay/capture"
+	"github.com/saichler/l8bus/go/overlay/dissector"
+	"github.com/saichler/l8bus/go/overlay/health"
+	infra "github.com/saichler/l8test/go/infra/t_resources"
+	"github.com/saichler/l8types/go/ifs"
+	"github.com/saichler/l8types/go/types/l8health"
+)
+
+func TestDissector(t *testing.T) {
+	ct := newChaosTopology(t)
+	defer ct.shutdown()
+	dir := t.TempDir()
+	nic1 := ct.nic("nic1_1")
+	nic2 := ct.nic("nic2_1")
+	uuid1 := nic1.Resources().SysConfig().LocalUuid
+
+	file := filepath.Join(dir, "nic2_1.l8cap")
+	err := nic2.StartCapture(file, &capture.Filter{Uuids: []string{uuid1}})
+	if err != nil {
+		infra.Log.Fail(t, err.Error())
+		return
+	}
+	nic1.Request(nic2.Resources().SysConfig().LocalUuid, health.ServiceName, 0, ifs.GET, &l8health.L8Health{Alias: "dissected"}, 5)
+	nic2.StopCapture()
+
+	frames, err := capture.ReadAll(file)
+	if err != nil {
+		infra.Log.Fail(t, err.Error())
+		return
+	}
+	var request *dissector.Dissection
+	for _, frame := range frames {
+		d, err := dissector.Dissect(frame.Data, nic2.Resources())
+		if err != nil {
+			infra.Log.Fail(t, err.Error())
+			return
+		}
+		if d.Source == uuid1 && d.Request {
+			request = d
+		}
+	}
+	if request == nil {
+		infra.Log.Fail(t, "Expected to dissect the request of nic1_1")
+		return
+	}
+	if request.ServiceName != health.ServiceName || request.Action != "GET" || request.Sequence == 0 {
+		infra.Log.Fail(t, "Unexpected request fields ", request.ServiceName, " ", request.Action)
+		return
+	}
+	if len(request.Payload) != 1 || !stdstrings.Contains(string(request.Payload[0].Body), "dissected") {
+		infra.Log.Fail(t, "Expected the payload to be decoded")
+		return
+	}
+	if !stdstrings.Contains(request.Text(), uuid1) {
+		infra.Log.Fail(t, "Expected the text to include the source")
+		return
+	}
+
+	data, err := dissector.ParseFrame(hex.EncodeToString(frames[0].Data))
+	if err != nil || !bytes.Equal(data, frames[0].Data) {
+		infra.Log.Fail(t, "Expected to parse a hex frame")
+		return
+	}
+	data, err = dissector.ParseFrame(base64.StdEncoding.EncodeToString(frames[0].Data))
+	if err != nil || !bytes.Equal(data, frames[0].Data) {
+		infra.Log.Fail(t, "Expected to parse a base64 frame")
+		return
+	}
+
+	// a truncated frame is reported, not a panic
+	d, err := dissector.Dissect(frames[0].Data[:len(frames[0].Data)/2], nic2.Resources())
+	if err == nil && d.Error == "" && d.PayloadError == "" {
+		infra.Log.Fail(t, "Expected an error for a truncated frame")
+		return
+	}
+}
@@ -0,0 +1,91 @@
+// © 2025 Sharon Aicler (saichler@gmail.com)
+//
+// Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/saichler/l8bus/go/overlay/events"
+	infra "github.com/saichler/l8test/go/infra/t_resources"
+)
+
+// eventLog collects the events of a subscriber
+type eventLog struct {
+	events []*events.Event
+	mtx    sync.Mutex
+}
+
+func (this *eventLog) add(event *events.Event) {
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+	this.events = append(this.events, event)
+}
+
+func (this *eventLog) has(eventType events.EventType, uuid string) bool {
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+	for _, event := range this.events {
+		if event.Type == eventType && event.Uuid == uuid {
+			return true
+		}
+	}
+	return false
+}
+
+func TestEvents(t *testing.T) {
+	ct := newChaosTopology(t)
+	defer ct.shutdown()
+
+	local := &eventLog{}
+	connected := &eventLog{}
+	ct.vnet1.Events().Subscribe(local.add)
+	ct.vnet1.Events().Subscribe(connected.add, events.VNicConnected)
+	// a panicking subscriber does not stop the delivery to the others
+	ct.vnet1.Events().Subscribe(func(event *events.Event) {
+		panic("subscriber")
+	}, events.VNicConnected)
+
+	remote := &eventLog{}
+	events.Subscribe(ct.nic("nic1_1"), remote.add)
+	time.Sleep(time.Second)
+
+	ct.addVnic("nic1_3", "vnet1", 3)
+	uuid := ct.uuid("nic1_3")
+
+	if !waitFor(time.Second*5, func() bool { return local.has(events.VNicConnected, uuid) }) {
+		infra.Log.Fail(t, "Expected a local VNicConnected event for nic1_3")
+		return
+	}
+	if !waitFor(time.Second*5, func() bool { return local.has(events.ServiceAdded, uuid) }) {
+		infra.Log.Fail(t, "Expected a local ServiceAdded event for nic1_3")
+		return
+	}
+	if !waitFor(time.Second*5, func() bool { return remote.has(events.VNicConnected, uuid) }) {
+		infra.Log.Fail(t, "Expected nic1_1 to receive the VNicConnected event of nic1_3")
+		return
+	}
+
+	ct.nic("nic1_3").Shutdown()
+	delete(ct.nics, "nic1_3")
+	if !waitFor(time.Second*5, func() bool { return remote.has(events.VNicDisconnected, uuid) }) {
+		infra.Log.Fail(t, "Expected nic1_1 to receive the VNicDisconnected event of nic1_3")
+		return
+	}
+	if connected.has(events.VNicDisconnected, uuid) {
+		infra.Log.Fail(t, "Expected the filtered subscriber to receive only VNicConnected events")
+		return
+	}
+}
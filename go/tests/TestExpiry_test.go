@@ -0,0 +1,70 @@
+// © 2025 Sharon Aicler (saichler@gmail.com)
+//
+// Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/saichler/l8bus/go/overlay/health"
+	"github.com/saichler/l8bus/go/overlay/protocol"
+	"github.com/saichler/l8bus/go/overlay/transport"
+	infra "github.com/saichler/l8test/go/infra/t_resources"
+	"github.com/saichler/l8types/go/ifs"
+	"github.com/saichler/l8types/go/types/l8health"
+)
+
+func TestExpiry(t *testing.T) {
+	data := protocol.AppendExtensions([]byte("message"), protocol.NewMessage("svc", 0, ifs.POST).WithTTL(-time.Second).Extensions)
+	if !protocol.Expired(data) {
+		infra.Log.Fail(t, "Expected a message past its expiry to be expired")
+		return
+	}
+	data = protocol.AppendExtensions([]byte("message"), protocol.NewMessage("svc", 0, ifs.POST).WithTTL(time.Hour).Extensions)
+	if protocol.Expired(data) || protocol.Expired([]byte("message")) {
+		infra.Log.Fail(t, "Expected messages before or without an expiry not to be expired")
+		return
+	}
+}
+
+func TestExpiredRequests(t *testing.T) {
+	ct := newChaosTopology(t)
+	defer ct.shutdown()
+	nic1 := ct.nic("nic1_1")
+	uuid2 := ct.uuid("nic2_1")
+	filter := &l8health.L8Health{AUuid: uuid2}
+
+	// dropped by the TX queue of nic1_1
+	start := time.Now()
+	opts := protocol.NewMessage(health.ServiceName, 0, ifs.GET).To(uuid2).WithExpiry(time.Now().Add(-time.Second))
+	resp := nic1.RequestWith(opts, filter, 5)
+	if resp == nil || resp.Error() == nil || !strings.Contains(resp.Error().Error(), "expired") ||
+		time.Since(start) > time.Second*4 {
+		infra.Log.Fail(t, "Expected the request to fail as expired by nic1_1")
+		return
+	}
+
+	// dropped by vnet1 as the link delays it past its expiry
+	ct.chaos.SetFaults("nic1_1", "vnet1", &transport.Faults{Latency: time.Millisecond * 500})
+	defer ct.chaos.SetFaults("nic1_1", "vnet1", nil)
+	start = time.Now()
+	opts = protocol.NewMessage(health.ServiceName, 0, ifs.GET).To(uuid2).WithTTL(time.Millisecond * 200)
+	resp = nic1.RequestWith(opts, filter, 5)
+	if resp == nil || resp.Error() == nil || !strings.Contains(resp.Error().Error(), "expired") ||
+		time.Since(start) > time.Second*4 {
+		infra.Log.Fail(t, "Expected the request to fail as expired by vnet1")
+		return
+	}
+}
@@ -0,0 +1,152 @@
+// © 2025 Sharon Aicler (saichler@gmail.com)
+//
+// Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/saichler/l8bus/go/overlay/transport"
+	vnet2 "github.com/saichler/l8bus/go/overlay/vnet"
+	"github.com/saichler/l8bus/go/overlay/vnic"
+	infra "github.com/saichler/l8test/go/infra/t_resources"
+	"github.com/saichler/l8types/go/ifs"
+)
+
+// testToken is presented by the gateway clients of the tests, the test security provider
+// validates any token.
+const testToken = "token"
+
+// ports hands out the vnet ports of the in-process topologies, so no two tests share one.
+var ports = atomic.Int32{}
+
+func init() {
+	ports.Store(50000)
+}
+
+// nextPort returns a vnet port no other test is using.
+func nextPort() int {
+	return int(ports.Add(100))
+}
+
+// memoryVNet creates and starts a VNet on the given in-process transport.
+func memoryVNet(mem transport.Transport, port int) *vnet2.VNet {
+	r, _ := infra.CreateResources(port, 0, ifs.Info_Level)
+	vnet := vnet2.NewVNet(r)
+	vnet.SetTransport(mem)
+	vnet.Start()
+	return vnet
+}
+
+// memoryVnic creates a VNic on the given in-process transport, applies the options to it
+// before it starts and waits for it to connect.
+func memoryVnic(mem transport.Transport, port, num int, options ...func(*vnic.VirtualNetworkInterface)) *vnic.VirtualNetworkInterface {
+	r, _ := infra.CreateResources(port, num, ifs.Info_Level)
+	nic := vnic.NewVirtualNetworkInterface(r, nil)
+	nic.SetTransport(mem)
+	for _, option := range options {
+		option(nic)
+	}
+	nic.Start()
+	nic.WaitForConnection()
+	return nic
+}
+
+// chaosTopology is two VNets over a chaos transport, with two vnics on the first VNet
+// and one vnic on the second. Endpoint names are vnet1, vnet2, nic1_1, nic1_2 & nic2_1.
+type chaosTopology struct {
+	chaos *transport.Chaos
+	vnet1 *vnet2.VNet
+	vnet2 *vnet2.VNet
+	ports map[string]int
+	nics  map[string]*vnic.VirtualNetworkInterface
+	names map[string]string
+	uuids map[string]string
+}
+
+func newChaosTopology(t *testing.T) *chaosTopology {
+	ct := &chaosTopology{}
+	ct.chaos = transport.NewChaos(transport.NewMemory(), 1)
+	ct.ports = map[string]int{"vnet1": nextPort(), "vnet2": nextPort()}
+	ct.nics = make(map[string]*vnic.VirtualNetworkInterface)
+	ct.names = make(map[string]string)
+	ct.uuids = make(map[string]string)
+	ct.vnet1 = memoryVNet(ct.chaos.Named("vnet1"), ct.ports["vnet1"])
+	ct.vnet2 = memoryVNet(ct.chaos.Named("vnet2"), ct.ports["vnet2"])
+	ct.named("vnet1", ct.vnet1.Resources())
+	ct.named("vnet2", ct.vnet2.Resources())
+	err := ct.vnet1.ConnectNetworks("127.0.0.1", uint32(ct.ports["vnet2"]))
+	if err != nil {
+		infra.Log.Fail(t, err)
+	}
+	ct.addVnic("nic1_1", "vnet1", 1)
+	ct.addVnic("nic1_2", "vnet1", 2)
+	ct.addVnic("nic2_1", "vnet2", 1)
+	return ct
+}
+
+func (this *chaosTopology) named(name string, resources ifs.IResources) {
+	this.names[resources.SysConfig().LocalUuid] = name
+	this.uuids[name] = resources.SysConfig().LocalUuid
+}
+
+// addVnic connects a new vnic, named name, to the given vnet of the topology, with the
+// options applied before it starts.
+func (this *chaosTopology) addVnic(name, vnet string, num int, options ...func(*vnic.VirtualNetworkInterface)) {
+	nic := memoryVnic(this.chaos.Named(name), this.ports[vnet], num, options...)
+	this.nics[name] = nic
+	this.named(name, nic.Resources())
+}
+
+// nic returns the vnic with the given endpoint name.
+func (this *chaosTopology) nic(name string) *vnic.VirtualNetworkInterface {
+	return this.nics[name]
+}
+
+// uuid returns the uuid of the vnet or vnic with the given endpoint name.
+func (this *chaosTopology) uuid(name string) string {
+	return this.uuids[name]
+}
+
+// leader returns the uuid vnet1 selects as the leader of the service, as the admin
+// service reports it.
+func (this *chaosTopology) leader(serviceName string, serviceArea byte) string {
+	for _, service := range this.vnet1.SwitchTable().Services {
+		if service.Name == serviceName && service.Area == serviceArea {
+			return service.Leader
+		}
+	}
+	return ""
+}
+
+func (this *chaosTopology) shutdown() {
+	for _, nic := range this.nics {
+		nic.Shutdown()
+	}
+	this.vnet1.Shutdown()
+	this.vnet2.Shutdown()
+}
+
+// waitFor polls the condition every 100ms until it is true or the timeout passes.
+func waitFor(timeout time.Duration, condition func() bool) bool {
+	end := time.Now().Add(timeout)
+	for time.Now().Before(end) {
+		if condition() {
+			return true
+		}
+		time.Sleep(time.Millisecond * 100)
+	}
+	return condition()
+}
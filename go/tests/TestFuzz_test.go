@@ -0,0 +1,236 @@
+// © 2025 Sharon Aicler (saichler@gmail.com)
+//
+// Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/saichler/l8bus/go/overlay/dissector"
+	"github.com/saichler/l8bus/go/overlay/health"
+	"github.com/saichler/l8bus/go/overlay/protocol"
+	"github.com/saichler/l8bus/go/overlay/tracing"
+	"github.com/saichler/l8bus/go/overlay/vnic"
+	"github.com/saichler/l8srlz/go/serialize/object"
+	infra "github.com/saichler/l8test/go/infra/t_resources"
+	"github.com/saichler/l8types/go/ifs"
+	"github.com/saichler/l8types/go/types/l8health"
+)
+
+var corpus = flag.Bool("corpus", false, "write the seed corpus of the fuzz targets to testdata/fuzz")
+
+// fuzzFrames returns real frames created by a vnic that is never started, with and
+// without a checksum & a trace context.
+func fuzzFrames(t testing.TB, r ifs.IResources) [][]byte {
+	nic := vnic.NewVirtualNetworkInterface(r, nil)
+	p := protocol.New(nic)
+	uuid := r.SysConfig().LocalUuid
+	seeds := []*protocol.MessageOptions{
+		protocol.NewMessage(health.ServiceName, 0, ifs.POST).To(uuid).From(uuid, uuid),
+		protocol.NewMessage(health.ServiceName, 0, ifs.GET).From(uuid, uuid).AsRequest(5).WithSequence(7),
+		protocol.NewMessage(health.ServiceName, 0, ifs.Notify).From(uuid, uuid).WithMode(ifs.M_Leader),
+		protocol.NewMessage(health.ServiceName, 0, ifs.GET).To(uuid).From(uuid, uuid).AsReply(),
+	}
+	frames := make([][]byte, 0)
+	for _, opts := range seeds {
+		data, err := p.Create(opts, object.New(nil, &l8health.L8Health{AUuid: uuid, Alias: "fuzz"}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		frames = append(frames, data, protocol.AppendChecksum(data),
+			tracing.Inject(data, &tracing.TraceContext{TraceId: [16]byte{1}, SpanId: [8]byte{2}, Sampled: true}))
+	}
+	return frames
+}
+
+// fuzzResources returns the resources the fuzz targets parse with, and adds the real
+// frames to the seed corpus of testdata/fuzz.
+func fuzzResources(f *testing.F) ifs.IResources {
+	r, _ := infra.CreateResources(13000, 1, ifs.Error_Level)
+	for _, data := range fuzzFrames(f, r) {
+		f.Add(data)
+	}
+	f.Add([]byte{})
+	return r
+}
+
+// TestFuzzCorpus writes the real frames as the committed seed corpus of the frame fuzz
+// targets when run with -corpus.
+func TestFuzzCorpus(t *testing.T) {
+	if !*corpus {
+		t.Skip("run with -corpus to write the seed corpus")
+	}
+	r, _ := infra.CreateResources(13000, 5, ifs.Error_Level)
+	frames := fuzzFrames(t, r)
+	for _, target := range []string{"FuzzMessageOf", "FuzzHeaderOf", "FuzzExtensions", "FuzzDissect"} {
+		dir := filepath.Join("testdata", "fuzz", target)
+		err := os.MkdirAll(dir, 0755)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, data := range frames {
+			entry := fmt.Sprintf("go test fuzz v1\n[]byte(%q)\n", data)
+			err = os.WriteFile(filepath.Join(dir, fmt.Sprintf("frame-%02d", i)), []byte(entry), 0644)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+}
+
+func FuzzMessageOf(f *testing.F) {
+	r := fuzzResources(f)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		msg, err := protocol.MessageOf(data, r)
+		if err != nil {
+			if msg != nil {
+				t.Fatal("Expected no message with an error")
+			}
+			return
+		}
+		if msg == nil {
+			t.Fatal("Expected a message or an error")
+		}
+		// a frame that holds a message holds its routing header as well
+		header, err := protocol.HeaderOf(data)
+		if err != nil {
+			t.Fatal("Expected the header of a frame with a message: ", err)
+		}
+		if header.Source != msg.Source() || header.ServiceName != msg.ServiceName() {
+			t.Fatal("Expected the header to match the message")
+		}
+		elems, err := protocol.ElementsOf(msg, r)
+		if (err == nil) == (elems == nil) {
+			t.Fatal("Expected either the payload or an error")
+		}
+	})
+}
+
+func FuzzHeaderOf(f *testing.F) {
+	fuzzResources(f)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		header, err := protocol.HeaderOf(data)
+		if (err == nil) == (header == nil) {
+			t.Fatal("Expected either a header or an error")
+		}
+	})
+}
+
+func FuzzExtensions(f *testing.F) {
+	fuzzResources(f)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		msg, ext := protocol.SplitExtensions(data)
+		if len(msg) > len(data) {
+			t.Fatal("Expected the message to be part of the frame")
+		}
+		if ext != nil {
+			_, ext2 := protocol.SplitExtensions(protocol.AppendExtensions(msg, ext))
+			if len(ext2) != len(ext) {
+				t.Fatal("Expected the extensions to survive a round trip")
+			}
+		}
+		protocol.VerifyChecksum(data)
+		tracing.Extract(data)
+	})
+}
+
+func FuzzDissect(f *testing.F) {
+	r := fuzzResources(f)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		d, err := dissector.Dissect(data, r)
+		_, headerErr := protocol.HeaderOf(data)
+		if (err == nil) != (headerErr == nil) {
+			t.Fatal("Expected the dissection to fail only for a frame without a header")
+		}
+		if err != nil {
+			return
+		}
+		if d == nil || d.Size != len(data) {
+			t.Fatal("Expected the dissection of the whole frame")
+		}
+		if _, err = protocol.MessageOf(data, r); (err == nil) != (d.Error == "") {
+			t.Fatal("Expected the dissection error to match the message error")
+		}
+		d.Text()
+	})
+}
+
+func FuzzElementsFor(f *testing.F) {
+	r, _ := infra.CreateResources(13000, 2, ifs.Error_Level)
+	f.Add("select * from L8Health")
+	f.Add("select * from L8Health where alias=fuzz")
+	f.Add("")
+	f.Fuzz(func(t *testing.T, query string) {
+		elems, err := protocol.ElementsFor(query, r)
+		if (err == nil) == (elems == nil) {
+			t.Fatal("Expected either the elements of the query or an error")
+		}
+	})
+}
+
+func TestElementsForUnknownInput(t *testing.T) {
+	r, _ := infra.CreateResources(13000, 3, ifs.Error_Level)
+	_, err := protocol.ElementsFor(42, r)
+	if err == nil {
+		infra.Log.Fail(t, "Expected an error for an unknown payload type")
+		return
+	}
+	_, err = protocol.ElementsFor([]int{1}, r)
+	if err == nil {
+		infra.Log.Fail(t, "Expected an error for a slice of an unknown type")
+		return
+	}
+}
+
+func TestShortFrames(t *testing.T) {
+	r, _ := infra.CreateResources(13000, 4, ifs.Error_Level)
+	nic := vnic.NewVirtualNetworkInterface(r, nil)
+	uuid := r.SysConfig().LocalUuid
+	data, err := protocol.New(nic).Create(protocol.NewMessage(health.ServiceName, 0, ifs.POST).To(uuid).From(uuid, uuid),
+		object.New(nil, &l8health.L8Health{AUuid: uuid}))
+	if err != nil {
+		infra.Log.Fail(t, err)
+		return
+	}
+	header, err := protocol.HeaderOf(data)
+	if err != nil || header.Source != uuid || header.ServiceName != health.ServiceName {
+		infra.Log.Fail(t, "Expected the header of a whole frame")
+		return
+	}
+	if _, err = protocol.HeaderOf(nil); err == nil {
+		infra.Log.Fail(t, "Expected an error for the header of an empty frame")
+		return
+	}
+	// a frame cut at any offset must fail to parse as a message & its payload, or be
+	// dissected with an error, instead of panicking
+	for size := 0; size < len(data); size++ {
+		cut := data[:size]
+		msg, err := protocol.MessageOf(cut, r)
+		if err == nil {
+			_, err = protocol.ElementsOf(msg, r)
+		}
+		if err == nil {
+			infra.Log.Fail(t, "Expected an error for a frame cut at ", size, " of ", len(data), " bytes")
+			return
+		}
+		d, err := dissector.Dissect(cut, r)
+		if err == nil && d.Error == "" && d.PayloadError == "" {
+			infra.Log.Fail(t, "Expected the dissection of a frame cut at ", size, " bytes to fail")
+			return
+		}
+	}
+}
@@ -0,0 +1,139 @@
+// © 2025 Sharon Aicler (saichler@gmail.com)
+//
+// Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/saichler/l8bus/go/overlay/gateway"
+	"github.com/saichler/l8bus/go/overlay/health"
+	"github.com/saichler/l8bus/go/overlay/protocol"
+	"github.com/saichler/l8bus/go/overlay/transport"
+	"github.com/saichler/l8srlz/go/serialize/object"
+	infra "github.com/saichler/l8test/go/infra/t_resources"
+	"github.com/saichler/l8types/go/ifs"
+	"github.com/saichler/l8types/go/types/l8health"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+func TestHttpGateway(t *testing.T) {
+	ct := newChaosTopology(t)
+	defer ct.shutdown()
+	nic1 := ct.nic("nic1_1")
+	uuid2 := ct.uuid("nic2_1")
+	if !waitFor(time.Second*5, func() bool { return health.HealthOf(uuid2, nic1.Resources()) != nil }) {
+		infra.Log.Fail(t, "Expected nic1_1 to see nic2_1")
+		return
+	}
+
+	server := httptest.NewServer(gateway.NewHttpGateway(gateway.NewVnicBus(nic1)))
+	defer server.Close()
+	vnetServer := httptest.NewServer(gateway.NewHttpGateway(ct.vnet1))
+	defer vnetServer.Close()
+
+	body, _ := protojson.Marshal(&l8health.L8Health{AUuid: uuid2})
+	tooLarge := make([]byte, nic1.Resources().SysConfig().MaxDataSize+1)
+	healthPath := "/" + health.ServiceName + "/0"
+	calls := []struct {
+		name   string
+		url    string
+		method string
+		path   string
+		body   []byte
+		status int
+	}{
+		{"a request", server.URL, http.MethodGet, healthPath + "?type=L8Health&timeout=5&destination=" + uuid2, body, http.StatusOK},
+		{"an invalid path", server.URL, http.MethodGet, "/a/b/c", nil, http.StatusNotFound},
+		{"an invalid area", server.URL, http.MethodGet, "/" + health.ServiceName + "/x", nil, http.StatusNotFound},
+		{"an unknown method", server.URL, http.MethodOptions, healthPath, nil, http.StatusMethodNotAllowed},
+		{"a body without a type", server.URL, http.MethodPost, healthPath, body, http.StatusBadRequest},
+		{"a body above the max data size", server.URL, http.MethodPost, healthPath + "?type=L8Health", tooLarge, http.StatusRequestEntityTooLarge},
+		{"a body the action has no endpoint for", server.URL, http.MethodPost, healthPath + "?type=L8Health", body, http.StatusBadRequest},
+		{"a service without a provider", vnetServer.URL, http.MethodGet, "/NoProvider/0?timeout=1", nil, http.StatusServiceUnavailable},
+		{"a failed message", server.URL, http.MethodGet, healthPath + "?type=L8Health&timeout=5&destination=" + ifs.NewUuid(), body, http.StatusBadGateway},
+	}
+	for _, call := range calls {
+		status, reply := httpCall(t, call.method, call.url+call.path, call.body)
+		if status != call.status {
+			infra.Log.Fail(t, "Expected ", call.name, " to return ", call.status, " got ", status, " ", reply.Error)
+			return
+		}
+	}
+
+	// a request without a token is rejected
+	resp, err := http.Get(server.URL + healthPath)
+	if err != nil {
+		infra.Log.Fail(t, err)
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		infra.Log.Fail(t, "Expected a request without a token to return 401, got ", resp.StatusCode)
+		return
+	}
+
+	// the replies of nic2_1 are dropped, so the request times out
+	ct.chaos.SetFaults("nic2_1", "vnet2", &transport.Faults{DropRate: 1})
+	status, _ := httpCall(t, http.MethodGet, server.URL+healthPath+"?type=L8Health&timeout=1&destination="+uuid2, body)
+	if status != http.StatusGatewayTimeout {
+		infra.Log.Fail(t, "Expected a request that timed out to return 504, got ", status)
+		return
+	}
+}
+
+func TestStatusOf(t *testing.T) {
+	statuses := []struct {
+		failure error
+		status  int
+	}{
+		{protocol.Failure(protocol.ErrNoProvider, "Service area 0"), http.StatusServiceUnavailable},
+		{protocol.Failure(protocol.ErrNoDestination, "uuid"), http.StatusBadGateway},
+		{protocol.Failure(protocol.ErrSendFailed, "closed"), http.StatusBadGateway},
+		{protocol.ErrExpired, http.StatusGatewayTimeout},
+		{errors.New("The request timeout is invalid"), http.StatusInternalServerError},
+	}
+	for _, s := range statuses {
+		// failures reach the gateway as the text of the failed message
+		status := gateway.StatusOf(object.NewError(s.failure.Error()))
+		if status != s.status {
+			infra.Log.Fail(t, "Expected ", s.failure.Error(), " to return ", s.status, " got ", status)
+			return
+		}
+	}
+	if gateway.StatusOf(nil) != http.StatusGatewayTimeout {
+		infra.Log.Fail(t, "Expected no response to return 504")
+		return
+	}
+}
+
+// httpCall sends the request with the test token and returns the status and the JSON reply.
+func httpCall(t *testing.T, method, url string, body []byte) (int, *gateway.JsonMessage) {
+	req, _ := http.NewRequest(method, url, bytes.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		infra.Log.Fail(t, err)
+		return 0, &gateway.JsonMessage{}
+	}
+	defer resp.Body.Close()
+	reply := &gateway.JsonMessage{}
+	json.NewDecoder(resp.Body).Decode(reply)
+	return resp.StatusCode, reply
+}
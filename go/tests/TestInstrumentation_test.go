@@ -0,0 +1,158 @@
+// © 2025 Sharon Aicler (saichler@gmail.com)
+//
+// Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/saichler/l8bus/go/overlay/health"
+	"github.com/saichler/l8bus/go/overlay/metrics"
+	"github.com/saichler/l8bus/go/overlay/transport"
+	"github.com/saichler/l8srlz/go/serialize/object"
+	infra "github.com/saichler/l8test/go/infra/t_resources"
+	"github.com/saichler/l8types/go/ifs"
+	"github.com/saichler/l8types/go/types/l8health"
+)
+
+func TestInstrumentation(t *testing.T) {
+	ct := newChaosTopology(t)
+	defer ct.shutdown()
+	nic1 := ct.nic("nic1_1")
+	uuid1 := nic1.Resources().SysConfig().LocalUuid
+	uuid2 := ct.uuid("nic1_2")
+
+	resp := nic1.Request(uuid2, health.ServiceName, 0, ifs.GET, &l8health.L8Health{AUuid: uuid2}, 5)
+	if resp == nil || resp.Error() != nil {
+		infra.Log.Fail(t, "Expected a reply from nic1_2 before the faults")
+		return
+	}
+
+	registry := metrics.GetGlobalRegistry(nic1.Resources().Logger())
+	labels := map[string]string{"vnic_id": uuid1}
+	if registry.Counter("layer8_messages_sent_total", labels).Get() == 0 ||
+		registry.Counter("layer8_messages_received_total", labels).Get() == 0 {
+		infra.Log.Fail(t, "Expected nic1_1 traffic to be counted")
+		return
+	}
+	if registry.Histogram("layer8_message_latency_ms", labels).GetCount() == 0 {
+		infra.Log.Fail(t, "Expected the request latency to be recorded")
+		return
+	}
+
+	// nic1_2 receives the requests, but all its replies are dropped
+	ct.chaos.SetFaults("nic1_2", "vnet1", &transport.Faults{DropRate: 1})
+	timeouts := registry.Counter("layer8_request_timeouts_total", labels).Get()
+	maxFailures := metrics.DefaultCircuitBreakerConfig().MaxFailures
+	for i := 0; i < maxFailures; i++ {
+		nic1.Request(uuid2, health.ServiceName, 0, ifs.GET, &l8health.L8Health{AUuid: uuid2}, 1)
+	}
+	if registry.Counter("layer8_request_timeouts_total", labels).Get()-timeouts != int64(maxFailures) {
+		infra.Log.Fail(t, "Expected ", maxFailures, " timeouts to be counted")
+		return
+	}
+
+	start := time.Now()
+	resp = nic1.Request(uuid2, health.ServiceName, 0, ifs.GET, &l8health.L8Health{AUuid: uuid2}, 1)
+	if resp == nil || resp.Error() == nil || !strings.Contains(resp.Error().Error(), "failed fast") {
+		infra.Log.Fail(t, "Expected the request to fail fast once the circuit breaker is open")
+		return
+	}
+	if time.Since(start) > time.Millisecond*500 {
+		infra.Log.Fail(t, "Expected the request to fail without waiting for the timeout")
+		return
+	}
+}
+
+func TestBreakerOpensOnFailedReplies(t *testing.T) {
+	ct := newChaosTopology(t)
+	defer ct.shutdown()
+	nic1 := ct.nic("nic1_1")
+	// vnet1 answers the requests to an unknown destination with a failed message
+	unknown := ifs.NewUuid()
+
+	maxFailures := metrics.DefaultCircuitBreakerConfig().MaxFailures
+	for i := 0; i < maxFailures; i++ {
+		resp := nic1.Request(unknown, health.ServiceName, 0, ifs.GET, &l8health.L8Health{AUuid: unknown}, 5)
+		if resp == nil || resp.Error() == nil {
+			infra.Log.Fail(t, "Expected the request to an unknown destination to fail")
+			return
+		}
+	}
+
+	resp := nic1.Request(unknown, health.ServiceName, 0, ifs.GET, &l8health.L8Health{AUuid: unknown}, 5)
+	if resp == nil || resp.Error() == nil || !strings.Contains(resp.Error().Error(), "failed fast") {
+		infra.Log.Fail(t, "Expected the circuit breaker to open on failed replies")
+		return
+	}
+}
+
+func TestBreakerIgnoresServiceErrors(t *testing.T) {
+	ct := newChaosTopology(t)
+	defer ct.shutdown()
+	nic1 := ct.nic("nic1_1")
+	nic2 := ct.nic("nic1_2")
+	uuid2 := ct.uuid("nic1_2")
+	sla := ifs.NewServiceLevelAgreement(&errorService{}, "Invalid", 0, false, nil)
+	nic2.Resources().Services().Activate(sla, nic2)
+
+	// the errors of a healthy service do not open its breaker
+	maxFailures := metrics.DefaultCircuitBreakerConfig().MaxFailures
+	for i := 0; i <= maxFailures; i++ {
+		resp := nic1.Request(uuid2, "Invalid", 0, ifs.GET, &l8health.L8Health{}, 5)
+		if resp == nil || resp.Error() == nil || strings.Contains(resp.Error().Error(), "failed fast") {
+			infra.Log.Fail(t, "Expected the error of the service, not of its breaker")
+			return
+		}
+	}
+}
+
+// errorService answers every action with an error, as a service rejecting invalid input
+type errorService struct {
+}
+
+func (this *errorService) Activate(sla *ifs.ServiceLevelAgreement, vnic ifs.IVNic) error {
+	return nil
+}
+func (this *errorService) DeActivate() error {
+	return nil
+}
+func (this *errorService) Post(pb ifs.IElements, vnic ifs.IVNic) ifs.IElements {
+	return object.NewError("invalid post")
+}
+func (this *errorService) Put(pb ifs.IElements, vnic ifs.IVNic) ifs.IElements {
+	return object.NewError("invalid put")
+}
+func (this *errorService) Patch(pb ifs.IElements, vnic ifs.IVNic) ifs.IElements {
+	return object.NewError("invalid patch")
+}
+func (this *errorService) Delete(pb ifs.IElements, vnic ifs.IVNic) ifs.IElements {
+	return object.NewError("invalid delete")
+}
+func (this *errorService) GetCopy(pb ifs.IElements, vnic ifs.IVNic) ifs.IElements {
+	return object.NewError("invalid get copy")
+}
+func (this *errorService) Get(pb ifs.IElements, vnic ifs.IVNic) ifs.IElements {
+	return object.NewError("invalid get")
+}
+func (this *errorService) Failed(pb ifs.IElements, vnic ifs.IVNic, msg *ifs.Message) ifs.IElements {
+	return nil
+}
+func (this *errorService) TransactionConfig() ifs.ITransactionConfig {
+	return nil
+}
+func (this *errorService) WebService() ifs.IWebService {
+	return nil
+}
@@ -0,0 +1,58 @@
+// © 2025 Sharon Aicler (saichler@gmail.com)
+//
+// Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/saichler/l8bus/go/overlay/health"
+	"github.com/saichler/l8bus/go/overlay/transport"
+	infra "github.com/saichler/l8test/go/infra/t_resources"
+)
+
+func TestMemoryTransport(t *testing.T) {
+	mem := transport.NewMemory()
+	port1, port2 := nextPort(), nextPort()
+	vnet1 := memoryVNet(mem, port1)
+	defer vnet1.Shutdown()
+	vnet2 := memoryVNet(mem, port2)
+	defer vnet2.Shutdown()
+
+	err := vnet1.ConnectNetworks("127.0.0.1", uint32(port2))
+	if err != nil {
+		infra.Log.Fail(t, err)
+		return
+	}
+
+	nic1 := memoryVnic(mem, port1, 1)
+	defer nic1.Shutdown()
+	nic2 := memoryVnic(mem, port2, 1)
+	defer nic2.Shutdown()
+
+	uuid2 := nic2.Resources().SysConfig().LocalUuid
+	for i := 0; i < 50 && health.HealthOf(uuid2, nic1.Resources()) == nil; i++ {
+		time.Sleep(time.Millisecond * 100)
+	}
+	if health.HealthOf(uuid2, nic1.Resources()) == nil {
+		infra.Log.Fail(t, "Expected vnic on vnet1 to see the vnic on vnet2")
+		return
+	}
+
+	_, err = mem.Dial("127.0.0.1", uint32(nextPort()), nil)
+	if err == nil {
+		infra.Log.Fail(t, "Expected dial to an unbound memory port to fail")
+		return
+	}
+}
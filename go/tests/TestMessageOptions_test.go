@@ -0,0 +1,72 @@
+// © 2025 Sharon Aicler (saichler@gmail.com)
+//
+// Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"testing"
+
+	"github.com/saichler/l8bus/go/overlay/dissector"
+	"github.com/saichler/l8bus/go/overlay/health"
+	"github.com/saichler/l8bus/go/overlay/protocol"
+	"github.com/saichler/l8srlz/go/serialize/object"
+	infra "github.com/saichler/l8test/go/infra/t_resources"
+	"github.com/saichler/l8types/go/ifs"
+	"github.com/saichler/l8types/go/types/l8health"
+)
+
+func TestMessageOptions(t *testing.T) {
+	ct := newChaosTopology(t)
+	defer ct.shutdown()
+	nic1 := ct.nic("nic1_1")
+	uuid1 := nic1.Resources().SysConfig().LocalUuid
+	uuid2 := ct.uuid("nic2_1")
+
+	opts := protocol.NewMessage(health.ServiceName, 0, ifs.GET).To(uuid2).From(uuid1, uuid1).
+		WithMode(ifs.M_Leader).WithSequence(7).AsRequest(3).WithToken("token").
+		WithExtension(protocol.Ext_Probe, []byte{4})
+	data, err := protocol.New(nic1).Create(opts, object.New(nil, &l8health.L8Health{Alias: "options"}))
+	if err != nil {
+		infra.Log.Fail(t, err.Error())
+		return
+	}
+	d, err := dissector.Dissect(data, nic1.Resources())
+	if err != nil {
+		infra.Log.Fail(t, err.Error())
+		return
+	}
+	if d.Source != uuid1 || d.Destination != uuid2 || d.ServiceName != health.ServiceName || d.Action != "GET" {
+		infra.Log.Fail(t, "Unexpected header ", d.Source, " ", d.Destination, " ", d.ServiceName, " ", d.Action)
+		return
+	}
+	if d.MulticastMode != "leader" || d.Sequence != 7 || !d.Request || d.Transaction != nil {
+		infra.Log.Fail(t, "Unexpected message fields ", d.MulticastMode, " ", d.Sequence)
+		return
+	}
+	if len(d.Extensions) != 1 || d.Extensions[0].Type != protocol.Ext_Probe {
+		infra.Log.Fail(t, "Expected the probe extension")
+		return
+	}
+
+	// the same options reach the public send apis
+	resp := nic1.RequestWith(protocol.NewMessage(health.ServiceName, 0, ifs.GET).To(uuid2), &l8health.L8Health{}, 5)
+	if resp == nil || resp.Error() != nil {
+		infra.Log.Fail(t, "Expected a response to a request with options")
+		return
+	}
+	err = nic1.Send(protocol.NewMessage(health.ServiceName, 0, ifs.GET).WithMode(ifs.M_Leader), &l8health.L8Health{})
+	if err != nil {
+		infra.Log.Fail(t, err.Error())
+		return
+	}
+}
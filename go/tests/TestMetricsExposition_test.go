@@ -0,0 +1,85 @@
+// © 2025 Sharon Aicler (saichler@gmail.com)
+//
+// Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/saichler/l8bus/go/overlay/metrics"
+	infra "github.com/saichler/l8test/go/infra/t_resources"
+	"github.com/saichler/l8types/go/ifs"
+)
+
+func TestMetricsExposition(t *testing.T) {
+	r, _ := infra.CreateResources(nextPort(), 0, ifs.Info_Level)
+	registry := metrics.NewMetricsRegistry(r.Logger())
+	registry.SetHelp("test_requests_total", "Requests with a \\ and\na new line")
+	registry.Counter("test_requests_total", map[string]string{"path": "a\"b\\c\nd", "code": "200"}).Add(3)
+	registry.Gauge("test_queue_size", nil).Set(7)
+	histogram := registry.Histogram("test_latency_ms", map[string]string{"vnic_id": "x"})
+	histogram.Observe(3)
+	histogram.Observe(30)
+	registry.Histogram("test_latency_ms", map[string]string{"vnic_id": "x"}).Observe(20000)
+
+	buff := &bytes.Buffer{}
+	registry.WriteText(buff, false)
+	text := buff.String()
+	expected := []string{
+		"# HELP test_requests_total Requests with a \\\\ and\\na new line\n",
+		"# TYPE test_requests_total counter\n",
+		"test_requests_total{code=\"200\",path=\"a\\\"b\\\\c\\nd\"} 3\n",
+		"# TYPE test_queue_size gauge\n",
+		"test_queue_size 7\n",
+		"# TYPE test_latency_ms histogram\n",
+		"test_latency_ms_bucket{vnic_id=\"x\",le=\"1\"} 0\n",
+		"test_latency_ms_bucket{vnic_id=\"x\",le=\"5\"} 1\n",
+		"test_latency_ms_bucket{vnic_id=\"x\",le=\"50\"} 2\n",
+		"test_latency_ms_bucket{vnic_id=\"x\",le=\"10000\"} 2\n",
+		"test_latency_ms_bucket{vnic_id=\"x\",le=\"+Inf\"} 3\n",
+		"test_latency_ms_sum{vnic_id=\"x\"} 20033\n",
+		"test_latency_ms_count{vnic_id=\"x\"} 3\n",
+	}
+	for _, line := range expected {
+		if !strings.Contains(text, line) {
+			infra.Log.Fail(t, "Expected the exposition to contain ", line, " in:\n", text)
+			return
+		}
+	}
+
+	server := httptest.NewServer(registry.Handler())
+	defer server.Close()
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	req.Header.Set("Accept", "application/openmetrics-text; version=1.0.0")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		infra.Log.Fail(t, err)
+		return
+	}
+	defer resp.Body.Close()
+	buff.Reset()
+	buff.ReadFrom(resp.Body)
+	text = buff.String()
+	if !strings.HasPrefix(resp.Header.Get("Content-Type"), "application/openmetrics-text") {
+		infra.Log.Fail(t, "Expected an openmetrics content type, got ", resp.Header.Get("Content-Type"))
+		return
+	}
+	if !strings.Contains(text, "# TYPE test_requests counter\n") || !strings.HasSuffix(text, "# EOF\n") {
+		infra.Log.Fail(t, "Expected an openmetrics exposition, got:\n", text)
+		return
+	}
+}
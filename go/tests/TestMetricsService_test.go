@@ -0,0 +1,70 @@
+// © 2025 Sharon Aicler (saichler@gmail.com)
+//
+// Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/saichler/l8bus/go/overlay/metrics"
+	infra "github.com/saichler/l8test/go/infra/t_resources"
+	"github.com/saichler/l8types/go/ifs"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestMetricsService(t *testing.T) {
+	ct := newChaosTopology(t)
+	defer ct.shutdown()
+	nic1 := ct.nic("nic1_1")
+	nic2 := ct.nic("nic2_1")
+	uuid2 := nic2.Resources().SysConfig().LocalUuid
+
+	resp := nic1.Request(uuid2, metrics.ServiceName, metrics.ServiceArea, ifs.GET, &wrapperspb.BytesValue{}, 5)
+	snapshot, err := metrics.SnapshotFrom(resp)
+	if err != nil {
+		infra.Log.Fail(t, "Failed to query the metrics of nic2_1: ", err.Error())
+		return
+	}
+	if snapshot.Source != uuid2 {
+		infra.Log.Fail(t, "Expected the snapshot of nic2_1 but got ", snapshot.Source)
+		return
+	}
+	if snapshot.Sum("layer8_messages_sent_total") == 0 {
+		infra.Log.Fail(t, "Expected the snapshot to include the messages sent")
+		return
+	}
+
+	// 3 vnics & 2 vnets run the metrics service
+	var merged *metrics.MetricsSnapshot
+	ok := waitFor(time.Second*10, func() bool {
+		merged = metrics.Collect(nic1, 5)
+		return len(nodesOf(merged)) == 5
+	})
+	if !ok {
+		infra.Log.Fail(t, "Expected metrics from 5 nodes but got ", len(nodesOf(merged)))
+		return
+	}
+	if len(merged.CircuitBreakers) == 0 {
+		infra.Log.Fail(t, "Expected the merged snapshot to include circuit breakers")
+		return
+	}
+}
+
+func nodesOf(snapshot *metrics.MetricsSnapshot) map[string]bool {
+	nodes := make(map[string]bool)
+	for _, m := range snapshot.Metrics {
+		nodes[m.Labels[metrics.NodeLabel]] = true
+	}
+	return nodes
+}
@@ -0,0 +1,201 @@
+// © 2025 Sharon Aicler (saichler@gmail.com)
+//
+// Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/saichler/l8bus/go/overlay/health"
+	"github.com/saichler/l8bus/go/overlay/metrics"
+	"github.com/saichler/l8bus/go/overlay/tracing"
+	infra "github.com/saichler/l8test/go/infra/t_resources"
+	"github.com/saichler/l8types/go/ifs"
+	"github.com/saichler/l8types/go/types/l8health"
+)
+
+func TestPanicBoundary(t *testing.T) {
+	ct := newChaosTopology(t)
+	defer ct.shutdown()
+	nic1 := ct.nic("nic1_1")
+	nic2 := ct.nic("nic2_1")
+	uuid2 := nic2.Resources().SysConfig().LocalUuid
+
+	exporter := tracing.NewMemoryExporter()
+	tracing.SetExporter(exporter)
+	defer tracing.SetExporter(nil)
+
+	sla := ifs.NewServiceLevelAgreement(&panicService{}, "Panic", 0, false, nil)
+	nic2.Resources().Services().Activate(sla, nic2)
+
+	resp := nic1.Request(uuid2, "Panic", 0, ifs.GET, &l8health.L8Health{}, 5)
+	if resp == nil || resp.Error() == nil {
+		infra.Log.Fail(t, "Expected the panic of the handler as the reply")
+		return
+	}
+	if panicsOf(nic2.Resources(), uuid2) != 1 {
+		infra.Log.Fail(t, "Expected the panic of nic2_1 to be counted")
+		return
+	}
+	finished := waitFor(time.Second*5, func() bool {
+		for _, span := range exporter.Spans() {
+			if span.Name == "handle Panic/0" && span.Error != "" {
+				return true
+			}
+		}
+		return false
+	})
+	if !finished {
+		infra.Log.Fail(t, "Expected the span of the panicked handler to be finished with the failure")
+		return
+	}
+
+	// a message that is not a request fails back to the Failed handler of its sender
+	failed := &failedService{}
+	sla = ifs.NewServiceLevelAgreement(failed, "Panic", 0, false, nil)
+	nic1.Resources().Services().Activate(sla, nic1)
+	err := nic1.Unicast(uuid2, "Panic", 0, ifs.POST, &l8health.L8Health{})
+	if err != nil {
+		infra.Log.Fail(t, err)
+		return
+	}
+	if !waitFor(time.Second*5, func() bool { return failed.count.Load() > 0 }) {
+		infra.Log.Fail(t, "Expected nic1_1 to get the failed message of the panic")
+		return
+	}
+	if panicsOf(nic2.Resources(), uuid2) != 2 {
+		infra.Log.Fail(t, "Expected both panics of nic2_1 to be counted")
+		return
+	}
+
+	// nic2_1 keeps handling messages after the panic
+	resp = nic1.Request(uuid2, health.ServiceName, 0, ifs.GET, &l8health.L8Health{AUuid: uuid2}, 5)
+	if resp == nil || resp.Error() != nil {
+		infra.Log.Fail(t, "Expected nic2_1 to answer after its handler panicked")
+		return
+	}
+}
+
+func TestVNetPanicBoundary(t *testing.T) {
+	ct := newChaosTopology(t)
+	defer ct.shutdown()
+	nic1 := ct.nic("nic1_1")
+	uuid := ct.uuid("vnet1")
+
+	// the service runs on vnet1, so its requests are handled by the vnet task queue
+	sla := ifs.NewServiceLevelAgreement(&panicService{}, "Panic", 0, false, nil)
+	ct.vnet1.Resources().Services().Activate(sla, ct.vnet1.VnetVnic())
+
+	resp := nic1.Request(uuid, "Panic", 0, ifs.GET, &l8health.L8Health{}, 5)
+	if resp == nil || resp.Error() == nil {
+		infra.Log.Fail(t, "Expected the panic of the vnet task as the reply")
+		return
+	}
+	if panicsOf(ct.vnet1.Resources(), uuid) != 1 {
+		infra.Log.Fail(t, "Expected the panic of vnet1 to be counted")
+		return
+	}
+
+	// vnet1 keeps handling its tasks after the panic
+	resp = nic1.Request(uuid, health.ServiceName, 0, ifs.GET, &l8health.L8Health{AUuid: uuid}, 5)
+	if resp == nil || resp.Error() != nil {
+		infra.Log.Fail(t, "Expected vnet1 to answer after its task panicked")
+		return
+	}
+}
+
+// panicsOf returns the handler panics of the Panic service counted for the vnic
+func panicsOf(resources ifs.IResources, uuid string) int64 {
+	return metrics.GetGlobalRegistry(resources.Logger()).Counter("layer8_handler_panics_total",
+		map[string]string{"vnic_id": uuid, "service": "Panic"}).Get()
+}
+
+// failedService counts the failed messages it gets
+type failedService struct {
+	count atomic.Int32
+}
+
+func (this *failedService) Activate(sla *ifs.ServiceLevelAgreement, vnic ifs.IVNic) error {
+	return nil
+}
+func (this *failedService) DeActivate() error {
+	return nil
+}
+func (this *failedService) Post(pb ifs.IElements, vnic ifs.IVNic) ifs.IElements {
+	return nil
+}
+func (this *failedService) Put(pb ifs.IElements, vnic ifs.IVNic) ifs.IElements {
+	return nil
+}
+func (this *failedService) Patch(pb ifs.IElements, vnic ifs.IVNic) ifs.IElements {
+	return nil
+}
+func (this *failedService) Delete(pb ifs.IElements, vnic ifs.IVNic) ifs.IElements {
+	return nil
+}
+func (this *failedService) GetCopy(pb ifs.IElements, vnic ifs.IVNic) ifs.IElements {
+	return nil
+}
+func (this *failedService) Get(pb ifs.IElements, vnic ifs.IVNic) ifs.IElements {
+	return nil
+}
+func (this *failedService) Failed(pb ifs.IElements, vnic ifs.IVNic, msg *ifs.Message) ifs.IElements {
+	this.count.Add(1)
+	return nil
+}
+func (this *failedService) TransactionConfig() ifs.ITransactionConfig {
+	return nil
+}
+func (this *failedService) WebService() ifs.IWebService {
+	return nil
+}
+
+// panicService panics on every action
+type panicService struct {
+}
+
+func (this *panicService) Activate(sla *ifs.ServiceLevelAgreement, vnic ifs.IVNic) error {
+	return nil
+}
+func (this *panicService) DeActivate() error {
+	return nil
+}
+func (this *panicService) Post(pb ifs.IElements, vnic ifs.IVNic) ifs.IElements {
+	panic("post")
+}
+func (this *panicService) Put(pb ifs.IElements, vnic ifs.IVNic) ifs.IElements {
+	panic("put")
+}
+func (this *panicService) Patch(pb ifs.IElements, vnic ifs.IVNic) ifs.IElements {
+	panic("patch")
+}
+func (this *panicService) Delete(pb ifs.IElements, vnic ifs.IVNic) ifs.IElements {
+	panic("delete")
+}
+func (this *panicService) GetCopy(pb ifs.IElements, vnic ifs.IVNic) ifs.IElements {
+	panic("get copy")
+}
+func (this *panicService) Get(pb ifs.IElements, vnic ifs.IVNic) ifs.IElements {
+	panic("get")
+}
+func (this *panicService) Failed(pb ifs.IElements, vnic ifs.IVNic, msg *ifs.Message) ifs.IElements {
+	return nil
+}
+func (this *panicService) TransactionConfig() ifs.ITransactionConfig {
+	return nil
+}
+func (this *panicService) WebService() ifs.IWebService {
+	return nil
+}
@@ -0,0 +1,76 @@
+// © 2025 Sharon Aicler (saichler@gmail.com)
+//
+// Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/saichler/l8bus/go/overlay/health"
+	"github.com/saichler/l8bus/go/overlay/metrics"
+	"github.com/saichler/l8bus/go/overlay/transport"
+	infra "github.com/saichler/l8test/go/infra/t_resources"
+	"github.com/saichler/l8types/go/ifs"
+	"github.com/saichler/l8types/go/types/l8health"
+)
+
+func TestServiceBreakers(t *testing.T) {
+	ct := newChaosTopology(t)
+	defer ct.shutdown()
+	nic1 := ct.nic("nic1_1")
+	uuid2 := ct.uuid("nic1_2")
+	filter := &l8health.L8Health{AUuid: nic1.Resources().SysConfig().LocalUuid}
+
+	// nic1_2 receives the requests, but all its replies are dropped
+	ct.chaos.SetFaults("nic1_2", "vnet1", &transport.Faults{DropRate: 1})
+
+	open := waitFor(time.Second*60, func() bool {
+		nic1.RoundRobinRequest(health.ServiceName, 0, ifs.GET, filter, 1)
+		return ct.vnet1.CircuitBreakers().IsOpen(health.ServiceName, 0, uuid2)
+	})
+	if !open {
+		infra.Log.Fail(t, "Expected the circuit breaker of nic1_2 to open")
+		return
+	}
+
+	for i := 0; i < 10; i++ {
+		resp := nic1.RoundRobinRequest(health.ServiceName, 0, ifs.GET, filter, 1)
+		if resp == nil || resp.Error() != nil {
+			infra.Log.Fail(t, "Expected round robin requests to be routed around nic1_2")
+			return
+		}
+	}
+
+	// the circuit breakers of nic1_2, and of its connection, are removed when it disconnects
+	connections := func() int {
+		count := 0
+		for name := range ct.vnet1.CircuitBreakers().GetAll() {
+			if strings.HasPrefix(name, "vnic_") {
+				count++
+			}
+		}
+		return count
+	}
+	before := connections()
+	ct.nic("nic1_2").Shutdown()
+	removed := waitFor(time.Second*10, func() bool {
+		_, ok := ct.vnet1.CircuitBreakers().Get(metrics.ServiceBreakerName(health.ServiceName, 0, uuid2))
+		return !ok && connections() == before-1
+	})
+	if !removed {
+		infra.Log.Fail(t, "Expected the circuit breaker of nic1_2 to be removed after it disconnected")
+		return
+	}
+}
@@ -0,0 +1,123 @@
+// © 2025 Sharon Aicler (saichler@gmail.com)
+//
+// Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	stdstrings "strings"
+	"testing"
+	"time"
+
+	"github.com/saichler/l8bus/go/overlay/health"
+	"github.com/saichler/l8bus/go/overlay/protocol"
+	infra "github.com/saichler/l8test/go/infra/t_resources"
+	"github.com/saichler/l8types/go/ifs"
+	"github.com/saichler/l8types/go/types/l8health"
+)
+
+func TestStatistics(t *testing.T) {
+	// The statistics are recorded once started, without the process wide message log
+	protocol.MessageLog = false
+	defer func() { protocol.MessageLog = true }()
+	ct := newChaosTopology(t)
+	dir := t.TempDir()
+	nic1 := ct.nic("nic1_1")
+	uuid1 := nic1.Resources().SysConfig().LocalUuid
+
+	vnetConfig := &protocol.StatisticsConfig{Dir: dir, Name: "vnet1", Format: protocol.FormatJSONL,
+		Interval: time.Millisecond * 200, MaxFileSize: 2048, MaxFiles: 2}
+	err := ct.vnet1.StartStatistics(vnetConfig)
+	if err != nil {
+		infra.Log.Fail(t, err.Error())
+		return
+	}
+	nicConfig := protocol.DefaultStatisticsConfig("nic1_1")
+	nicConfig.Dir = dir
+	nicConfig.Interval = time.Millisecond * 200
+	err = nic1.StartStatistics(nicConfig)
+	if err != nil {
+		infra.Log.Fail(t, err.Error())
+		return
+	}
+
+	for i := 0; i < 10; i++ {
+		nic1.Request(ct.uuid("nic2_1"), health.ServiceName, 0, ifs.GET, &l8health.L8Health{}, 5)
+	}
+	time.Sleep(time.Second)
+	ct.shutdown()
+
+	snapshot := lastSnapshot(t, filepath.Join(dir, "vnet1.jsonl"))
+	if snapshot == nil {
+		return
+	}
+	found := false
+	for _, flow := range snapshot.Flows {
+		if flow.Source == uuid1 && flow.Count > 0 && flow.Bytes > 0 {
+			found = true
+		}
+	}
+	if !found {
+		infra.Log.Fail(t, "Expected vnet1 to count the messages of nic1_1")
+		return
+	}
+	if _, err = os.Stat(filepath.Join(dir, "vnet1.1.jsonl")); err != nil {
+		infra.Log.Fail(t, "Expected the vnet1 statistics to rotate")
+		return
+	}
+	if _, err = os.Stat(filepath.Join(dir, "vnet1.3.jsonl")); err == nil {
+		infra.Log.Fail(t, "Expected no more than 2 rotated files")
+		return
+	}
+
+	csv, err := os.ReadFile(filepath.Join(dir, "nic1_1.csv"))
+	if err != nil || !stdstrings.Contains(string(csv), "\"flow\"") {
+		infra.Log.Fail(t, "Expected the csv statistics of nic1_1")
+		return
+	}
+
+	// the sinks stop with their vnet & vnic
+	info, _ := os.Stat(filepath.Join(dir, "nic1_1.csv"))
+	time.Sleep(time.Millisecond * 500)
+	after, _ := os.Stat(filepath.Join(dir, "nic1_1.csv"))
+	if after.Size() != info.Size() {
+		infra.Log.Fail(t, "Expected the statistics of nic1_1 to stop on shutdown")
+		return
+	}
+}
+
+// lastSnapshot reads the last json line of a statistics file
+func lastSnapshot(t *testing.T, filename string) *protocol.StatisticsSnapshot {
+	file, err := os.Open(filename)
+	if err != nil {
+		infra.Log.Fail(t, err.Error())
+		return nil
+	}
+	defer file.Close()
+	var line string
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 1024*1024), 1024*1024)
+	for scanner.Scan() {
+		line = scanner.Text()
+	}
+	snapshot := &protocol.StatisticsSnapshot{}
+	err = json.Unmarshal([]byte(line), snapshot)
+	if err != nil {
+		infra.Log.Fail(t, err.Error())
+		return nil
+	}
+	return snapshot
+}
@@ -0,0 +1,67 @@
+// © 2025 Sharon Aicler (saichler@gmail.com)
+//
+// Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	stdstrings "strings"
+	"testing"
+	"time"
+
+	"github.com/saichler/l8bus/go/overlay/health"
+	"github.com/saichler/l8bus/go/overlay/vnet"
+	infra "github.com/saichler/l8test/go/infra/t_resources"
+)
+
+func TestTopology(t *testing.T) {
+	ct := newChaosTopology(t)
+	defer ct.shutdown()
+	nic1 := ct.nic("nic1_1")
+	waitFor(time.Second*10, func() bool {
+		return len(health.All(nic1.Resources())) >= 5
+	})
+
+	topology := vnet.CollectTopology(nic1, 5)
+	vnets := 0
+	for _, node := range topology.Nodes {
+		if node.IsVnet {
+			vnets++
+		}
+	}
+	if vnets != 2 || len(topology.Nodes) != 5 {
+		infra.Log.Fail(t, "Expected 2 vnets & 3 vnics but got ", len(topology.Nodes), " nodes")
+		return
+	}
+	vnet1 := ct.uuid("vnet1")
+	vnet2 := ct.uuid("vnet2")
+	external := 0
+	for _, link := range topology.Links {
+		if link.Kind == vnet.LinkExternalVnet {
+			external++
+			if !(link.From == vnet1 && link.To == vnet2 || link.From == vnet2 && link.To == vnet1) {
+				infra.Log.Fail(t, "Expected the external link to be between the vnets")
+				return
+			}
+		}
+	}
+	if external != 1 || len(topology.Links) != 4 {
+		infra.Log.Fail(t, "Expected 1 vnet link & 3 vnic links but got ", len(topology.Links), " links")
+		return
+	}
+
+	dot := topology.Dot()
+	if !stdstrings.HasPrefix(dot, "graph overlay {") || stdstrings.Count(dot, " -- ") != 4 {
+		infra.Log.Fail(t, "Unexpected DOT output ", dot)
+		return
+	}
+}
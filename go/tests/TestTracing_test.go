@@ -0,0 +1,199 @@
+// © 2025 Sharon Aicler (saichler@gmail.com)
+//
+// Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/saichler/l8bus/go/overlay/health"
+	"github.com/saichler/l8bus/go/overlay/protocol"
+	"github.com/saichler/l8bus/go/overlay/tracing"
+	infra "github.com/saichler/l8test/go/infra/t_resources"
+	"github.com/saichler/l8types/go/ifs"
+	"github.com/saichler/l8types/go/types/l8health"
+)
+
+func TestExtensions(t *testing.T) {
+	msg := []byte("message")
+	data := protocol.WithExtension(msg, protocol.Ext_Trace, []byte("trace"))
+	data = protocol.WithExtension(data, 7, []byte{})
+	body, ext := protocol.SplitExtensions(data)
+	if !bytes.Equal(body, msg) {
+		infra.Log.Fail(t, "Expected the message without the trailer")
+		return
+	}
+	if string(ext[protocol.Ext_Trace]) != "trace" || len(ext) != 2 {
+		infra.Log.Fail(t, "Expected the trace & empty extensions")
+		return
+	}
+	body, ext = protocol.SplitExtensions(msg)
+	if !bytes.Equal(body, msg) || ext != nil {
+		infra.Log.Fail(t, "Expected no extensions for a message without a trailer")
+		return
+	}
+}
+
+func TestTracing(t *testing.T) {
+	exporter := tracing.NewMemoryExporter()
+	tracing.SetExporter(exporter)
+	defer tracing.SetExporter(nil)
+
+	ct := newChaosTopology(t)
+	defer ct.shutdown()
+	nic1 := ct.nic("nic1_1")
+	nic2 := ct.nic("nic2_1")
+	filter := &l8health.L8Health{AUuid: nic2.Resources().SysConfig().LocalUuid}
+	exporter.Reset()
+
+	resp := nic1.Request(nic2.Resources().SysConfig().LocalUuid, health.ServiceName, 0, ifs.GET, filter, 5)
+	if resp == nil || resp.Error() != nil {
+		infra.Log.Fail(t, "Expected a response from nic2_1")
+		return
+	}
+
+	var root *tracing.Span
+	for _, span := range exporter.Spans() {
+		if span.Name == "request Health/0" && span.ParentSpanIdString() == "" {
+			root = span
+		}
+	}
+	if root == nil {
+		infra.Log.Fail(t, "Expected a root span for the request")
+		return
+	}
+
+	// request on nic1_1, routed by vnet1 & vnet2 and handled by nic2_1
+	hops := -1
+	waitFor(time.Second*5, func() bool {
+		hops = hopsOf(exporter.Trace(root.TraceIdString()), root, "handle Health/0")
+		return hops != -1
+	})
+	if hops != 3 {
+		infra.Log.Fail(t, "Expected the request to be routed by 2 vnets but got ", hops, " spans to the handler")
+		return
+	}
+}
+
+func TestTracingFromHandler(t *testing.T) {
+	exporter := tracing.NewMemoryExporter()
+	tracing.SetExporter(exporter)
+	defer tracing.SetExporter(nil)
+
+	ct := newChaosTopology(t)
+	defer ct.shutdown()
+	nic1 := ct.nic("nic1_1")
+	nic2 := ct.nic("nic1_2")
+	uuid2 := nic2.Resources().SysConfig().LocalUuid
+	sla := ifs.NewServiceLevelAgreement(&relayService{destination: ct.uuid("nic2_1")}, "Relay", 0, false, nil)
+	nic2.Resources().Services().Activate(sla, nic2)
+
+	// nic1_2 requests the health of nic2_1 while handling the request of nic1_1, by a
+	// unicast request on a GET and a leader request on a POST
+	for _, action := range []ifs.Action{ifs.GET, ifs.POST} {
+		exporter.Reset()
+		resp := nic1.Request(uuid2, "Relay", 0, action, &l8health.L8Health{}, 5)
+		if resp == nil || resp.Error() != nil {
+			infra.Log.Fail(t, "Expected a response from the relay on nic1_2")
+			return
+		}
+
+		var root *tracing.Span
+		for _, span := range exporter.Spans() {
+			if span.Name == "request Relay/0" && span.ParentSpanIdString() == "" {
+				root = span
+			}
+		}
+		if root == nil {
+			infra.Log.Fail(t, "Expected a root span for the relay request")
+			return
+		}
+		hops := -1
+		waitFor(time.Second*5, func() bool {
+			hops = hopsOf(exporter.Trace(root.TraceIdString()), root, "request Health/0")
+			return hops != -1
+		})
+		if hops == -1 {
+			infra.Log.Fail(t, "Expected the request of the relay handler to continue the trace on ", action)
+			return
+		}
+	}
+}
+
+// relayService requests the health of its destination on a Get, and of the leader of the
+// health service on a Post
+type relayService struct {
+	destination string
+}
+
+func (this *relayService) Activate(sla *ifs.ServiceLevelAgreement, vnic ifs.IVNic) error {
+	return nil
+}
+func (this *relayService) DeActivate() error {
+	return nil
+}
+func (this *relayService) Post(pb ifs.IElements, vnic ifs.IVNic) ifs.IElements {
+	return vnic.LeaderRequest(health.ServiceName, 0, ifs.GET, &l8health.L8Health{AUuid: this.destination}, 5)
+}
+func (this *relayService) Put(pb ifs.IElements, vnic ifs.IVNic) ifs.IElements {
+	return nil
+}
+func (this *relayService) Patch(pb ifs.IElements, vnic ifs.IVNic) ifs.IElements {
+	return nil
+}
+func (this *relayService) Delete(pb ifs.IElements, vnic ifs.IVNic) ifs.IElements {
+	return nil
+}
+func (this *relayService) GetCopy(pb ifs.IElements, vnic ifs.IVNic) ifs.IElements {
+	return nil
+}
+func (this *relayService) Get(pb ifs.IElements, vnic ifs.IVNic) ifs.IElements {
+	return vnic.Request(this.destination, health.ServiceName, 0, ifs.GET, &l8health.L8Health{AUuid: this.destination}, 5)
+}
+func (this *relayService) Failed(pb ifs.IElements, vnic ifs.IVNic, msg *ifs.Message) ifs.IElements {
+	return nil
+}
+func (this *relayService) TransactionConfig() ifs.ITransactionConfig {
+	return nil
+}
+func (this *relayService) WebService() ifs.IWebService {
+	return nil
+}
+
+// hopsOf returns the number of spans from the named span up to the root, or -1 if the
+// named span or one of its ancestors were not exported yet
+func hopsOf(trace []*tracing.Span, root *tracing.Span, name string) int {
+	spans := make(map[string]*tracing.Span)
+	var span *tracing.Span
+	for _, s := range trace {
+		spans[s.SpanIdString()] = s
+		if s.Name == name {
+			span = s
+		}
+	}
+	if span == nil {
+		return -1
+	}
+	hops := 0
+	for span.SpanIdString() != root.SpanIdString() {
+		parent, ok := spans[span.ParentSpanIdString()]
+		if !ok {
+			return -1
+		}
+		span = parent
+		hops++
+	}
+	return hops
+}
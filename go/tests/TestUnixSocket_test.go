@@ -0,0 +1,94 @@
+// © 2025 Sharon Aicler (saichler@gmail.com)
+//
+// Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/saichler/l8bus/go/overlay/health"
+	"github.com/saichler/l8bus/go/overlay/transport"
+	vnet2 "github.com/saichler/l8bus/go/overlay/vnet"
+	"github.com/saichler/l8bus/go/overlay/vnic"
+	infra "github.com/saichler/l8test/go/infra/t_resources"
+	"github.com/saichler/l8types/go/ifs"
+)
+
+// tcpVnic creates a VNic on the default transport and waits for it to connect.
+func tcpVnic(port, num int) *vnic.VirtualNetworkInterface {
+	r, _ := infra.CreateResources(port, num, ifs.Info_Level)
+	nic := vnic.NewVirtualNetworkInterface(r, nil)
+	nic.Start()
+	nic.WaitForConnection()
+	return nic
+}
+
+func TestUnixSocket(t *testing.T) {
+	port := nextPort()
+	r, _ := infra.CreateResources(port, 0, ifs.Info_Level)
+	vnet := vnet2.NewVNet(r)
+	err := vnet.Start()
+	if err != nil {
+		infra.Log.Fail(t, err)
+		return
+	}
+	defer vnet.Shutdown()
+
+	info, err := os.Lstat(transport.UnixSocketDir)
+	if err != nil || info.Mode().Perm()&0022 != 0 {
+		infra.Log.Fail(t, "Expected the unix socket directory to be private to the vnet")
+		return
+	}
+
+	// a vnic on the same host dials the unix socket and is internal to the vnet
+	nic1 := tcpVnic(port, 1)
+	defer nic1.Shutdown()
+	if !nic1.LocalLink() {
+		infra.Log.Fail(t, "Expected nic1 to connect over the unix socket")
+		return
+	}
+	uuid1 := nic1.Resources().SysConfig().LocalUuid
+	ok := waitFor(time.Second*5, func() bool {
+		hp := health.HealthOf(uuid1, vnet.Resources())
+		return vnet.LocalCount() == 1 && hp != nil && !hp.IsVnet
+	})
+	if !ok {
+		infra.Log.Fail(t, "Expected nic1 to be an internal connection of the vnet")
+		return
+	}
+
+	// without the socket, the vnic falls back to tcp
+	os.Remove(transport.UnixSocketPath(uint32(port)))
+	nic2 := tcpVnic(port, 2)
+	defer nic2.Shutdown()
+	if nic2.LocalLink() {
+		infra.Log.Fail(t, "Expected nic2 to fall back to tcp")
+		return
+	}
+	if !waitFor(time.Second*5, func() bool { return vnet.LocalCount() == 2 }) {
+		infra.Log.Fail(t, "Expected nic2 to be an internal connection of the vnet")
+		return
+	}
+
+	// the socket is only preferred for a vnet on this host
+	if !transport.IsLocalHost("127.0.0.1") || transport.IsLocalHost("192.0.2.1") {
+		infra.Log.Fail(t, "Expected only the loopback address to be this host")
+		return
+	}
+	if transport.PreferLocal(transport.NewTCP(), "192.0.2.1", uint32(port)) {
+		infra.Log.Fail(t, "Expected no unix socket for a remote vnet")
+		return
+	}
+}
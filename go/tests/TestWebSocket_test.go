@@ -0,0 +1,113 @@
+// © 2025 Sharon Aicler (saichler@gmail.com)
+//
+// Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/saichler/l8bus/go/overlay/gateway"
+	"github.com/saichler/l8bus/go/overlay/health"
+	"github.com/saichler/l8bus/go/overlay/transport"
+	vnet2 "github.com/saichler/l8bus/go/overlay/vnet"
+	"github.com/saichler/l8bus/go/overlay/vnic"
+	infra "github.com/saichler/l8test/go/infra/t_resources"
+	"github.com/saichler/l8types/go/ifs"
+	"github.com/saichler/l8types/go/types/l8health"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+func TestWebSocket(t *testing.T) {
+	mem := transport.NewMemory()
+	port := nextPort()
+	vnet := memoryVNet(mem, port)
+	defer vnet.Shutdown()
+	// the websocket endpoint is a real tcp listener, next to the port of the vnet
+	wsPort := port + 1
+	address := "ws://127.0.0.1:" + strconv.Itoa(wsPort)
+	err := vnet.StartWebSocket(uint32(wsPort))
+	if err != nil {
+		infra.Log.Fail(t, err)
+		return
+	}
+
+	// A vnic joining the overlay over the binary websocket endpoint
+	r, _ := infra.CreateResources(wsPort, 1, ifs.Info_Level)
+	nic := vnic.NewVirtualNetworkInterface(r, nil)
+	nic.SetTransport(transport.NewWebSocket(vnet2.WebSocketPath))
+	nic.Start()
+	nic.WaitForConnection()
+	defer nic.Shutdown()
+
+	uuid := nic.Resources().SysConfig().LocalUuid
+	if !waitFor(time.Second*5, func() bool { return health.HealthOf(uuid, vnet.Resources()) != nil }) {
+		infra.Log.Fail(t, "Expected the websocket vnic to be known by the vnet")
+		return
+	}
+
+	// JSON clients without a token, or from another browser origin, are rejected
+	_, resp, err := websocket.DefaultDialer.Dial(address+vnet2.WebSocketJsonPath, nil)
+	if err == nil || resp == nil || resp.StatusCode != http.StatusUnauthorized {
+		infra.Log.Fail(t, "Expected a JSON client without a token to be rejected")
+		return
+	}
+	origin := http.Header{"Origin": []string{"http://example.com"}}
+	_, resp, err = websocket.DefaultDialer.Dial(address+vnet2.WebSocketJsonPath+"?token="+testToken, origin)
+	if err == nil || resp == nil || resp.StatusCode != http.StatusForbidden {
+		infra.Log.Fail(t, "Expected a JSON client from another origin to be rejected")
+		return
+	}
+
+	// A JSON client requesting the health of the websocket vnic
+	ws, _, err := websocket.DefaultDialer.Dial(address+vnet2.WebSocketJsonPath+"?token="+testToken, nil)
+	if err != nil {
+		infra.Log.Fail(t, err)
+		return
+	}
+	defer ws.Close()
+
+	body, _ := protojson.Marshal(&l8health.L8Health{AUuid: uuid})
+	err = ws.WriteJSON(&gateway.JsonMessage{Id: 1, Op: gateway.OpRequest, Destination: uuid,
+		ServiceName: health.ServiceName, Action: "GET", Type: "L8Health", Body: body, Timeout: 5})
+	if err != nil {
+		infra.Log.Fail(t, err)
+		return
+	}
+	reply := &gateway.JsonMessage{}
+	ws.SetReadDeadline(time.Now().Add(time.Second * 10))
+	err = ws.ReadJSON(reply)
+	if err != nil {
+		infra.Log.Fail(t, err)
+		return
+	}
+	if reply.Id != 1 || reply.Error != "" {
+		infra.Log.Fail(t, "Expected a reply to request 1, got ", reply.Id, " ", reply.Error)
+		return
+	}
+
+	err = ws.WriteJSON(&gateway.JsonMessage{Id: 2, Op: gateway.OpRequest, ServiceName: health.ServiceName, Action: "FETCH"})
+	if err != nil {
+		infra.Log.Fail(t, err)
+		return
+	}
+	reply = &gateway.JsonMessage{}
+	err = ws.ReadJSON(reply)
+	if err != nil || reply.Id != 2 || reply.Error == "" {
+		infra.Log.Fail(t, "Expected an error reply for an unknown action")
+		return
+	}
+}
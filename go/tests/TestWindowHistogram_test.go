@@ -0,0 +1,72 @@
+// © 2025 Sharon Aicler (saichler@gmail.com)
+//
+// Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/saichler/l8bus/go/overlay/metrics"
+	infra "github.com/saichler/l8test/go/infra/t_resources"
+)
+
+// within returns true if the value is within 5% of the expected value
+func within(value, expected int64) bool {
+	diff := value - expected
+	if diff < 0 {
+		diff = -diff
+	}
+	return diff*100 <= expected*5
+}
+
+func TestWindowHistogram(t *testing.T) {
+	window := metrics.NewWindowHistogram(time.Second, 4)
+	for i := int64(1); i <= 1000; i++ {
+		window.Observe(i)
+	}
+	p := window.Percentiles()
+	if p.Count != 1000 || !within(p.P50, 500) || !within(p.P90, 900) || !within(p.P99, 990) || !within(p.P999, 999) {
+		infra.Log.Fail(t, "Unexpected percentiles ", p.Count, " ", p.P50, " ", p.P90, " ", p.P99, " ", p.P999)
+		return
+	}
+
+	time.Sleep(time.Millisecond * 1300)
+	if window.Count() != 0 || window.Percentile(99) != 0 {
+		infra.Log.Fail(t, "Expected the observations to slide out of the window")
+		return
+	}
+}
+
+func TestTailLatencyHealth(t *testing.T) {
+	conn := metrics.NewConnectionMetrics("conn", "127.0.0.1")
+	for i := 0; i < 1000; i++ {
+		conn.RecordLatency(10)
+	}
+	if conn.GetState() != metrics.HealthyState {
+		infra.Log.Fail(t, "Expected a low latency connection to be healthy")
+		return
+	}
+	// 2% of slow requests leave the average low, but degrade the p99
+	for i := 0; i < 20; i++ {
+		conn.RecordLatency(2000)
+	}
+	if conn.GetAverageLatency() > 100 {
+		infra.Log.Fail(t, "Expected the average latency to stay low")
+		return
+	}
+	if conn.GetState() == metrics.HealthyState || conn.GetState() == metrics.DegradedState {
+		infra.Log.Fail(t, "Expected the tail latency to score the connection unhealthy, got ", conn.GetState().String())
+		return
+	}
+}